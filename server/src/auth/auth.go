@@ -2,9 +2,13 @@ package auth
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
-	"time"
+	"slices"
 	"strconv"
+	"sync"
+	"time"
+	"users-service/src/constants"
 
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -12,8 +16,19 @@ import (
 var (
 	jwtSecret          string
 	jwtExpirationHours int
+	// jwtSecretCutoff is when the tokens signed with jwtSecret stop being accepted, they aren't when it is not set
+	jwtSecretCutoff time.Time
+
+	keyManager     *KeyManager
+	keyManagerErr  error
+	keyManagerOnce sync.Once
 )
 
+var validMethods = []string{AlgorithmRS256, AlgorithmEdDSA}
+
+// the user tokens may also be the ones signed with JWT_SECRET
+var validUserTokenMethods = []string{AlgorithmRS256, AlgorithmEdDSA, jwt.SigningMethodHS256.Alg()}
+
 func init() {
 	jwtSecret = os.Getenv("JWT_SECRET")
 	jwtExpirationHoursStr := os.Getenv("JWT_DURATION_HOURS")
@@ -21,6 +36,79 @@ func init() {
 	if jwtExpirationHoursStr != "" {
 		jwtExpirationHours, _ = strconv.Atoi(jwtExpirationHoursStr)
 	}
+
+	if cutoff := os.Getenv("JWT_SECRET_CUTOFF"); cutoff != "" {
+		var err error
+		if jwtSecretCutoff, err = time.Parse(time.RFC3339, cutoff); err != nil {
+			slog.Warn("invalid JWT_SECRET_CUTOFF, the tokens signed with JWT_SECRET are rejected", slog.String("error", err.Error()))
+		}
+	}
+}
+
+// signingKeys returns the keys the tokens are signed with, they are loaded the first time they are needed from
+// JWT_KEYS_DIR with the algorithm of JWT_SIGNING_ALGORITHM and rotated every JWT_KEY_ROTATION_INTERVAL
+func signingKeys() (*KeyManager, error) {
+	keyManagerOnce.Do(func() {
+		algorithm := os.Getenv("JWT_SIGNING_ALGORITHM")
+		if algorithm == "" {
+			algorithm = AlgorithmRS256
+		}
+
+		rotationInterval := 30 * 24 * time.Hour
+		if value := os.Getenv("JWT_KEY_ROTATION_INTERVAL"); value != "" {
+			var err error
+			if rotationInterval, err = time.ParseDuration(value); err != nil {
+				keyManagerErr = fmt.Errorf("invalid duration for JWT_KEY_ROTATION_INTERVAL: %w", err)
+				return
+			}
+		}
+
+		// a replaced key has to outlive every token it signed
+		retention := max(time.Duration(jwtExpirationHours)*time.Hour, constants.ServiceTokenDuration)
+
+		keyManager, keyManagerErr = NewKeyManager(os.Getenv("JWT_KEYS_DIR"), algorithm, rotationInterval, retention)
+	})
+	return keyManager, keyManagerErr
+}
+
+// RotateSigningKeys reloads the signing keys, generating a new one if the newest is due for rotation
+func RotateSigningKeys() error {
+	keys, err := signingKeys()
+	if err != nil {
+		return err
+	}
+	return keys.Rotate()
+}
+
+// GetJWKS returns the public keys the tokens can be verified with
+func GetJWKS() (JWKS, error) {
+	keys, err := signingKeys()
+	if err != nil {
+		return JWKS{}, err
+	}
+	return keys.JWKS(), nil
+}
+
+// keyfunc returns the signing key a token was signed with
+func keyfunc(token *jwt.Token) (interface{}, error) {
+	keys, err := signingKeys()
+	if err != nil {
+		return nil, err
+	}
+	return keys.Keyfunc(token)
+}
+
+// userKeyfunc is keyfunc for the user tokens, which also accepts the ones signed with JWT_SECRET before there were
+// signing keys. Those have no kid, and are only accepted until JWT_SECRET_CUTOFF so the switch doesn't log everyone out
+func userKeyfunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return keyfunc(token)
+	}
+
+	if _, hasKid := token.Header["kid"]; hasKid || jwtSecret == "" || !time.Now().Before(jwtSecretCutoff) {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return []byte(jwtSecret), nil
 }
 
 type Claims struct {
@@ -30,12 +118,13 @@ type Claims struct {
 }
 
 func GenerateToken(userId string, userAdmin bool) (string, error) {
-	if jwtSecret == "" {
-		return "", fmt.Errorf("JWT_SECRET environment variable is not set")
-	}
 	if jwtExpirationHours == 0 {
 		return "", fmt.Errorf("JWT_DURATION_HOURS environment variable is not set or invalid")
 	}
+	keys, err := signingKeys()
+	if err != nil {
+		return "", fmt.Errorf("error loading signing keys: %w", err)
+	}
 
 	claims := Claims{
 		UserId:    userId,
@@ -47,9 +136,7 @@ func GenerateToken(userId string, userAdmin bool) (string, error) {
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	sign, err := token.SignedString([]byte(jwtSecret))
+	sign, err := keys.Sign(claims)
 	if err != nil {
 		return "", fmt.Errorf("error signing JWT token: %w", err)
 	}
@@ -58,16 +145,7 @@ func GenerateToken(userId string, userAdmin bool) (string, error) {
 }
 
 func ValidateToken(tokenString string) (*Claims, error) {
-	if jwtSecret == "" {
-		return nil, fmt.Errorf("JWT_SECRET environment variable is not set")
-	}
-
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(jwtSecret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, userKeyfunc, jwt.WithValidMethods(validUserTokenMethods))
 
 	if err != nil {
 		return nil, fmt.Errorf("error parsing JWT token: %w", err)
@@ -78,6 +156,11 @@ func ValidateToken(tokenString string) (*Claims, error) {
 		return nil, fmt.Errorf("invalid token")
 	}
 
+	// the service tokens are signed with the same keys but have no user
+	if claims.UserId == "" || slices.Contains(claims.Audience, ServiceTokenAudience) {
+		return nil, fmt.Errorf("the token is not a user token")
+	}
+
 	if time.Now().After(claims.ExpiresAt.Time) {
 		return nil, fmt.Errorf("token has expired")
 	}
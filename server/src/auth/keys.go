@@ -0,0 +1,386 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Algorithms the signing keys can be generated for
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+const (
+	keyFileExtension = ".pem"
+	rsaKeyBits       = 2048
+	// a token with an unknown kid makes the keys be reloaded at most once every unknownKidReloadInterval,
+	// since another instance may have just rotated them
+	unknownKidReloadInterval = 30 * time.Second
+)
+
+var errUnknownKid = errors.New("unknown signing key")
+
+type signingKey struct {
+	kid       string
+	method    jwt.SigningMethod
+	private   crypto.Signer
+	createdAt time.Time
+	// file is the name of the file the key is stored in, empty for the keys only kept in memory
+	file string
+}
+
+// KeyManager holds the keys the tokens are signed with. Every key it holds is valid to verify tokens,
+// the newest one signs the new ones. The keys are PEM files of a directory shared by every instance of the service,
+// named after when they were created and their kid. When there is none they are only kept in memory
+// and the tokens don't survive a restart
+type KeyManager struct {
+	mu               sync.RWMutex
+	dir              string
+	algorithm        string
+	rotationInterval time.Duration
+	retention        time.Duration
+	keys             []*signingKey
+	lastReload       time.Time
+}
+
+// JWK is the public part of a signing key as published in the JWKS
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS is the set of keys the tokens can be verified with
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewKeyManager loads the keys of dir, generating one with the algorithm if there are none.
+// A new key is generated once the newest one is older than rotationInterval, zero disables the rotation,
+// and the replaced keys are kept for retention so the tokens they signed can still be verified
+func NewKeyManager(dir string, algorithm string, rotationInterval time.Duration, retention time.Duration) (*KeyManager, error) {
+	if algorithm != AlgorithmRS256 && algorithm != AlgorithmEdDSA {
+		return nil, fmt.Errorf("unsupported signing algorithm: '%s'", algorithm)
+	}
+
+	manager := &KeyManager{
+		dir:              dir,
+		algorithm:        algorithm,
+		rotationInterval: rotationInterval,
+		retention:        retention,
+	}
+	if dir == "" {
+		slog.Warn("no signing keys directory, the keys are only kept in memory")
+	} else if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("error creating signing keys directory: %w", err)
+	}
+	if err := manager.Rotate(); err != nil {
+		return nil, err
+	}
+	return manager, nil
+}
+
+// Rotate reloads the keys, generates a new signing key if the newest one is due for rotation
+// and retires the keys that were replaced more than the retention ago
+func (m *KeyManager) Rotate() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.load(); err != nil {
+		return err
+	}
+
+	if len(m.keys) == 0 || (m.rotationInterval > 0 && time.Since(m.keys[len(m.keys)-1].createdAt) >= m.rotationInterval) {
+		key, err := m.generate()
+		if err != nil {
+			return err
+		}
+		m.keys = append(m.keys, key)
+		slog.Info("signing key generated", slog.String("kid", key.kid), slog.String("algorithm", key.method.Alg()))
+	}
+
+	active := []*signingKey{}
+	for i, key := range m.keys {
+		if i < len(m.keys)-1 && time.Since(m.keys[i+1].createdAt) > m.retention {
+			if err := m.remove(key); err != nil {
+				return err
+			}
+			slog.Info("signing key retired", slog.String("kid", key.kid))
+			continue
+		}
+		active = append(active, key)
+	}
+	m.keys = active
+	return nil
+}
+
+// Sign signs the claims with the newest key, its id goes in the kid header
+func (m *KeyManager) Sign(claims jwt.Claims) (string, error) {
+	m.mu.RLock()
+	key := m.keys[len(m.keys)-1]
+	m.mu.RUnlock()
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+
+	return token.SignedString(key.private)
+}
+
+// Keyfunc returns the public key a token was signed with, by its kid
+func (m *KeyManager) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, fmt.Errorf("the token has no kid")
+	}
+
+	key, err := m.find(kid)
+	if errors.Is(err, errUnknownKid) && m.reloadForUnknownKid() {
+		key, err = m.find(kid)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.private.Public(), nil
+}
+
+// JWKS returns the public part of every key
+func (m *KeyManager) JWKS() JWKS {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	jwks := JWKS{Keys: make([]JWK, 0, len(m.keys))}
+	for _, key := range m.keys {
+		jwks.Keys = append(jwks.Keys, key.jwk())
+	}
+	return jwks
+}
+
+func (m *KeyManager) find(kid string) (*signingKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, key := range m.keys {
+		if key.kid == kid {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: '%s'", errUnknownKid, kid)
+}
+
+// reloadForUnknownKid reloads the keys from the directory, unless they were reloaded recently
+func (m *KeyManager) reloadForUnknownKid() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.dir == "" || time.Since(m.lastReload) < unknownKidReloadInterval {
+		return false
+	}
+	if err := m.load(); err != nil {
+		slog.Error("error reloading signing keys", slog.String("error", err.Error()))
+		return false
+	}
+	return true
+}
+
+// load reads the keys of the directory, the oldest first. Without a directory the keys in memory are kept
+func (m *KeyManager) load() error {
+	if m.dir == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return fmt.Errorf("error reading signing keys directory: %w", err)
+	}
+
+	keys := []*signingKey{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), keyFileExtension) {
+			continue
+		}
+		encoded, err := os.ReadFile(filepath.Join(m.dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("error reading signing key %s: %w", entry.Name(), err)
+		}
+		key, err := parseSigningKey(encoded, keyFileCreatedAt(entry.Name()))
+		if err != nil {
+			return fmt.Errorf("error parsing signing key %s: %w", entry.Name(), err)
+		}
+		key.file = entry.Name()
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].createdAt.Equal(keys[j].createdAt) {
+			return keys[i].kid < keys[j].kid
+		}
+		return keys[i].createdAt.Before(keys[j].createdAt)
+	})
+	m.keys = keys
+	m.lastReload = time.Now()
+	return nil
+}
+
+// generate creates a new key and, if there is a directory, stores it there readable only by the service
+func (m *KeyManager) generate() (*signingKey, error) {
+	var private crypto.Signer
+	var err error
+	switch m.algorithm {
+	case AlgorithmEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error generating signing key: %w", err)
+	}
+
+	key, err := newSigningKey(private, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if m.dir == "" {
+		return key, nil
+	}
+
+	encoded, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding signing key: %w", err)
+	}
+	key.file = keyFileName(key)
+	if err := os.WriteFile(filepath.Join(m.dir, key.file), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: encoded}), 0600); err != nil {
+		return nil, fmt.Errorf("error storing signing key: %w", err)
+	}
+	return key, nil
+}
+
+// remove deletes the file of a retired key, so the other instances stop accepting it too
+func (m *KeyManager) remove(key *signingKey) error {
+	if m.dir == "" || key.file == "" {
+		return nil
+	}
+	err := os.Remove(filepath.Join(m.dir, key.file))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error removing signing key %s: %w", key.kid, err)
+	}
+	return nil
+}
+
+// keyFileName names the file of a key after its creation time and its kid, the time is kept in the name
+// since the modification time of the file changes when it is copied or restored
+func keyFileName(key *signingKey) string {
+	return fmt.Sprintf("%d-%s%s", key.createdAt.Unix(), key.kid, keyFileExtension)
+}
+
+// keyFileCreatedAt returns the creation time in the name of a key file. The files named otherwise, like the ones
+// added by hand, are taken as the oldest keys so they are the first to be replaced
+func keyFileCreatedAt(name string) time.Time {
+	prefix, _, found := strings.Cut(strings.TrimSuffix(name, keyFileExtension), "-")
+	if !found {
+		return time.Time{}
+	}
+	seconds, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
+
+// parseSigningKey parses a PKCS#1 RSA or a PKCS#8 RSA or Ed25519 private key
+func parseSigningKey(encoded []byte, createdAt time.Time) (*signingKey, error) {
+	block, _ := pem.Decode(encoded)
+	if block == nil {
+		return nil, fmt.Errorf("the key is not PEM encoded")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block: %s", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	private, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported key type: %T", parsed)
+	}
+	return newSigningKey(private, createdAt)
+}
+
+// newSigningKey identifies the key by the thumbprint of its public key, so every instance gives it the same kid
+func newSigningKey(private crypto.Signer, createdAt time.Time) (*signingKey, error) {
+	var method jwt.SigningMethod
+	switch private.(type) {
+	case *rsa.PrivateKey:
+		method = jwt.SigningMethodRS256
+	case ed25519.PrivateKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type: %T", private)
+	}
+
+	public, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return nil, fmt.Errorf("error encoding public key: %w", err)
+	}
+	thumbprint := sha256.Sum256(public)
+
+	return &signingKey{
+		kid:       base64.RawURLEncoding.EncodeToString(thumbprint[:16]),
+		method:    method,
+		private:   private,
+		createdAt: createdAt,
+	}, nil
+}
+
+func (k *signingKey) jwk() JWK {
+	jwk := JWK{Kid: k.kid, Use: "sig", Alg: k.method.Alg()}
+	switch public := k.private.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+	return jwk
+}
@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+	"users-service/src/constants"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrInvalidIdentityToken is returned when the token of an identity provider is malformed, expired,
+	// not signed by it or not issued for this service
+	ErrInvalidIdentityToken = errors.New("invalid identity token")
+	// ErrUnverifiedEmail is returned when the token is valid but the provider did not verify its email
+	ErrUnverifiedEmail = errors.New("the identity provider did not verify the email")
+)
+
+// defaultJWKSCacheTTL is how long the keys of a provider are kept when its configuration doesn't say
+const defaultJWKSCacheTTL = time.Hour
+
+// oidcSigningMethods are the algorithms the providers can sign their tokens with, never the symmetric ones
+var oidcSigningMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// VerifiedIdentity is the user an identity provider vouches for, identified by its subject
+type VerifiedIdentity struct {
+	Provider string
+	Subject  string
+	Email    string
+}
+
+// IdentityVerifier verifies the tokens an identity provider issued to its users
+type IdentityVerifier interface {
+	// Verify returns the identity of a token, ErrInvalidIdentityToken if it is not valid
+	// and ErrUnverifiedEmail if it has no verified email
+	Verify(ctx context.Context, token string) (VerifiedIdentity, error)
+}
+
+// OIDCProviderConfig is an OpenID Connect provider as configured in the providers file. The tokens must be issued
+// by Issuer for one of the ClientIds and signed with a key of JWKSURL
+type OIDCProviderConfig struct {
+	Name         string   `json:"name"`
+	Issuer       string   `json:"issuer"`
+	ClientIds    []string `json:"client_ids"`
+	JWKSURL      string   `json:"jwks_url"`
+	JWKSCacheTTL string   `json:"jwks_cache_ttl"`
+}
+
+// OIDCVerifier verifies the ID tokens of an OpenID Connect provider
+type OIDCVerifier struct {
+	config OIDCProviderConfig
+	keys   *jwksCache
+}
+
+type oidcClaims struct {
+	Email         string          `json:"email"`
+	EmailVerified json.RawMessage `json:"email_verified"`
+	jwt.RegisteredClaims
+}
+
+// isEmailVerified reads email_verified, some providers send it as a string
+func (c *oidcClaims) isEmailVerified() bool {
+	var verified bool
+	if err := json.Unmarshal(c.EmailVerified, &verified); err == nil {
+		return verified
+	}
+	var verifiedString string
+	if err := json.Unmarshal(c.EmailVerified, &verifiedString); err == nil {
+		return verifiedString == "true"
+	}
+	return false
+}
+
+// NewOIDCVerifier creates the verifier of a provider, its keys are fetched with client the first time a token is verified
+func NewOIDCVerifier(config OIDCProviderConfig, client *http.Client) (*OIDCVerifier, error) {
+	if config.Name == "" || config.Issuer == "" || config.JWKSURL == "" || len(config.ClientIds) == 0 {
+		return nil, fmt.Errorf("the provider '%s' needs a name, an issuer, a JWKS URL and at least one client id", config.Name)
+	}
+
+	ttl := defaultJWKSCacheTTL
+	if config.JWKSCacheTTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(config.JWKSCacheTTL); err != nil {
+			return nil, fmt.Errorf("invalid JWKS cache TTL of the provider '%s': %w", config.Name, err)
+		}
+	}
+
+	return &OIDCVerifier{config: config, keys: newJWKSCache(config.JWKSURL, ttl, client)}, nil
+}
+
+func (v *OIDCVerifier) Verify(ctx context.Context, token string) (VerifiedIdentity, error) {
+	keyfunc := func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.keys.key(ctx, kid)
+	}
+
+	parsed, err := jwt.ParseWithClaims(token, &oidcClaims{}, keyfunc,
+		jwt.WithValidMethods(oidcSigningMethods), jwt.WithIssuer(v.config.Issuer), jwt.WithExpirationRequired())
+	if err != nil {
+		if errors.Is(err, ErrJWKSUnavailable) {
+			return VerifiedIdentity{}, err
+		}
+		return VerifiedIdentity{}, fmt.Errorf("%w: %w", ErrInvalidIdentityToken, err)
+	}
+
+	claims, ok := parsed.Claims.(*oidcClaims)
+	if !ok || !parsed.Valid || claims.Subject == "" {
+		return VerifiedIdentity{}, fmt.Errorf("%w: the token has no subject", ErrInvalidIdentityToken)
+	}
+	if !slices.ContainsFunc(claims.Audience, func(audience string) bool { return slices.Contains(v.config.ClientIds, audience) }) {
+		return VerifiedIdentity{}, fmt.Errorf("%w: the token was issued for %v", ErrInvalidIdentityToken, claims.Audience)
+	}
+	if claims.Email == "" || !claims.isEmailVerified() {
+		return VerifiedIdentity{}, ErrUnverifiedEmail
+	}
+
+	return VerifiedIdentity{Provider: v.config.Name, Subject: claims.Subject, Email: claims.Email}, nil
+}
+
+// ProviderRegistry has the verifiers of the identity providers users can sign in with, by name
+type ProviderRegistry struct {
+	verifiers map[string]IdentityVerifier
+}
+
+func NewProviderRegistry() *ProviderRegistry {
+	return &ProviderRegistry{verifiers: make(map[string]IdentityVerifier)}
+}
+
+// Register adds the verifier of a provider, replacing the one it had
+func (r *ProviderRegistry) Register(name string, verifier IdentityVerifier) {
+	r.verifiers[name] = verifier
+}
+
+// Get returns the verifier of a provider
+func (r *ProviderRegistry) Get(name string) (IdentityVerifier, bool) {
+	verifier, ok := r.verifiers[name]
+	return verifier, ok
+}
+
+// LoadOIDCProviders registers the OpenID Connect providers of a JSON file with a list of OIDCProviderConfig,
+// their keys are fetched with the timeout. No file means there are no OIDC providers
+func (r *ProviderRegistry) LoadOIDCProviders(file string, timeout time.Duration) error {
+	if file == "" {
+		return nil
+	}
+
+	encoded, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("error reading OIDC providers file: %w", err)
+	}
+	var configs []OIDCProviderConfig
+	if err := json.Unmarshal(encoded, &configs); err != nil {
+		return fmt.Errorf("error parsing OIDC providers file: %w", err)
+	}
+
+	client := &http.Client{Timeout: timeout}
+	for _, config := range configs {
+		config.Name = strings.ToUpper(strings.TrimSpace(config.Name))
+		if config.Name == constants.GoogleProvider || config.Name == constants.InternalProvider {
+			return fmt.Errorf("the provider name '%s' is reserved", config.Name)
+		}
+		if _, ok := r.verifiers[config.Name]; ok {
+			return fmt.Errorf("the provider '%s' is configured twice", config.Name)
+		}
+
+		verifier, err := NewOIDCVerifier(config, client)
+		if err != nil {
+			return err
+		}
+		r.Register(config.Name, verifier)
+	}
+	return nil
+}
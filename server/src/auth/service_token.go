@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ServiceTokenAudience is the audience of the tokens issued to other services, the users tokens don't have it
+// so one can't be used in place of the other
+const ServiceTokenAudience = "ThePsyducks-internal"
+
+// ServiceClaims are the claims of the tokens issued to the service clients, the scopes are space separated
+type ServiceClaims struct {
+	ClientId string `json:"client_id"`
+	Scope    string `json:"scope"`
+	jwt.RegisteredClaims
+}
+
+// Scopes returns the scopes granted to the client
+func (c *ServiceClaims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+// HasScope tells if the client was granted the scope
+func (c *ServiceClaims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes(), scope)
+}
+
+// GenerateServiceToken issues a token for a service client with the given scopes that lasts duration
+func GenerateServiceToken(clientId string, scopes []string, duration time.Duration) (string, error) {
+	keys, err := signingKeys()
+	if err != nil {
+		return "", fmt.Errorf("error loading signing keys: %w", err)
+	}
+
+	claims := ServiceClaims{
+		ClientId: clientId,
+		Scope:    strings.Join(scopes, " "),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   clientId,
+			Audience:  jwt.ClaimStrings{ServiceTokenAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    "ThePsyducks-users-service",
+		},
+	}
+
+	sign, err := keys.Sign(claims)
+	if err != nil {
+		return "", fmt.Errorf("error signing service token: %w", err)
+	}
+
+	return sign, nil
+}
+
+// ValidateServiceToken validates a token issued by GenerateServiceToken, the users tokens are rejected.
+// Only the signing keys are accepted, never JWT_SECRET
+func ValidateServiceToken(tokenString string) (*ServiceClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &ServiceClaims{}, keyfunc,
+		jwt.WithValidMethods(validMethods), jwt.WithAudience(ServiceTokenAudience), jwt.WithExpirationRequired())
+
+	if err != nil {
+		return nil, fmt.Errorf("error parsing service token: %w", err)
+	}
+
+	claims, ok := token.Claims.(*ServiceClaims)
+	if !ok || !token.Valid || claims.ClientId == "" {
+		return nil, fmt.Errorf("invalid service token")
+	}
+
+	return claims, nil
+}
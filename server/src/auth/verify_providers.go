@@ -3,36 +3,62 @@ package auth
 import (
 	"context"
 	"fmt"
-	"os"
 	"time"
+	"users-service/src/constants"
 
 	firebase "firebase.google.com/go/v4"
 	"firebase.google.com/go/v4/auth"
 	"google.golang.org/api/option"
 )
 
-func IsGoogleTokenValid(idToken string) (bool, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-	defer cancel()
+// GoogleVerifier verifies the Firebase ID tokens of the users that sign in with Google. It is created once, the Firebase
+// client keeps the Google public keys until they expire so they are not fetched for every token
+type GoogleVerifier struct {
+	client  *auth.Client
+	timeout time.Duration
+}
 
-	opt := option.WithCredentialsFile(os.Getenv("FIREBASE_CREDENTIALS_FILE"))
-	app, err := firebase.NewApp(ctx, nil, opt)
+// NewGoogleVerifier creates the Firebase app of the service account credentials, each verification takes at most timeout
+func NewGoogleVerifier(ctx context.Context, credentials []byte, timeout time.Duration) (*GoogleVerifier, error) {
+	app, err := firebase.NewApp(ctx, nil, option.WithCredentialsJSON(credentials))
 	if err != nil {
-		return false, fmt.Errorf("error initializing firebase app: %w", err)
+		return nil, fmt.Errorf("error initializing firebase app: %w", err)
 	}
 
 	client, err := app.Auth(ctx)
 	if err != nil {
-		return false, fmt.Errorf("error getting firebase auth client: %w", err)
+		return nil, fmt.Errorf("error getting firebase auth client: %w", err)
 	}
 
-	_, err = client.VerifyIDToken(ctx, idToken)
+	return &GoogleVerifier{client: client, timeout: timeout}, nil
+}
+
+func (v *GoogleVerifier) Verify(ctx context.Context, idToken string) (VerifiedIdentity, error) {
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+
+	token, err := v.client.VerifyIDToken(ctx, idToken)
 	if err != nil {
-		if ok := auth.IsIDTokenInvalid(err); ok {
-			return false, nil
+		if auth.IsIDTokenInvalid(err) || auth.IsIDTokenExpired(err) {
+			return VerifiedIdentity{}, fmt.Errorf("%w: %w", ErrInvalidIdentityToken, err)
 		}
-		return false, fmt.Errorf("error verificando el token de ID: %w", err)
+		return VerifiedIdentity{}, fmt.Errorf("error verifying google token: %w", err)
+	}
+
+	return googleIdentity(token)
+}
+
+// googleIdentity reads the identity of a verified Firebase token, the email is only trusted if Google verified it
+func googleIdentity(token *auth.Token) (VerifiedIdentity, error) {
+	if token.UID == "" {
+		return VerifiedIdentity{}, fmt.Errorf("%w: the token has no subject", ErrInvalidIdentityToken)
+	}
+
+	email, _ := token.Claims["email"].(string)
+	verified, _ := token.Claims["email_verified"].(bool)
+	if email == "" || !verified {
+		return VerifiedIdentity{}, ErrUnverifiedEmail
 	}
 
-	return true, nil
+	return VerifiedIdentity{Provider: constants.GoogleProvider, Subject: token.UID, Email: email}, nil
 }
@@ -1,6 +1,11 @@
 package config
 
-import "os"
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
 
 // Config struct that holds the configuration of the server
 type Config struct {
@@ -12,14 +17,64 @@ type Config struct {
 	DatabaseName     string
 	DatabaseUser     string
 	DatabasePassword string
+
+	FollowCountersReconciliationInterval time.Duration
+
+	AutocompleteCacheSize int
+	AutocompleteCacheTTL  time.Duration
+
+	RecommendationsRefreshInterval time.Duration
+
+	CatalogCacheTTL time.Duration
+
+	SigningKeysDir                   string
+	SigningKeysRotationCheckInterval time.Duration
+
+	FirebaseCredentials     []byte
+	OIDCProvidersFile       string
+	IdentityProviderTimeout time.Duration
 }
 
 // LoadConfig loads the configuration from the Environment variables
 func LoadConfig() (*Config, error) {
-	err := buildFirebaseConfig()
+	firebaseCredentials, err := buildFirebaseCredentials()
+	if err != nil {
+		return nil, err
+	}
+	reconciliationInterval, err := getDurationEnvOrDefault("FOLLOW_COUNTERS_RECONCILIATION_INTERVAL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	autocompleteCacheSize, err := getIntEnvOrDefault("AUTOCOMPLETE_CACHE_SIZE", 0)
+	if err != nil {
+		return nil, err
+	}
+	autocompleteCacheTTL, err := getDurationEnvOrDefault("AUTOCOMPLETE_CACHE_TTL", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	recommendationsRefreshInterval, err := getDurationEnvOrDefault("RECOMMENDATIONS_REFRESH_INTERVAL", 6*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	catalogCacheTTL, err := getDurationEnvOrDefault("CATALOG_CACHE_TTL", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	signingKeysRotationCheckInterval, err := getDurationEnvOrDefault("JWT_KEYS_ROTATION_CHECK_INTERVAL", time.Hour)
 	if err != nil {
 		return nil, err
 	}
+
+	identityProviderTimeout, err := getDurationEnvOrDefault("IDENTITY_PROVIDER_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Config{
 		Host:             getEnvOrDefault("HOST", "0.0.0.0"),
 		Port:             getEnvOrDefault("PORT", "8080"),
@@ -29,6 +84,22 @@ func LoadConfig() (*Config, error) {
 		DatabaseName:     os.Getenv("DATABASE_NAME"),
 		DatabaseUser:     os.Getenv("DATABASE_USER"),
 		DatabasePassword: os.Getenv("DATABASE_PASSWORD"),
+
+		FollowCountersReconciliationInterval: reconciliationInterval,
+
+		AutocompleteCacheSize: autocompleteCacheSize,
+		AutocompleteCacheTTL:  autocompleteCacheTTL,
+
+		RecommendationsRefreshInterval: recommendationsRefreshInterval,
+
+		CatalogCacheTTL: catalogCacheTTL,
+
+		SigningKeysDir:                   os.Getenv("JWT_KEYS_DIR"),
+		SigningKeysRotationCheckInterval: signingKeysRotationCheckInterval,
+
+		FirebaseCredentials:     firebaseCredentials,
+		OIDCProvidersFile:       os.Getenv("OIDC_PROVIDERS_FILE"),
+		IdentityProviderTimeout: identityProviderTimeout,
 	}, nil
 }
 
@@ -37,4 +108,32 @@ func getEnvOrDefault(key, defaultValue string) string {
 		return value
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
+
+// getDurationEnvOrDefault parses a duration like "30m" or "1h" from the environment
+func getDurationEnvOrDefault(key string, defaultValue time.Duration) (time.Duration, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration for %s: %w", key, err)
+	}
+	return duration, nil
+}
+
+// getIntEnvOrDefault parses an integer from the environment
+func getIntEnvOrDefault(key string, defaultValue int) (int, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer for %s: %w", key, err)
+	}
+	return number, nil
+}
@@ -21,11 +21,25 @@ type firebaseConfig struct {
 	UniverseDomain          string `json:"universe_domain"`
 }
 
-// BuildFirebaseConfig builds the Firebase configuration from environment variables
-func buildFirebaseConfig() error {
+// buildFirebaseCredentials builds the Firebase service account credentials from environment variables, they are only
+// kept in memory. Without a private key in the environment they are read from FIREBASE_CREDENTIALS_FILE, if set,
+// and with neither there are no credentials and no one can sign in with Google
+func buildFirebaseCredentials() ([]byte, error) {
 	privateKey := os.Getenv("FIREBASE_PRIVATE_KEY")
+	if privateKey == "" {
+		fileName := os.Getenv("FIREBASE_CREDENTIALS_FILE")
+		if fileName == "" {
+			return nil, nil
+		}
+		credentials, err := os.ReadFile(fileName)
+		if err != nil {
+			return nil, fmt.Errorf("error reading firebase credentials file: %w", err)
+		}
+		return credentials, nil
+	}
+
 	formatedPrivateKey := strings.ReplaceAll(privateKey, "\\n", "\n")
-	configFile := &firebaseConfig{
+	credentials := &firebaseConfig{
 		Type:                    "service_account",
 		ProjectID:               os.Getenv("FIREBASE_PROJECT_ID"),
 		PrivateKeyID:            os.Getenv("FIREBASE_PRIVATE_KEY_ID"),
@@ -39,19 +53,9 @@ func buildFirebaseConfig() error {
 		UniverseDomain:          os.Getenv("FIREBASE_UNIVERSE_DOMAIN"),
 	}
 
-	jsonData, err := json.MarshalIndent(configFile, "", "  ")
-	if err != nil {
-		return fmt.Errorf("error marshaling JSON: %w", err)
-	}
-
-	fileName := os.Getenv("FIREBASE_CREDENTIALS_FILE")
-	if fileName == "" {
-		fileName = "firebase_credentials.json"
-	}
-	err = os.WriteFile(fileName, jsonData, 0644)
+	jsonData, err := json.Marshal(credentials)
 	if err != nil {
-		return fmt.Errorf("error writing to file: %w", err)
+		return nil, fmt.Errorf("error marshaling JSON: %w", err)
 	}
-
-	return nil
-}
\ No newline at end of file
+	return jsonData, nil
+}
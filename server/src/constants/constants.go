@@ -1,5 +1,7 @@
 package constants
 
+import "time"
+
 // Validation constants
 const (
 	MinPasswordLength 	= 8
@@ -14,8 +16,55 @@ const (
 	MaxLastNameLength 	= 100
 	MinInterests 		= 1
 	MaxInterests 		= 100
+	MaxCatalogEntryNameLength = 255
+	MaxCatalogEntryIconLength = 32
+	MaxCatalogEntryDescriptionLength = 500
+	MaxBioLength		= 500
+	MaxWebsiteLength	= 255
+	MaxPronounsLength	= 32
+)
+
+// Users must be at least MinUserAge years old to sign up, birth dates that would make them older than MaxUserAge are rejected
+const (
+	MinUserAge = 13
+	MaxUserAge = 120
+)
+
+// A user can change its username once every UsernameChangeCooldown. The usernames it leaves are held for
+// UsernameHoldPeriod, nobody else can take them and they still lead to the user
+const (
+	UsernameChangeCooldown = 30 * 24 * time.Hour
+	UsernameHoldPeriod     = 14 * 24 * time.Hour
+)
+
+// Scopes the service clients can be granted, each one lets them call some of the internal endpoints
+const (
+	ScopeUsersRead   = "users:read"
+	ScopeMetricsRead = "metrics:read"
+)
+
+var ServiceScopes = []string{ScopeUsersRead, ScopeMetricsRead}
+
+// Service clients constants. The tokens issued to them last ServiceTokenDuration and, when a secret is rotated,
+// the previous one keeps working for ServiceSecretGracePeriod so the clients can be updated
+const (
+	MaxServiceClientNameLength = 100
+	ServiceTokenDuration       = time.Hour
+	ServiceSecretGracePeriod   = 24 * time.Hour
+)
+
+// Name this service uses as client when it calls the other services of the platform,
+// and the scope it asks the notifications service for
+const (
+	UsersServiceClientName = "users-service"
+	ScopeNotificationsSend = "notifications:send"
 )
 
+// Languages the catalogs are translated to, the default one is used when the client asks for none of them
+const DefaultLanguage = "en"
+
+var SupportedLanguages = []string{DefaultLanguage, "es"}
+
 // Resolver constants
 const (
 	LoginStep = "LOGIN"
@@ -45,4 +94,42 @@ const (
 	NewUser 				= "NEW_USER"
 )
 
-const MaxPaginationLimit = 20
\ No newline at end of file
+const MaxPaginationLimit = 20
+// Nearby users constants, the radius is in kilometers
+const (
+	DefaultNearbyRadiusKm = 50.0
+	MaxNearbyRadiusKm     = 1000.0
+)
+
+// Most users that can be asked for in a batch, counting both ids and usernames
+const MaxBatchUsers = 100
+
+// Autocomplete constants
+const (
+	DefaultAutocompleteLimit = 5
+	MaxAutocompleteLimit     = 10
+)
+
+// Recommendation weights, the score of a recommended user is the sum of its signals multiplied by them.
+// The popularity is measured as ln(1 + followers) so the most followed users don't take over the recommendations
+const (
+	RecommendationSecondDegreeWeight   = 2.0
+	RecommendationSharedInterestWeight = 1.0
+	RecommendationSharedCategoryWeight = 0.5
+	RecommendationSameLocationWeight   = 1.5
+	RecommendationPopularityWeight     = 0.25
+)
+
+// Recommendations shown more than RecommendationFreeImpressions times without being followed
+// get their score divided by 1 + RecommendationImpressionPenalty for each extra impression
+const (
+	RecommendationFreeImpressions   = 3
+	RecommendationImpressionPenalty = 0.5
+)
+
+// Precomputed recommendations constants
+const (
+	PrecomputedRecommendations      = 100
+	RecommendationsRefreshBatchSize = 500
+	RecommendationsRefreshQueueSize = 1000
+)
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"users-service/src/app_errors"
+	"users-service/src/model"
+)
+
+// GetUserIdentities returns the identity providers the session user can sign in with
+func (u *User) GetUserIdentities(c *gin.Context) {
+	userSessionId, err := getSessionUserId(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	identities, err := u.service.GetUserIdentities(userSessionId)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": identities})
+}
+
+// LinkUserIdentity links the identity provider account of the token in the body to the session user
+func (u *User) LinkUserIdentity(c *gin.Context) {
+	userSessionId, err := getSessionUserId(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	var data model.LinkIdentityRequest
+	if err := c.BindJSON(&data); err != nil {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid data in request", err)
+		_ = c.Error(err)
+		return
+	}
+
+	identity, err := u.service.LinkUserIdentity(c.Request.Context(), userSessionId, data)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, identity)
+}
+
+// UnlinkUserIdentity unlinks the account of the identity provider from the session user
+func (u *User) UnlinkUserIdentity(c *gin.Context) {
+	userSessionId, err := getSessionUserId(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := u.service.UnlinkUserIdentity(userSessionId, c.Param("provider")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{})
+}
@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"users-service/src/app_errors"
+	"users-service/src/auth"
+)
+
+// jwksMaxAge is how long the other services can cache the keys, a new key is published
+// well before it signs any token they could not verify
+const jwksMaxAge = 300
+
+// GetJWKS returns the public keys the other services verify the tokens with
+func (u *User) GetJWKS(c *gin.Context) {
+	jwks, err := auth.GetJWKS()
+	if err != nil {
+		err = app_errors.NewAppError(http.StatusInternalServerError, "Internal server error", fmt.Errorf("error retrieving signing keys: %w", err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", jwksMaxAge))
+	c.JSON(http.StatusOK, jwks)
+}
@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"users-service/src/app_errors"
+	"users-service/src/model"
+)
+
+// IssueServiceToken exchanges the credentials of a service client for a token, as a client credentials grant
+func (u *User) IssueServiceToken(c *gin.Context) {
+	var data model.ServiceTokenRequest
+	if err := c.ShouldBind(&data); err != nil {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid data in request", err)
+		_ = c.Error(err)
+		return
+	}
+
+	token, err := u.service.IssueServiceToken(data)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, token)
+}
+
+func (u *User) GetServiceClients(c *gin.Context) {
+	userSessionIsAdmin := c.GetBool("session_user_admin")
+
+	clients, err := u.service.GetServiceClients(userSessionIsAdmin)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": clients})
+}
+
+func (u *User) CreateServiceClient(c *gin.Context) {
+	userSessionIsAdmin := c.GetBool("session_user_admin")
+
+	var data model.CreateServiceClientRequest
+	if err := c.BindJSON(&data); err != nil {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid data in request", err)
+		_ = c.Error(err)
+		return
+	}
+
+	credentials, err := u.service.CreateServiceClient(userSessionIsAdmin, data)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, credentials)
+}
+
+func (u *User) RotateServiceClientSecret(c *gin.Context) {
+	userSessionIsAdmin := c.GetBool("session_user_admin")
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid data in request", err)
+		_ = c.Error(err)
+		return
+	}
+
+	credentials, err := u.service.RotateServiceClientSecret(userSessionIsAdmin, id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, credentials)
+}
+
+func (u *User) RevokeServiceClient(c *gin.Context) {
+	userSessionIsAdmin := c.GetBool("session_user_admin")
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid data in request", err)
+		_ = c.Error(err)
+		return
+	}
+
+	if err := u.service.RevokeServiceClient(userSessionIsAdmin, id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{})
+}
+
+// GetInternalUserProfile returns the public profile of a user to a service client
+func (u *User) GetInternalUserProfile(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid data in request", err)
+		_ = c.Error(err)
+		return
+	}
+
+	user, err := u.service.GetUserProfileById(uuid.Nil, false, id, c.GetString("language"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// GetInternalUsersBatch returns the compact profiles of several users to a service client,
+// it follows nobody so the relationships are always false
+func (u *User) GetInternalUsersBatch(c *gin.Context) {
+	var request model.UserBatchRequest
+	if err := c.BindJSON(&request); err != nil {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid data in request", err)
+		_ = c.Error(err)
+		return
+	}
+
+	users, err := u.service.GetUsersBatch(uuid.Nil, request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// GetInternalAmountOfFollowers returns how many followers a user got in a time range to a service client
+func (u *User) GetInternalAmountOfFollowers(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid data in request", err)
+		_ = c.Error(err)
+		return
+	}
+	startTime, endTime, err := getTimeRangeQueryParams(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	amount, err := u.service.GetAmountOfFollowersInTimeRange(id, startTime, endTime)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"new_followers": amount})
+}
@@ -1,10 +1,12 @@
 package controller
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -13,7 +15,6 @@ import (
 	"github.com/google/uuid"
 
 	"users-service/src/app_errors"
-	"users-service/src/auth"
 	"users-service/src/constants"
 	"users-service/src/model"
 	"users-service/src/service"
@@ -33,13 +34,53 @@ func (u *User) HandleNoRoute(c *gin.Context) {
 }
 
 func (u *User) GetLocations(c *gin.Context) {
-	data := u.service.GetLocations()
+	var parentId *int
+	if parentStr := c.Query("parent"); parentStr != "" {
+		id, err := strconv.Atoi(parentStr)
+		if err != nil {
+			err = app_errors.NewAppError(http.StatusBadRequest, "Invalid 'parent' value in request", fmt.Errorf("invalid parent: %s", parentStr))
+			_ = c.Error(err)
+			return
+		}
+		parentId = &id
+	}
+
+	data, err := u.service.GetLocations(parentId, c.GetString("language"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
 
 	c.JSON(http.StatusOK, data)
 }
 
 func (u *User) GetInterests(c *gin.Context) {
-	data := u.service.GetInterests()
+	var categoryId *int
+	if categoryStr := c.Query("category"); categoryStr != "" {
+		id, err := strconv.Atoi(categoryStr)
+		if err != nil {
+			err = app_errors.NewAppError(http.StatusBadRequest, "Invalid 'category' value in request", fmt.Errorf("invalid category: %s", categoryStr))
+			_ = c.Error(err)
+			return
+		}
+		categoryId = &id
+	}
+
+	data, err := u.service.GetInterests(categoryId, c.GetString("language"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, data)
+}
+
+func (u *User) GetInterestCategories(c *gin.Context) {
+	data, err := u.service.GetInterestCategories(c.GetString("language"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
 
 	c.JSON(http.StatusOK, data)
 }
@@ -52,34 +93,18 @@ func (u *User) ResolveUserEmail(c *gin.Context) {
 		return
 	}
 
-	var identityProvider *string
-	switch data.ProviderData.Name {
-	case constants.GoogleProvider:
-		var googleMetadata model.GoogleAuthMetadata
-		if err := json.Unmarshal(data.ProviderData.Metadata, &googleMetadata); err != nil {
-			err = app_errors.NewAppError(http.StatusBadRequest, "Invalid data in request", err)
+	if data.ProviderData.Name != "" {
+		slog.Info("authenticating with identity provider", slog.String("provider", data.ProviderData.Name))
+		user, err := u.service.ResolveUserWithProvider(c.Request.Context(), data.ProviderData.Name, data.Email, data.ProviderData.Metadata, c.GetString("language"))
+		if err != nil {
 			_ = c.Error(err)
 			return
 		}
-		slog.Info("authenticating Google provider")
-		if isValid, err := auth.IsGoogleTokenValid(googleMetadata.FirebaseTokenId); err != nil {
-			err = app_errors.NewAppError(http.StatusInternalServerError, "Internal server error", fmt.Errorf("error validating google token: %w", err))
-			_ = c.Error(err)
-			return
-		} else if !isValid {
-			err := app_errors.NewAppError(http.StatusUnauthorized, "Invalid token", fmt.Errorf("google token is invalid: %s", googleMetadata.FirebaseTokenId))
-			_ = c.Error(err)
-			return
-		}
-		identityProvider = &data.ProviderData.Name
-	case "":
-	default:
-		err := app_errors.NewAppError(http.StatusBadRequest, "Unknown provider type", nil)
-		_ = c.Error(err)
+		c.JSON(http.StatusOK, user)
 		return
 	}
 
-	user, err := u.service.ResolveUserEmail(data.Email, identityProvider)
+	user, err := u.service.ResolveUserEmail(data.Email, c.GetString("language"))
 	if err != nil {
 		_ = c.Error(err)
 		return
@@ -196,7 +221,7 @@ func (u *User) CompleteRegistry(c *gin.Context) {
 		return
 	}
 
-	userResponse, err := u.service.CompleteRegistry(registrationId)
+	userResponse, err := u.service.CompleteRegistry(registrationId, c.GetString("language"))
 	if err != nil {
 		_ = c.Error(err)
 		return
@@ -214,7 +239,7 @@ func (u *User) Login(c *gin.Context) {
 		return
 	}
 
-	token, profile, err := u.service.LoginUser(data)
+	token, profile, err := u.service.LoginUser(data, c.GetString("language"))
 
 	if err != nil {
 		_ = c.Error(err)
@@ -260,16 +285,70 @@ func (u *User) GetUserProfileById(c *gin.Context) {
 	}
 
 	userSessionIsAdmin := c.GetBool("session_user_admin")
-	user, err := u.service.GetUserProfileById(userSessionId, userSessionIsAdmin, id)
+	user, err := u.service.GetUserProfileById(userSessionId, userSessionIsAdmin, id, c.GetString("language"))
 
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
 
+	if profile, ok := user.Profile.(model.UserPrivateProfile); ok {
+		c.Header("ETag", profile.ETag())
+	}
 	c.JSON(http.StatusOK, user)
 }
 
+// GetUserProfileByUsername returns the profile of the user with the username, the usernames that were left
+// recently redirect to the current username of their user
+func (u *User) GetUserProfileByUsername(c *gin.Context) {
+	userSessionId, err := getSessionUserId(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userSessionIsAdmin := c.GetBool("session_user_admin")
+	user, currentUsername, err := u.service.GetUserProfileByUsername(userSessionId, userSessionIsAdmin, c.Param("username"), c.GetString("language"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if currentUsername != "" {
+		c.Redirect(http.StatusFound, "/users/by-username/"+url.PathEscape(currentUsername))
+		return
+	}
+
+	if profile, ok := user.Profile.(model.UserPrivateProfile); ok {
+		c.Header("ETag", profile.ETag())
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// GetUsersBatch returns the compact profiles of several users at once, by id or by username
+func (u *User) GetUsersBatch(c *gin.Context) {
+	userSessionId, err := getSessionUserId(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	var request model.UserBatchRequest
+	if err := c.BindJSON(&request); err != nil {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid data in request", err)
+		_ = c.Error(err)
+		return
+	}
+
+	users, err := u.service.GetUsersBatch(userSessionId, request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
 func (u *User) ModifyUserProfile(c *gin.Context) {
 	sessionUserId, err := getSessionUserId(c)
 	if err != nil {
@@ -284,13 +363,39 @@ func (u *User) ModifyUserProfile(c *gin.Context) {
 		return
 	}
 
-	userProfile, err := u.service.ModifyUserProfile(sessionUserId, data)
+	userProfile, err := u.service.ModifyUserProfile(sessionUserId, data, c.GetString("language"))
+
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("ETag", userProfile.ETag())
+	c.JSON(http.StatusOK, userProfile)
+}
+
+// PatchUserProfile modifies only the fields of the profile of the session user that are in the JSON merge patch
+func (u *User) PatchUserProfile(c *gin.Context) {
+	sessionUserId, err := getSessionUserId(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
 
+	body, err := c.GetRawData()
 	if err != nil {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid data in request", err)
 		_ = c.Error(err)
 		return
 	}
 
+	userProfile, err := u.service.PatchUserProfile(sessionUserId, body, c.GetHeader("If-Match"), c.GetString("language"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("ETag", userProfile.ETag())
 	c.JSON(http.StatusOK, userProfile)
 }
 
@@ -301,7 +406,7 @@ func (u *User) FollowUser(c *gin.Context) {
 		return
 	}
 
-	err = u.service.FollowUser(userSessionId, userToFollowId, c.GetString("token"))
+	err = u.service.FollowUser(userSessionId, userToFollowId)
 	if err != nil {
 		_ = c.Error(err)
 		return
@@ -326,26 +431,26 @@ func (u *User) UnfollowUser(c *gin.Context) {
 	c.JSON(http.StatusNoContent, gin.H{})
 }
 
-func getPaginationParams(c *gin.Context) (string, int, int, error) {
+func getPaginationParams(c *gin.Context) (model.PageRequest, error) {
 	timestampStr := c.DefaultQuery("time", time.Now().UTC().Format(time.RFC3339))
-	_, err := time.Parse(time.RFC3339, timestampStr)
+	timestamp, err := time.Parse(time.RFC3339, timestampStr)
 	if err != nil {
 		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid 'timestamp' value in request. Must be in RFC3339 format.", err)
-		return "", 0, 0, err
+		return model.PageRequest{}, err
 	}
 
 	skipStr := c.DefaultQuery("skip", "0")
 	skipInt, err := strconv.Atoi(skipStr)
-	if err != nil {
-		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid 'skip' value in request", err)
-		return "", 0, 0, err
+	if err != nil || skipInt < 0 {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid 'skip' value in request", fmt.Errorf("invalid skip: %s", skipStr))
+		return model.PageRequest{}, err
 	}
 
 	limitStr := c.DefaultQuery("limit", "20")
 	limitInt, err := strconv.Atoi(limitStr)
-	if err != nil {
-		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid 'limit' value in request", err)
-		return "", 0, 0, err
+	if err != nil || limitInt < 1 {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid 'limit' value in request", fmt.Errorf("invalid limit: %s", limitStr))
+		return model.PageRequest{}, err
 	}
 
 	if limitInt > constants.MaxPaginationLimit {
@@ -353,7 +458,60 @@ func getPaginationParams(c *gin.Context) (string, int, int, error) {
 			slog.Int("limit", limitInt), slog.Int("max", constants.MaxPaginationLimit))
 		limitInt = constants.MaxPaginationLimit
 	}
-	return timestampStr, skipInt, limitInt, nil
+
+	page := model.PageRequest{
+		Timestamp: timestamp,
+		Skip:      skipInt,
+		Limit:     limitInt,
+	}
+
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		cursor, err := model.DecodeCursor(cursorStr)
+		if err != nil {
+			err = app_errors.NewAppError(http.StatusBadRequest, "Invalid 'cursor' value in request", err)
+			return model.PageRequest{}, err
+		}
+		page.Cursor = &cursor
+	}
+	return page, nil
+}
+
+// getSearchFiltersParams parses the optional 'location' id and the comma separated 'interests' and 'categories' ids of the search
+func getSearchFiltersParams(c *gin.Context) (model.SearchFiltersRequest, error) {
+	filters := model.SearchFiltersRequest{}
+
+	if locationStr := c.Query("location"); locationStr != "" {
+		locationId, err := strconv.Atoi(locationStr)
+		if err != nil {
+			err = app_errors.NewAppError(http.StatusBadRequest, "Invalid 'location' value in request", fmt.Errorf("invalid location: %s", locationStr))
+			return model.SearchFiltersRequest{}, err
+		}
+		filters.LocationId = &locationId
+	}
+
+	if interestsStr := c.Query("interests"); interestsStr != "" {
+		for _, interestStr := range strings.Split(interestsStr, ",") {
+			interestId, err := strconv.Atoi(strings.TrimSpace(interestStr))
+			if err != nil {
+				err = app_errors.NewAppError(http.StatusBadRequest, "Invalid 'interests' value in request", fmt.Errorf("invalid interests: %s", interestsStr))
+				return model.SearchFiltersRequest{}, err
+			}
+			filters.InterestIds = append(filters.InterestIds, interestId)
+		}
+	}
+
+	if categoriesStr := c.Query("categories"); categoriesStr != "" {
+		for _, categoryStr := range strings.Split(categoriesStr, ",") {
+			categoryId, err := strconv.Atoi(strings.TrimSpace(categoryStr))
+			if err != nil {
+				err = app_errors.NewAppError(http.StatusBadRequest, "Invalid 'categories' value in request", fmt.Errorf("invalid categories: %s", categoriesStr))
+				return model.SearchFiltersRequest{}, err
+			}
+			filters.CategoryIds = append(filters.CategoryIds, categoryId)
+		}
+	}
+
+	return filters, nil
 }
 
 func (u *User) GetFollowers(c *gin.Context) {
@@ -362,19 +520,19 @@ func (u *User) GetFollowers(c *gin.Context) {
 		_ = c.Error(err)
 		return
 	}
-	timestamp, skip, limit, err := getPaginationParams(c)
+	page, err := getPaginationParams(c)
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
 
-	followers, hasMore, err := u.service.GetFollowers(userToGetFollowersId, userSessionId, timestamp, skip, limit)
+	followers, next, err := u.service.GetFollowers(userToGetFollowersId, userSessionId, page, c.GetString("language"))
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
 
-	response := model.CreatePaginationResponse(followers, limit, skip, hasMore)
+	response := model.CreatePaginationResponse(followers, page, next)
 	c.JSON(http.StatusOK, response)
 }
 
@@ -384,22 +542,60 @@ func (u *User) GetFollowing(c *gin.Context) {
 		_ = c.Error(err)
 		return
 	}
-	timestamp, skip, limit, err := getPaginationParams(c)
+	page, err := getPaginationParams(c)
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
 
-	following, hasMore, err := u.service.GetFollowing(userToGetFollowingId, userSessionId, timestamp, skip, limit)
+	following, next, err := u.service.GetFollowing(userToGetFollowingId, userSessionId, page, c.GetString("language"))
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
 
-	response := model.CreatePaginationResponse(following, limit, skip, hasMore)
+	response := model.CreatePaginationResponse(following, page, next)
 	c.JSON(http.StatusOK, response)
 }
 
+func (u *User) GetMutuals(c *gin.Context) {
+	userToGetMutualsId, userSessionId, err := getUrlIdAndSessionUserId(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	page, err := getPaginationParams(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	mutuals, next, err := u.service.GetMutuals(userToGetMutualsId, userSessionId, page, c.GetString("language"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := model.CreatePaginationResponse(mutuals, page, next)
+	c.JSON(http.StatusOK, response)
+}
+
+func (u *User) GetRelationship(c *gin.Context) {
+	id, userSessionId, err := getUrlIdAndSessionUserId(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	relationship, err := u.service.GetRelationship(userSessionId, id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, relationship)
+}
+
 func (u *User) SearchUsers(c *gin.Context) {
 	userSessionId, err := getSessionUserId(c)
 	if err != nil {
@@ -407,29 +603,69 @@ func (u *User) SearchUsers(c *gin.Context) {
 		return
 	}
 
-	timestamp, skip, limit, err := getPaginationParams(c)
+	page, err := getPaginationParams(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	filters, err := getSearchFiltersParams(c)
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
 
 	text := c.DefaultQuery("text", "")
-	if strings.TrimSpace(text) == "" {
-		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid 'text' value in request. Must not be empty.", fmt.Errorf("invalid search text"))
+	if strings.TrimSpace(text) == "" && filters.IsEmpty() {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid 'text' value in request. Must not be empty when there are no filters.", fmt.Errorf("invalid search text"))
 		_ = c.Error(err)
 		return
 	}
 
-	users, hasMore, err := u.service.SearchUsers(userSessionId, text, timestamp, skip, limit)
+	users, next, err := u.service.SearchUsers(userSessionId, text, filters, page, c.GetString("language"))
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
 
-	response := model.CreatePaginationResponse(users, limit, skip, hasMore)
+	response := model.CreatePaginationResponse(users, page, next)
 	c.JSON(http.StatusOK, response)
 }
 
+func (u *User) AutocompleteUsernames(c *gin.Context) {
+	userSessionId, err := getSessionUserId(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	prefix := strings.TrimSpace(c.Query("prefix"))
+	if prefix == "" || len(prefix) > constants.MaxUsernameLength {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid 'prefix' value in request", fmt.Errorf("invalid prefix: %s", prefix))
+		_ = c.Error(err)
+		return
+	}
+
+	limitStr := c.DefaultQuery("limit", strconv.Itoa(constants.DefaultAutocompleteLimit))
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid 'limit' value in request", fmt.Errorf("invalid limit: %s", limitStr))
+		_ = c.Error(err)
+		return
+	}
+	if limit > constants.MaxAutocompleteLimit {
+		limit = constants.MaxAutocompleteLimit
+	}
+
+	suggestions, err := u.service.AutocompleteUsernames(userSessionId, prefix, limit)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": suggestions})
+}
+
 func (u *User) RecommendUsers(c *gin.Context) {
 	userSessionId, err := getSessionUserId(c)
 	if err != nil {
@@ -437,37 +673,91 @@ func (u *User) RecommendUsers(c *gin.Context) {
 		return
 	}
 
-	timestamp, skip, limit, err := getPaginationParams(c)
+	page, err := getPaginationParams(c)
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
 
-	users, hasMore, err := u.service.RecommendUsers(userSessionId, timestamp, skip, limit)
+	users, next, err := u.service.RecommendUsers(userSessionId, page, c.GetString("language"))
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
 
-	response := model.CreatePaginationResponse(users, limit, skip, hasMore)
+	response := model.CreatePaginationResponse(users, page, next)
 	c.JSON(http.StatusOK, response)
 }
 
+func (u *User) GetNearbyUsers(c *gin.Context) {
+	userSessionId, err := getSessionUserId(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	radiusStr := c.DefaultQuery("radius_km", strconv.FormatFloat(constants.DefaultNearbyRadiusKm, 'f', -1, 64))
+	radiusKm, err := strconv.ParseFloat(radiusStr, 64)
+	if err != nil || !(radiusKm > 0 && radiusKm <= constants.MaxNearbyRadiusKm) {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid 'radius_km' value in request", fmt.Errorf("invalid radius_km: %s", radiusStr))
+		_ = c.Error(err)
+		return
+	}
+
+	page, err := getPaginationParams(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	users, next, err := u.service.GetNearbyUsers(userSessionId, radiusKm, page, c.GetString("language"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response := model.CreatePaginationResponse(users, page, next)
+	c.JSON(http.StatusOK, response)
+}
+
+func (u *User) DismissRecommendation(c *gin.Context) {
+	dismissedId, userSessionId, err := getUrlIdAndSessionUserId(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	// the body is optional, without it the dismissal never expires
+	var data model.DismissRecommendationRequest
+	if err := c.ShouldBindJSON(&data); err != nil && !errors.Is(err, io.EOF) {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid data in request", err)
+		_ = c.Error(err)
+		return
+	}
+
+	if err := u.service.DismissRecommendation(userSessionId, dismissedId, data.ExpiresAt); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{})
+}
+
 func (u *User) GetAllUsers(c *gin.Context) {
-	timestamp, skip, limit, err := getPaginationParams(c)
+	page, err := getPaginationParams(c)
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
 
 	userSessionIsAdmin := c.GetBool("session_user_admin")
-	users, hasMore, err := u.service.GetAllUsers(userSessionIsAdmin, timestamp, skip, limit)
+	users, next, err := u.service.GetAllUsers(userSessionIsAdmin, page, c.GetString("language"))
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
 
-	response := model.CreatePaginationResponse(users, limit, skip, hasMore)
+	response := model.CreatePaginationResponse(users, page, next)
 	c.JSON(http.StatusOK, response)
 }
 
@@ -479,7 +769,7 @@ func (u *User) GetUserInformation(c *gin.Context) {
 	}
 
 	userSessionIsAdmin := c.GetBool("session_user_admin")
-	user, err := u.service.GetUserInformation(userSessionId, userSessionIsAdmin, id)
+	user, err := u.service.GetUserInformation(userSessionId, userSessionIsAdmin, id, c.GetString("language"))
 
 	if err != nil {
 		_ = c.Error(err)
@@ -531,6 +821,132 @@ func (u *User) UnblockUser(c *gin.Context) {
 	c.JSON(http.StatusNoContent, gin.H{})
 }
 
+func (u *User) GetCatalogEntries(c *gin.Context) {
+	userSessionIsAdmin := c.GetBool("session_user_admin")
+	catalog := model.Catalog(c.Param("catalog"))
+
+	entries, err := u.service.GetCatalogEntries(userSessionIsAdmin, catalog)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": entries})
+}
+
+func (u *User) AddCatalogEntry(c *gin.Context) {
+	userSessionIsAdmin := c.GetBool("session_user_admin")
+	catalog := model.Catalog(c.Param("catalog"))
+
+	var data model.CreateCatalogEntryRequest
+	if err := c.BindJSON(&data); err != nil {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid data in request", err)
+		_ = c.Error(err)
+		return
+	}
+
+	entry, err := u.service.AddCatalogEntry(userSessionIsAdmin, catalog, data)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+func (u *User) UpdateCatalogEntry(c *gin.Context) {
+	userSessionIsAdmin := c.GetBool("session_user_admin")
+	catalog := model.Catalog(c.Param("catalog"))
+
+	id, err := strconv.Atoi(c.Param("entry_id"))
+	if err != nil {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid catalog entry id", err)
+		_ = c.Error(err)
+		return
+	}
+
+	var data model.UpdateCatalogEntryRequest
+	if err := c.BindJSON(&data); err != nil {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid data in request", err)
+		_ = c.Error(err)
+		return
+	}
+
+	entry, err := u.service.UpdateCatalogEntry(userSessionIsAdmin, catalog, id, data)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+func (u *User) ReorderCatalog(c *gin.Context) {
+	userSessionIsAdmin := c.GetBool("session_user_admin")
+	catalog := model.Catalog(c.Param("catalog"))
+
+	var data model.ReorderCatalogRequest
+	if err := c.BindJSON(&data); err != nil {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid data in request", err)
+		_ = c.Error(err)
+		return
+	}
+
+	entries, err := u.service.ReorderCatalog(userSessionIsAdmin, catalog, data.ParentId, data.Ids)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": entries})
+}
+
+func (u *User) SetCatalogEntryTranslation(c *gin.Context) {
+	userSessionIsAdmin := c.GetBool("session_user_admin")
+	catalog := model.Catalog(c.Param("catalog"))
+
+	id, err := strconv.Atoi(c.Param("entry_id"))
+	if err != nil {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid catalog entry id", err)
+		_ = c.Error(err)
+		return
+	}
+
+	var data model.SetCatalogTranslationRequest
+	if err := c.BindJSON(&data); err != nil {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid data in request", err)
+		_ = c.Error(err)
+		return
+	}
+
+	entry, err := u.service.SetCatalogEntryTranslation(userSessionIsAdmin, catalog, id, c.Param("language"), data)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+func (u *User) DeleteCatalogEntryTranslation(c *gin.Context) {
+	userSessionIsAdmin := c.GetBool("session_user_admin")
+	catalog := model.Catalog(c.Param("catalog"))
+
+	id, err := strconv.Atoi(c.Param("entry_id"))
+	if err != nil {
+		err = app_errors.NewAppError(http.StatusBadRequest, "Invalid catalog entry id", err)
+		_ = c.Error(err)
+		return
+	}
+
+	if err := u.service.DeleteCatalogEntryTranslation(userSessionIsAdmin, catalog, id, c.Param("language")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{})
+}
+
 func getTimeRangeQueryParams(c *gin.Context) (time.Time, time.Time, error) {
 	startTimeStr := c.Query("time")
 	endTimeStr := c.Query("end_time")
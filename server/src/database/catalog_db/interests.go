@@ -0,0 +1,66 @@
+package catalog_db
+
+import (
+	"fmt"
+	"users-service/src/database/register_options"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// createInterestsMetadata adds the icons and descriptions to the interests and their categories, and the category
+// to the interests. The predefined entries get theirs only when the columns are added, afterwards they are
+// left as the admins edit them
+func createInterestsMetadata(db *sqlx.DB) error {
+	var exists bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_name = $1 AND column_name = 'category_id'
+		)`
+	if err := db.Get(&exists, query, interestsTable); err != nil {
+		return fmt.Errorf("error checking the columns of %s: %w", interestsTable, err)
+	}
+
+	schema := fmt.Sprintf(`
+		ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS icon VARCHAR(32) NOT NULL DEFAULT '';
+		ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS description VARCHAR(500) NOT NULL DEFAULT '';
+		ALTER TABLE %[2]s ADD COLUMN IF NOT EXISTS icon VARCHAR(32) NOT NULL DEFAULT '';
+		ALTER TABLE %[2]s ADD COLUMN IF NOT EXISTS description VARCHAR(500) NOT NULL DEFAULT '';
+		ALTER TABLE %[2]s ADD COLUMN IF NOT EXISTS category_id INTEGER REFERENCES %[1]s(id);
+
+		CREATE INDEX IF NOT EXISTS idx_interests_category_id ON %[2]s (category_id);
+	`, interestCategoriesTable, interestsTable)
+
+	if _, err := db.Exec(schema); err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	if err := seedMetadata(db, interestCategoriesTable, register_options.GetInterestCategoryMetadata()); err != nil {
+		return fmt.Errorf("error seeding the metadata of the interest categories: %w", err)
+	}
+	if err := seedMetadata(db, interestsTable, register_options.GetInterestMetadata()); err != nil {
+		return fmt.Errorf("error seeding the metadata of the interests: %w", err)
+	}
+	return nil
+}
+
+func seedMetadata(db *sqlx.DB, table string, metadata map[int]register_options.CatalogMetadata) error {
+	query := fmt.Sprintf(`UPDATE %s SET icon = $2, description = $3 WHERE id = $1`, table)
+	if table == interestsTable {
+		query = fmt.Sprintf(`UPDATE %s SET icon = $2, description = $3, category_id = $4 WHERE id = $1`, table)
+	}
+
+	for id, entry := range metadata {
+		args := []interface{}{id, entry.Icon, entry.Description}
+		if table == interestsTable {
+			args = append(args, entry.CategoryId)
+		}
+		if _, err := db.Exec(query, args...); err != nil {
+			return fmt.Errorf("error updating entry %d: %w", id, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package catalog_db
+
+import "users-service/src/model"
+
+// CatalogDatabase interface to interact with the catalogs of interests, their categories and locations
+// it is used by the service layer
+type CatalogDatabase interface {
+	// GetCatalogEntries returns every entry of the catalog, deprecated ones included, sorted by their position.
+	// The locations of every level are returned together, linked by their parent id, each entry with its translations
+	GetCatalogEntries(catalog model.Catalog) ([]model.CatalogEntry, error)
+
+	// AddCatalogEntry adds an entry with the name and, if the catalog has them, the metadata of the given one
+	// after the last top level one of the catalog.
+	// It returns ErrKeyAlreadyExists if the catalog already has an entry with that name
+	AddCatalogEntry(catalog model.Catalog, entry model.CatalogEntry) (model.CatalogEntry, error)
+
+	// UpdateCatalogEntry sets the name, metadata and deprecation of the entry with the id of the given one,
+	// the users that have it see the new name right away.
+	// It returns ErrKeyNotFound if the entry does not exist and ErrKeyAlreadyExists if the name is taken
+	UpdateCatalogEntry(catalog model.Catalog, entry model.CatalogEntry) (model.CatalogEntry, error)
+
+	// ReorderCatalog sets the position of each entry to its index in ids, all of them at once
+	ReorderCatalog(catalog model.Catalog, ids []int) error
+
+	// SetCatalogEntryTranslation sets the name of an entry in a language, replacing the previous one
+	// it returns ErrKeyNotFound if the entry does not exist
+	SetCatalogEntryTranslation(catalog model.Catalog, id int, language string, name string) error
+
+	// DeleteCatalogEntryTranslation removes the name of an entry in a language, so the entry name is shown instead
+	// it returns ErrKeyNotFound if the entry has no translation to that language
+	DeleteCatalogEntryTranslation(catalog model.Catalog, id int, language string) error
+}
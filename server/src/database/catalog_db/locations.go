@@ -0,0 +1,115 @@
+package catalog_db
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"users-service/src/database/register_options"
+	"users-service/src/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// locationRecord is a location of the bundled dataset along with the ones inside it
+type locationRecord struct {
+	Code         string            `json:"code"`
+	Name         string            `json:"name"`
+	Latitude     float64           `json:"latitude"`
+	Longitude    float64           `json:"longitude"`
+	Translations map[string]string `json:"translations"`
+	Children     []locationRecord  `json:"children"`
+}
+
+// bundledLocations are the countries, provinces and cities shipped with the service, so no external
+// service is needed to place the users
+//
+//go:embed data/locations.json
+var bundledLocations []byte
+
+// locationKinds are the kinds of the levels of the dataset, from its roots to its leaves
+var locationKinds = []string{model.CountryLocation, model.ProvinceLocation, model.CityLocation}
+
+// createLocationsHierarchy adds the columns of the hierarchy to the locations. The names only have to be
+// unique among siblings, since a province and its capital usually share it
+func createLocationsHierarchy(db *sqlx.DB) error {
+	schema := fmt.Sprintf(`
+		ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS parent_id INTEGER REFERENCES %[1]s(id);
+		ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS kind VARCHAR(16) NOT NULL DEFAULT '%[2]s';
+		ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS code VARCHAR(32);
+		ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION;
+		ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION;
+
+		ALTER TABLE %[1]s DROP CONSTRAINT IF EXISTS %[1]s_name_key;
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_locations_parent_name ON %[1]s (COALESCE(parent_id, -1), name);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_locations_code ON %[1]s (code);
+		CREATE INDEX IF NOT EXISTS idx_locations_parent ON %[1]s (parent_id);
+		CREATE INDEX IF NOT EXISTS idx_locations_coordinates ON %[1]s (latitude, longitude);
+	`, locationsTable, model.CountryLocation)
+
+	if _, err := db.Exec(schema); err != nil {
+		return err
+	}
+
+	// the predefined countries were seeded without codes, the dataset finds them by these
+	query := fmt.Sprintf(`UPDATE %s SET code = $2 WHERE id = $1 AND code IS NULL`, locationsTable)
+	for id, code := range register_options.GetAllLocationCodes() {
+		if _, err := db.Exec(query, id, code); err != nil {
+			return fmt.Errorf("error setting the code of location %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// importLocations adds the locations of the dataset that are missing and updates the place of the existing ones,
+// matching them by their code. The names and translations of the existing ones are kept, since an admin may have edited them
+func importLocations(db *sqlx.DB, dataset []byte) error {
+	var countries []locationRecord
+	if err := json.Unmarshal(dataset, &countries); err != nil {
+		return fmt.Errorf("error parsing the locations dataset: %w", err)
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := importLocationLevel(tx, countries, nil, 0); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing the locations: %w", err)
+	}
+	return nil
+}
+
+func importLocationLevel(tx *sqlx.Tx, locations []locationRecord, parentId *int, depth int) error {
+	if depth >= len(locationKinds) {
+		return fmt.Errorf("the locations dataset is deeper than %d levels", len(locationKinds))
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (name, position, parent_id, kind, code, latitude, longitude)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (code) DO UPDATE
+		SET parent_id = EXCLUDED.parent_id, kind = EXCLUDED.kind, latitude = EXCLUDED.latitude, longitude = EXCLUDED.longitude
+		RETURNING id
+	`, locationsTable)
+
+	for position, location := range locations {
+		var id int
+		err := tx.Get(&id, query, location.Name, position, parentId, locationKinds[depth], location.Code, location.Latitude, location.Longitude)
+		if err != nil {
+			return fmt.Errorf("error importing location %s: %w", location.Code, err)
+		}
+		if err := seedTranslations(tx, locationTranslationsTable, map[int]map[string]string{id: location.Translations}); err != nil {
+			return fmt.Errorf("error importing location %s: %w", location.Code, err)
+		}
+
+		if err := importLocationLevel(tx, location.Children, &id, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
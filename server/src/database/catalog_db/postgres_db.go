@@ -0,0 +1,270 @@
+package catalog_db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"users-service/src/database"
+	"users-service/src/database/register_options"
+	"users-service/src/model"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	interestsTable          = "interests"
+	locationsTable          = "locations"
+	interestCategoriesTable = "interest_categories"
+)
+
+type CatalogPostgresDB struct {
+	db *sqlx.DB
+}
+
+// CreateCatalogPostgresDB creates the catalog tables and seeds them with the predefined entries,
+// it must run before the users and registry tables are created since they reference the catalogs
+func CreateCatalogPostgresDB(db *sqlx.DB, test bool) (*CatalogPostgresDB, error) {
+	if test {
+		dropTables := fmt.Sprintf(`
+			DROP TABLE IF EXISTS %s CASCADE;
+			DROP TABLE IF EXISTS %s CASCADE;
+			DROP TABLE IF EXISTS %s CASCADE;
+			DROP TABLE IF EXISTS %s CASCADE;
+			DROP TABLE IF EXISTS %s CASCADE;
+			DROP TABLE IF EXISTS %s CASCADE;
+			`, interestTranslationsTable, locationTranslationsTable, interestCategoryTranslationsTable,
+			interestsTable, locationsTable, interestCategoriesTable)
+
+		if _, err := db.Exec(dropTables); err != nil {
+			return nil, fmt.Errorf("failed to drop tables: %w", err)
+		}
+	}
+
+	for _, table := range []string{interestsTable, locationsTable, interestCategoriesTable} {
+		schema := fmt.Sprintf(`
+			CREATE TABLE IF NOT EXISTS %s (
+				id SERIAL PRIMARY KEY,
+				name VARCHAR(255) NOT NULL UNIQUE,
+				position INT NOT NULL DEFAULT 0,
+				deprecated BOOLEAN NOT NULL DEFAULT FALSE,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+			);
+			`, table)
+
+		if _, err := db.Exec(schema); err != nil {
+			return nil, fmt.Errorf("failed to create table %s: %w", table, err)
+		}
+	}
+
+	if err := seedCatalog(db, interestsTable, register_options.GetAllInterestsAndIds()); err != nil {
+		return nil, fmt.Errorf("failed to seed interests: %w", err)
+	}
+	if err := seedCatalog(db, locationsTable, register_options.GetAllLocationsAndIds()); err != nil {
+		return nil, fmt.Errorf("failed to seed locations: %w", err)
+	}
+	if err := seedCatalog(db, interestCategoriesTable, register_options.GetAllInterestCategoriesAndIds()); err != nil {
+		return nil, fmt.Errorf("failed to seed interest categories: %w", err)
+	}
+	if err := createInterestsMetadata(db); err != nil {
+		return nil, fmt.Errorf("failed to add the metadata of the interests: %w", err)
+	}
+	if err := createLocationsHierarchy(db); err != nil {
+		return nil, fmt.Errorf("failed to create the locations hierarchy: %w", err)
+	}
+	if err := createTranslationsTables(db); err != nil {
+		return nil, err
+	}
+	if err := seedTranslations(db, interestTranslationsTable, register_options.GetAllInterestTranslations()); err != nil {
+		return nil, fmt.Errorf("failed to seed the translations of the interests: %w", err)
+	}
+	if err := seedTranslations(db, interestCategoryTranslationsTable, register_options.GetAllInterestCategoryTranslations()); err != nil {
+		return nil, fmt.Errorf("failed to seed the translations of the interest categories: %w", err)
+	}
+	if err := importLocations(db, bundledLocations); err != nil {
+		return nil, fmt.Errorf("failed to import the bundled locations: %w", err)
+	}
+
+	return &CatalogPostgresDB{db}, nil
+}
+
+// seedCatalog inserts the predefined entries keeping their ids, so the ids the clients already use stay valid.
+// Entries that already exist are left as they are, they may have been renamed or deprecated by an admin
+func seedCatalog(db *sqlx.DB, table string, entries map[int]string) error {
+	ids := make([]int, 0, len(entries))
+	for id := range entries {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	insert := fmt.Sprintf(`INSERT INTO %s (id, name, position) VALUES ($1, $2, $1) ON CONFLICT DO NOTHING`, table)
+	for _, id := range ids {
+		if _, err := db.Exec(insert, id, entries[id]); err != nil {
+			return fmt.Errorf("error inserting %s: %w", entries[id], err)
+		}
+	}
+
+	// the ids were given explicitly, so the sequence has to be moved past them
+	query := fmt.Sprintf(`SELECT setval(pg_get_serial_sequence('%[1]s', 'id'), GREATEST((SELECT MAX(id) FROM %[1]s), 1))`, table)
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("error updating the id sequence: %w", err)
+	}
+	return nil
+}
+
+// catalogColumns returns the columns of the entries of the catalog, the locations have the ones of the hierarchy
+// and the interests and their categories the ones of their metadata
+func catalogColumns(catalog model.Catalog) string {
+	switch catalog {
+	case model.LocationsCatalog:
+		return "id, name, position, deprecated, parent_id, kind, latitude, longitude"
+	case model.InterestsCatalog:
+		return "id, name, position, deprecated, category_id, icon, description"
+	case model.InterestCategoriesCatalog:
+		return "id, name, position, deprecated, icon, description"
+	default:
+		return "id, name, position, deprecated"
+	}
+}
+
+// editableValues returns the columns of the catalog an admin can set and their values in the entry
+func editableValues(catalog model.Catalog, entry model.CatalogEntry) ([]string, []interface{}) {
+	columns, values := []string{"name"}, []interface{}{entry.Name}
+	if catalog.HasMetadata() {
+		columns = append(columns, "icon", "description")
+		values = append(values, entry.Icon, entry.Description)
+	}
+	if catalog == model.InterestsCatalog {
+		columns = append(columns, "category_id")
+		values = append(values, entry.CategoryId)
+	}
+	return columns, values
+}
+
+func catalogTable(catalog model.Catalog) (string, error) {
+	switch catalog {
+	case model.InterestsCatalog:
+		return interestsTable, nil
+	case model.LocationsCatalog:
+		return locationsTable, nil
+	case model.InterestCategoriesCatalog:
+		return interestCategoriesTable, nil
+	default:
+		return "", fmt.Errorf("unknown catalog: %s", catalog)
+	}
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func (postDB *CatalogPostgresDB) GetCatalogEntries(catalog model.Catalog) ([]model.CatalogEntry, error) {
+	table, err := catalogTable(catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []model.CatalogEntry{}
+	query := fmt.Sprintf(`SELECT %s FROM %s ORDER BY position, id`, catalogColumns(catalog), table)
+	if err := postDB.db.Select(&entries, query); err != nil {
+		return nil, fmt.Errorf("error getting %s: %w", catalog, err)
+	}
+	if err := postDB.attachTranslations(catalog, entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (postDB *CatalogPostgresDB) AddCatalogEntry(catalog model.Catalog, entry model.CatalogEntry) (model.CatalogEntry, error) {
+	table, err := catalogTable(catalog)
+	if err != nil {
+		return model.CatalogEntry{}, err
+	}
+
+	// the locations added by hand are countries, they go after the other top level ones
+	siblings := ""
+	if catalog == model.LocationsCatalog {
+		siblings = "WHERE parent_id IS NULL"
+	}
+
+	columns, values := editableValues(catalog, entry)
+	placeholders := make([]string, len(values))
+	for i := range values {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+
+	var added model.CatalogEntry
+	query := fmt.Sprintf(`
+		INSERT INTO %[1]s (%[2]s, position)
+		VALUES (%[3]s, (SELECT COALESCE(MAX(position), -1) + 1 FROM %[1]s %[4]s))
+		RETURNING %[5]s
+	`, table, strings.Join(columns, ", "), strings.Join(placeholders, ", "), siblings, catalogColumns(catalog))
+
+	if err := postDB.db.Get(&added, query, values...); err != nil {
+		if isUniqueViolation(err) {
+			return model.CatalogEntry{}, database.ErrKeyAlreadyExists
+		}
+		return model.CatalogEntry{}, fmt.Errorf("error adding entry to %s: %w", catalog, err)
+	}
+	return added, nil
+}
+
+func (postDB *CatalogPostgresDB) UpdateCatalogEntry(catalog model.Catalog, entry model.CatalogEntry) (model.CatalogEntry, error) {
+	table, err := catalogTable(catalog)
+	if err != nil {
+		return model.CatalogEntry{}, err
+	}
+
+	columns, values := editableValues(catalog, entry)
+	assignments := make([]string, len(columns))
+	for i, column := range columns {
+		assignments[i] = fmt.Sprintf("%s = $%d", column, i+3)
+	}
+
+	var updated model.CatalogEntry
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET deprecated = $2, %s
+		WHERE id = $1
+		RETURNING %s
+	`, table, strings.Join(assignments, ", "), catalogColumns(catalog))
+
+	args := append([]interface{}{entry.Id, entry.Deprecated}, values...)
+	if err := postDB.db.Get(&updated, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.CatalogEntry{}, database.ErrKeyNotFound
+		}
+		if isUniqueViolation(err) {
+			return model.CatalogEntry{}, database.ErrKeyAlreadyExists
+		}
+		return model.CatalogEntry{}, fmt.Errorf("error updating entry of %s: %w", catalog, err)
+	}
+
+	entries := []model.CatalogEntry{updated}
+	if err := postDB.attachTranslations(catalog, entries); err != nil {
+		return model.CatalogEntry{}, err
+	}
+	return entries[0], nil
+}
+
+func (postDB *CatalogPostgresDB) ReorderCatalog(catalog model.Catalog, ids []int) error {
+	table, err := catalogTable(catalog)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE %s c
+		SET position = o.position - 1
+		FROM unnest($1::int[]) WITH ORDINALITY AS o(id, position)
+		WHERE c.id = o.id
+	`, table)
+
+	if _, err := postDB.db.Exec(query, pq.Array(ids)); err != nil {
+		return fmt.Errorf("error reordering %s: %w", catalog, err)
+	}
+	return nil
+}
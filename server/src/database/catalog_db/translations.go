@@ -0,0 +1,151 @@
+package catalog_db
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"users-service/src/database"
+	"users-service/src/model"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	interestTranslationsTable         = "interest_translations"
+	locationTranslationsTable         = "location_translations"
+	interestCategoryTranslationsTable = "interest_category_translations"
+)
+
+// catalogTranslationsTable returns the table with the names of the entries of the catalog by language
+func catalogTranslationsTable(catalog model.Catalog) (string, error) {
+	switch catalog {
+	case model.InterestsCatalog:
+		return interestTranslationsTable, nil
+	case model.LocationsCatalog:
+		return locationTranslationsTable, nil
+	case model.InterestCategoriesCatalog:
+		return interestCategoryTranslationsTable, nil
+	default:
+		return "", fmt.Errorf("unknown catalog: %s", catalog)
+	}
+}
+
+// createTranslationsTables creates a table of translations for each catalog, they go away along with their entries
+func createTranslationsTables(db *sqlx.DB) error {
+	for _, catalog := range []model.Catalog{model.InterestsCatalog, model.LocationsCatalog, model.InterestCategoriesCatalog} {
+		entries, _ := catalogTable(catalog)
+		translations, _ := catalogTranslationsTable(catalog)
+
+		schema := fmt.Sprintf(`
+			CREATE TABLE IF NOT EXISTS %s (
+				entry_id INTEGER NOT NULL REFERENCES %s(id) ON DELETE CASCADE,
+				language VARCHAR(8) NOT NULL,
+				name VARCHAR(255) NOT NULL,
+				PRIMARY KEY (entry_id, language)
+			);
+			`, translations, entries)
+
+		if _, err := db.Exec(schema); err != nil {
+			return fmt.Errorf("failed to create table %s: %w", translations, err)
+		}
+	}
+	return nil
+}
+
+// seedTranslations inserts the translations of the predefined entries. The ones that already exist are
+// left as they are, they may have been edited by an admin
+func seedTranslations(db sqlx.Execer, table string, translations map[int]map[string]string) error {
+	ids := make([]int, 0, len(translations))
+	for id := range translations {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	insert := fmt.Sprintf(`INSERT INTO %s (entry_id, language, name) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`, table)
+	for _, id := range ids {
+		for language, name := range translations[id] {
+			if _, err := db.Exec(insert, id, language, name); err != nil {
+				return fmt.Errorf("error inserting the %s translation of %d: %w", language, id, err)
+			}
+		}
+	}
+	return nil
+}
+
+type entryTranslation struct {
+	EntryId  int    `db:"entry_id"`
+	Language string `db:"language"`
+	Name     string `db:"name"`
+}
+
+// attachTranslations fills the translations of the entries with a single query
+func (postDB *CatalogPostgresDB) attachTranslations(catalog model.Catalog, entries []model.CatalogEntry) error {
+	table, err := catalogTranslationsTable(catalog)
+	if err != nil {
+		return err
+	}
+
+	translations := []entryTranslation{}
+	query := fmt.Sprintf(`SELECT entry_id, language, name FROM %s`, table)
+	if err := postDB.db.Select(&translations, query); err != nil {
+		return fmt.Errorf("error getting the translations of %s: %w", catalog, err)
+	}
+
+	byEntry := make(map[int]map[string]string)
+	for _, translation := range translations {
+		if byEntry[translation.EntryId] == nil {
+			byEntry[translation.EntryId] = make(map[string]string)
+		}
+		byEntry[translation.EntryId][translation.Language] = translation.Name
+	}
+
+	for i := range entries {
+		entries[i].Translations = byEntry[entries[i].Id]
+	}
+	return nil
+}
+
+func (postDB *CatalogPostgresDB) SetCatalogEntryTranslation(catalog model.Catalog, id int, language string, name string) error {
+	table, err := catalogTranslationsTable(catalog)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (entry_id, language, name)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (entry_id, language) DO UPDATE SET name = EXCLUDED.name
+	`, table)
+
+	if _, err := postDB.db.Exec(query, id, language, name); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return database.ErrKeyNotFound
+		}
+		return fmt.Errorf("error setting the %s translation of entry %d of %s: %w", language, id, catalog, err)
+	}
+	return nil
+}
+
+func (postDB *CatalogPostgresDB) DeleteCatalogEntryTranslation(catalog model.Catalog, id int, language string) error {
+	table, err := catalogTranslationsTable(catalog)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE entry_id = $1 AND language = $2`, table)
+	result, err := postDB.db.Exec(query, id, language)
+	if err != nil {
+		return fmt.Errorf("error deleting the %s translation of entry %d of %s: %w", language, id, catalog, err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting the %s translation of entry %d of %s: %w", language, id, catalog, err)
+	}
+	if deleted == 0 {
+		return database.ErrKeyNotFound
+	}
+	return nil
+}
@@ -6,4 +6,5 @@ import "errors"
 var (
 	ErrKeyNotFound = errors.New("key not found")
 	ErrKeyAlreadyExists = errors.New("key already exists")
+	ErrVersionMismatch = errors.New("version mismatch")
 )
\ No newline at end of file
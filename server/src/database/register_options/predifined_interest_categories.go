@@ -0,0 +1,63 @@
+package register_options
+
+// CatalogMetadata is the icon, description and category a predefined entry is seeded with
+type CatalogMetadata struct {
+	Icon        string
+	Description string
+	CategoryId  *int
+}
+
+// Predefined interest categories, the interest categories catalog is seeded with them keeping their ids
+var predefinedInterestCategories = map[int]string{
+	0: "technology",
+	1: "entertainment",
+	2: "learning",
+	3: "lifestyle",
+}
+
+// GetAllInterestCategoriesAndIds returns all predefined interest categories
+func GetAllInterestCategoriesAndIds() map[int]string {
+	return predefinedInterestCategories
+}
+
+// translations of the predefined interest categories to the languages other than english
+var predefinedInterestCategoryTranslations = map[int]map[string]string{
+	0: {"es": "tecnología"},
+	1: {"es": "entretenimiento"},
+	2: {"es": "aprendizaje"},
+	3: {"es": "estilo de vida"},
+}
+
+// GetAllInterestCategoryTranslations returns the translations of the predefined interest categories by their ids
+func GetAllInterestCategoryTranslations() map[int]map[string]string {
+	return predefinedInterestCategoryTranslations
+}
+
+var predefinedInterestCategoryMetadata = map[int]CatalogMetadata{
+	0: {Icon: "💻", Description: "Software, gadgets and science"},
+	1: {Icon: "🎭", Description: "Movies, series, music and games"},
+	2: {Icon: "🎓", Description: "Books, languages and courses"},
+	3: {Icon: "🌍", Description: "Travel, food and everyday life"},
+}
+
+// GetInterestCategoryMetadata returns the metadata of the predefined interest categories by their ids
+func GetInterestCategoryMetadata() map[int]CatalogMetadata {
+	return predefinedInterestCategoryMetadata
+}
+
+func category(id int) *int {
+	return &id
+}
+
+var predefinedInterestMetadata = map[int]CatalogMetadata{
+	0: {Icon: "💻", Description: "Writing software, from scripts to whole systems", CategoryId: category(0)},
+	1: {Icon: "🎬", Description: "Watching and talking about films", CategoryId: category(1)},
+	2: {Icon: "📚", Description: "Books of every genre", CategoryId: category(2)},
+	3: {Icon: "✈️", Description: "Getting to know new places", CategoryId: category(3)},
+	4: {Icon: "🍳", Description: "Trying and sharing recipes", CategoryId: category(3)},
+}
+
+// GetInterestMetadata returns the metadata of the predefined interests by their ids
+func GetInterestMetadata() map[int]CatalogMetadata {
+	return predefinedInterestMetadata
+}
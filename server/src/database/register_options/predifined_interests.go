@@ -1,6 +1,6 @@
 package register_options
 
-// Predefined interests
+// Predefined interests, the interests catalog is seeded with them keeping their ids
 var predefinedInterests = map[int]string{
 	0: "programming",
 	1: "movies",
@@ -14,11 +14,16 @@ func GetAllInterestsAndIds() map[int]string {
     return predefinedInterests
 }
 
-// GetInterestName returns the name of an interest given its ID or "" if the ID is invalid
-func GetInterestName(id int) string {
-	name, exists := predefinedInterests[id]
-	if !exists {
-		return ""
-	}
-	return name
+// translations of the predefined interests to the languages other than english, the interests catalog is seeded with them too
+var predefinedInterestTranslations = map[int]map[string]string{
+	0: {"es": "programación"},
+	1: {"es": "películas"},
+	2: {"es": "lectura"},
+	3: {"es": "viajes"},
+	4: {"es": "cocina"},
+}
+
+// GetAllInterestTranslations returns the translations of the predefined interests by their ids
+func GetAllInterestTranslations() map[int]map[string]string {
+    return predefinedInterestTranslations
 }
@@ -1,6 +1,6 @@
 package register_options
 
-// predefined Locations
+// predefined Locations, the locations catalog is seeded with them keeping their ids
 var predefinedLocations = map[int]string{
 	0: "Argentina",
 	1: "Brasil",
@@ -9,16 +9,21 @@ var predefinedLocations = map[int]string{
 	4: "Uruguay",
 }
 
-// GetAllInterests returns all predefined interests
+// GetAllLocationsAndIds returns all predefined locations
 func GetAllLocationsAndIds() map[int]string {
     return predefinedLocations
 }
 
-// GetLocationName returns the name of an interest given its ID
-func GetLocationName(id int) string {
-	name, exists := predefinedLocations[id]
-	if !exists {
-		return ""
-	}
-	return name
+// codes of the predefined locations, the bundled locations dataset uses them to place its provinces and cities
+var predefinedLocationCodes = map[int]string{
+	0: "AR",
+	1: "BR",
+	2: "PY",
+	3: "CL",
+	4: "UY",
+}
+
+// GetAllLocationCodes returns the codes of the predefined locations by their ids
+func GetAllLocationCodes() map[int]string {
+    return predefinedLocationCodes
 }
@@ -10,6 +10,14 @@ type RegistryDatabase interface {
 	// CreateRegistryEntry creates a new registry entry with the given email
 	CreateRegistryEntry(email string, identityProvider *string) (uuid.UUID, error)
 
+	// SetRegistryEntryIdentity sets the identity provider account the user registers with,
+	// it is bound to the user once the registry is completed
+	SetRegistryEntryIdentity(id uuid.UUID, provider string, subject string) error
+
+	// ClaimRegistryIdentity sets the subject of the completed registry of the email if it was made with the provider
+	// before the subjects were stored, and tells if there was one. It can only be claimed once
+	ClaimRegistryIdentity(email string, provider string, subject string) (bool, error)
+
 	// GetRegistryEntry returns the registry entry with the given id
 	GetRegistryEntry(id uuid.UUID) (model.RegistryEntry, error)
 
@@ -19,8 +27,8 @@ type RegistryDatabase interface {
 	// AddPersonalInfoToRegistryEntry adds personal info to the registry entry with the given id
 	AddPersonalInfoToRegistryEntry(id uuid.UUID, personalInfo model.UserPersonalInfoRecord) error
 
-	// AddInterestsToRegistryEntry adds interests to the registry entry with the given id
-	AddInterestsToRegistryEntry(id uuid.UUID, interests []string) error
+	// AddInterestsToRegistryEntry adds the interests of the catalog with the given ids to the registry entry
+	AddInterestsToRegistryEntry(id uuid.UUID, interestIds []int) error
 
 	// SetEmailVerificationPin sets the email verification pin of the registry entry with the given id
 	SetEmailVerificationPin(id uuid.UUID, code string) error
@@ -38,25 +38,74 @@ func CreateRegistryPostgresDB(db *sqlx.DB, test bool) (*RegistryPostgresDB, erro
         last_name VARCHAR(%d) DEFAULT '',
         username VARCHAR(%d) DEFAULT '',
         password TEXT DEFAULT '',
-        location VARCHAR(255) DEFAULT '',
+        location_id INTEGER REFERENCES locations(id),
 		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
 		deleted_at TIMESTAMPTZ
     );
 
+    ALTER TABLE registry_entries ADD COLUMN IF NOT EXISTS birth_date DATE;
+    ALTER TABLE registry_entries ADD COLUMN IF NOT EXISTS identity_subject VARCHAR(255) NOT NULL DEFAULT '';
+
     CREATE TABLE IF NOT EXISTS registry_interests (
         registry_id UUID,
-        interest VARCHAR(255),
-        PRIMARY KEY (registry_id, interest),
+        interest_id INTEGER NOT NULL REFERENCES interests(id),
+        PRIMARY KEY (registry_id, interest_id),
         FOREIGN KEY (registry_id) REFERENCES registry_entries(id) ON DELETE CASCADE
     );`, constants.MaxEmailLength, constants.MaxFirstNameLength, constants.MaxLastNameLength, constants.MaxUsernameLength)
 
 	if _, err := db.Exec(schema); err != nil {
 		return nil, fmt.Errorf("failed to create tables: %w", err)
 	}
+	if err := migrateToCatalogIds(db); err != nil {
+		return nil, fmt.Errorf("failed to migrate registry entries to catalog ids: %w", err)
+	}
 
 	return &RegistryPostgresDB{db}, nil
 }
 
+// migrateToCatalogIds replaces the location and interest names of the registrations that were stored before
+// the catalogs existed with their ids. The locations were countries, they are matched by their name, code or
+// any of their translations. Names that are not in the catalogs are dropped, and the step of those registrations
+// goes back to the one where they are chosen
+func migrateToCatalogIds(db *sqlx.DB) error {
+	migration := `
+	DO $$
+	BEGIN
+		IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'registry_entries' AND column_name = 'location') THEN
+			ALTER TABLE registry_entries ADD COLUMN IF NOT EXISTS location_id INTEGER REFERENCES locations(id);
+			UPDATE registry_entries r SET location_id = (
+				SELECT l.id
+				FROM locations l
+				WHERE l.parent_id IS NULL
+				AND (LOWER(l.name) = LOWER(TRIM(r.location))
+					OR LOWER(l.code) = LOWER(TRIM(r.location))
+					OR EXISTS (
+						SELECT 1
+						FROM location_translations t
+						WHERE t.entry_id = l.id AND LOWER(t.name) = LOWER(TRIM(r.location))
+					))
+				ORDER BY (LOWER(l.name) = LOWER(TRIM(r.location))) DESC, l.id
+				LIMIT 1
+			)
+			WHERE r.location_id IS NULL AND r.location IS NOT NULL;
+			ALTER TABLE registry_entries DROP COLUMN location;
+		END IF;
+
+		IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'registry_interests' AND column_name = 'interest') THEN
+			ALTER TABLE registry_interests ADD COLUMN interest_id INTEGER REFERENCES interests(id);
+			UPDATE registry_interests r SET interest_id = i.id FROM interests i WHERE i.name = r.interest;
+			DELETE FROM registry_interests WHERE interest_id IS NULL;
+			ALTER TABLE registry_interests DROP COLUMN interest;
+			ALTER TABLE registry_interests ALTER COLUMN interest_id SET NOT NULL;
+			ALTER TABLE registry_interests ADD PRIMARY KEY (registry_id, interest_id);
+		END IF;
+	END $$;
+	`
+
+	_, err := db.Exec(migration)
+	return err
+}
+
 func (db *RegistryPostgresDB) CreateRegistryEntry(email string, identityProvider *string) (uuid.UUID, error) {
 	var id uuid.UUID
     if identityProvider == nil {
@@ -70,6 +119,30 @@ func (db *RegistryPostgresDB) CreateRegistryEntry(email string, identityProvider
 	return id, nil
 }
 
+func (db *RegistryPostgresDB) SetRegistryEntryIdentity(id uuid.UUID, provider string, subject string) error {
+	_, err := db.db.Exec("UPDATE registry_entries SET identity_provider = $2, identity_subject = $3 WHERE id = $1", id, provider, subject)
+	if err != nil {
+		return fmt.Errorf("failed to set registry entry identity: %w", err)
+	}
+	return nil
+}
+
+func (db *RegistryPostgresDB) ClaimRegistryIdentity(email string, provider string, subject string) (bool, error) {
+	result, err := db.db.Exec(`
+		UPDATE registry_entries
+		SET identity_subject = $3
+		WHERE email = $1 AND identity_provider = $2 AND identity_subject = '' AND deleted_at IS NOT NULL`,
+		email, provider, subject)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim registry entry identity: %w", err)
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim registry entry identity: %w", err)
+	}
+	return claimed > 0, nil
+}
+
 func (db *RegistryPostgresDB) CheckIfRegistryEntryExistsByEmail(email string) (bool, error) {
 	var exists bool
 	err := db.db.QueryRow("SELECT EXISTS(SELECT 1 FROM registry_entries WHERE email = $1 AND deleted_at IS NULL)", email).Scan(&exists)
@@ -91,15 +164,16 @@ func (db *RegistryPostgresDB) CheckIfRegistryEntryExists(id uuid.UUID) (bool, er
 func (db *RegistryPostgresDB) GetRegistryEntry(id uuid.UUID) (model.RegistryEntry, error) {
 	var entry model.RegistryEntry
 	var personalInfo model.UserPersonalInfoRecord
+	var locationId sql.NullInt32
 
 	err := db.db.QueryRow(`
-        SELECT id, email, email_verified, first_name, last_name, username, password, location, identity_provider
+        SELECT id, email, email_verified, first_name, last_name, username, password, location_id, birth_date, identity_provider, identity_subject
         FROM registry_entries 
         WHERE id = $1`, id).Scan(
 		&entry.Id, &entry.Email, &entry.EmailVerified,
 		&personalInfo.FirstName, &personalInfo.LastName,
 		&personalInfo.UserName, &personalInfo.Password,
-		&personalInfo.Location, &entry.IdentityProvider)
+		&locationId, &personalInfo.BirthDate, &entry.IdentityProvider, &entry.IdentitySubject)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -108,13 +182,16 @@ func (db *RegistryPostgresDB) GetRegistryEntry(id uuid.UUID) (model.RegistryEntr
 		return model.RegistryEntry{}, fmt.Errorf("failed to get registry entry: %w", err)
 	}
 
+	// the location is only set once the personal info is added
+	personalInfo.LocationId = int(locationId.Int32)
 	entry.PersonalInfo = personalInfo
+	entry.HasLocation = locationId.Valid
 
 	interests, err := db.getInterests(id)
 	if err != nil {
 		return model.RegistryEntry{}, fmt.Errorf("failed to get interests: %w", err)
 	}
-	entry.Interests = interests
+	entry.InterestIds = interests
 
 	return entry, nil
 }
@@ -123,27 +200,31 @@ func (db *RegistryPostgresDB) GetRegistryEntry(id uuid.UUID) (model.RegistryEntr
 func (db *RegistryPostgresDB) GetRegistryEntryByEmail(email string) (model.RegistryEntry, error) {
 	var entry model.RegistryEntry
 	var personalInfo model.UserPersonalInfoRecord
+	var locationId sql.NullInt32
 
 	err := db.db.QueryRow(`
-        SELECT id, email, email_verified, first_name, last_name, username, password, location 
+        SELECT id, email, email_verified, first_name, last_name, username, password, location_id, birth_date
         FROM registry_entries 
         WHERE email = $1`, email).Scan(
 		&entry.Id, &entry.Email, &entry.EmailVerified,
 		&personalInfo.FirstName, &personalInfo.LastName,
 		&personalInfo.UserName, &personalInfo.Password,
-		&personalInfo.Location)
+		&locationId, &personalInfo.BirthDate)
 
 	if err != nil {
 		return model.RegistryEntry{}, fmt.Errorf("failed to get registry entry: %w", err)
 	}
 
+	// the location is only set once the personal info is added
+	personalInfo.LocationId = int(locationId.Int32)
 	entry.PersonalInfo = personalInfo
+	entry.HasLocation = locationId.Valid
 
 	interests, err := db.getInterests(entry.Id)
 	if err != nil {
 		return model.RegistryEntry{}, fmt.Errorf("failed to get interests: %w", err)
 	}
-	entry.Interests = interests
+	entry.InterestIds = interests
 
 	return entry, nil
 }
@@ -151,17 +232,17 @@ func (db *RegistryPostgresDB) GetRegistryEntryByEmail(email string) (model.Regis
 func (db *RegistryPostgresDB) AddPersonalInfoToRegistryEntry(id uuid.UUID, personalInfo model.UserPersonalInfoRecord) error {
 	_, err := db.db.Exec(`
         UPDATE registry_entries 
-        SET first_name = $2, last_name = $3, username = $4, password = $5, location = $6
+        SET first_name = $2, last_name = $3, username = $4, password = $5, location_id = $6, birth_date = $7
         WHERE id = $1`,
 		id, personalInfo.FirstName, personalInfo.LastName,
-		personalInfo.UserName, personalInfo.Password, personalInfo.Location)
+		personalInfo.UserName, personalInfo.Password, personalInfo.LocationId, personalInfo.BirthDate)
 	if err != nil {
 		return fmt.Errorf("failed to add personal info: %w", err)
 	}
 	return nil
 }
 
-func (db *RegistryPostgresDB) AddInterestsToRegistryEntry(id uuid.UUID, interests []string) error {
+func (db *RegistryPostgresDB) AddInterestsToRegistryEntry(id uuid.UUID, interestIds []int) error {
     var exists bool
     err := db.db.QueryRow("SELECT EXISTS(SELECT 1 FROM registry_interests WHERE registry_id = $1)", id).Scan(&exists)
     if err != nil {
@@ -172,10 +253,10 @@ func (db *RegistryPostgresDB) AddInterestsToRegistryEntry(id uuid.UUID, interest
         return fmt.Errorf("interests already exist for registry entry with id %s", id)
     }
 
-    for _, interest := range interests {
-        _, err = db.db.Exec("INSERT INTO registry_interests (registry_id, interest) VALUES ($1, $2)", id, interest)
+    for _, interestId := range interestIds {
+        _, err = db.db.Exec("INSERT INTO registry_interests (registry_id, interest_id) VALUES ($1, $2)", id, interestId)
         if err != nil {
-            return fmt.Errorf("failed to insert interest '%s': %w", interest, err)
+            return fmt.Errorf("failed to insert interest %d: %w", interestId, err)
         }
     }
 
@@ -219,16 +300,16 @@ func (db *RegistryPostgresDB) DeleteRegistryEntry(id uuid.UUID) error {
 	return nil
 }
 
-func (db *RegistryPostgresDB) getInterests(id uuid.UUID) ([]string, error) {
-	rows, err := db.db.Query("SELECT interest FROM registry_interests WHERE registry_id = $1", id)
+func (db *RegistryPostgresDB) getInterests(id uuid.UUID) ([]int, error) {
+	rows, err := db.db.Query("SELECT interest_id FROM registry_interests WHERE registry_id = $1", id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get interests: %w", err)
 	}
 	defer rows.Close()
 
-	var interests []string
+	var interests []int
 	for rows.Next() {
-		var interest string
+		var interest int
 		if err := rows.Scan(&interest); err != nil {
 			return nil, fmt.Errorf("failed to scan interest: %w", err)
 		}
@@ -0,0 +1,34 @@
+package users_db
+
+import (
+	"fmt"
+	"strings"
+	"users-service/src/model"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+func (postDB *UsersPostgresDB) GetUsersBatch(viewerId uuid.UUID, ids []uuid.UUID, usernames []string) ([]model.BatchUser, error) {
+	lowered := make([]string, len(usernames))
+	for i, username := range usernames {
+		lowered[i] = strings.ToLower(username)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT u.id, u.username, u.first_name, u.last_name, COALESCE(u.picture_path, '') AS picture_path,
+			(f.follower_id IS NOT NULL) AS follows,
+			(fb.follower_id IS NOT NULL) AS followed_by
+		FROM %[1]s u
+		LEFT JOIN %[2]s f ON f.follower_id = $1 AND f.following_id = u.id
+		LEFT JOIN %[2]s fb ON fb.follower_id = u.id AND fb.following_id = $1
+		WHERE (u.id = ANY($2) OR LOWER(u.username) = ANY($3))
+		AND u.blocked IS NOT TRUE
+	`, usersTable, followersTable)
+
+	users := []model.BatchUser{}
+	if err := postDB.db.Select(&users, query, viewerId, pq.Array(ids), pq.Array(lowered)); err != nil {
+		return nil, fmt.Errorf("error getting batch of users: %w", err)
+	}
+	return users, nil
+}
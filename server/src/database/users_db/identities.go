@@ -0,0 +1,92 @@
+package users_db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"users-service/src/constants"
+	"users-service/src/database"
+	"users-service/src/model"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const userIdentitiesTable = "user_identities"
+
+// createUserIdentitiesTable creates the table with the identity provider accounts of the users,
+// each one belongs to a single user and a user has at most one of each provider
+func createUserIdentitiesTable(db *sqlx.DB) error {
+	schema := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %[1]s (
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			provider VARCHAR(64) NOT NULL,
+			subject VARCHAR(255) NOT NULL,
+			email VARCHAR(%[2]d) NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			PRIMARY KEY (provider, subject),
+			UNIQUE (user_id, provider)
+		);
+	`, userIdentitiesTable, constants.MaxEmailLength)
+
+	_, err := db.Exec(schema)
+	return err
+}
+
+func (postDB *UsersPostgresDB) GetUserIdByIdentity(provider string, subject string) (uuid.UUID, error) {
+	var userId uuid.UUID
+	query := fmt.Sprintf(`SELECT user_id FROM %s WHERE provider = $1 AND subject = $2`, userIdentitiesTable)
+	if err := postDB.db.Get(&userId, query, provider, subject); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return uuid.Nil, database.ErrKeyNotFound
+		}
+		return uuid.Nil, fmt.Errorf("error getting user by identity: %w", err)
+	}
+	return userId, nil
+}
+
+func (postDB *UsersPostgresDB) GetUserIdentities(userId uuid.UUID) ([]model.UserIdentity, error) {
+	identities := []model.UserIdentity{}
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE user_id = $1 ORDER BY created_at, provider`, userIdentitiesTable)
+	if err := postDB.db.Select(&identities, query, userId); err != nil {
+		return nil, fmt.Errorf("error getting user identities: %w", err)
+	}
+	return identities, nil
+}
+
+func (postDB *UsersPostgresDB) DeleteUserIdentity(userId uuid.UUID, provider string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE user_id = $1 AND provider = $2`, userIdentitiesTable)
+	result, err := postDB.db.Exec(query, userId, provider)
+	if err != nil {
+		return fmt.Errorf("error deleting user identity: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting user identity: %w", err)
+	}
+	if affected == 0 {
+		return database.ErrKeyNotFound
+	}
+	return nil
+}
+
+func (postDB *UsersPostgresDB) AddUserIdentity(identity model.UserIdentity) error {
+	return insertUserIdentity(postDB.db, identity)
+}
+
+// insertUserIdentity binds the identity to its user, within the transaction the user is created in if there is one
+func insertUserIdentity(e sqlx.Ext, identity model.UserIdentity) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (user_id, provider, subject, email)
+		VALUES (:user_id, :provider, :subject, :email)`, userIdentitiesTable)
+
+	if _, err := sqlx.NamedExec(e, query, identity); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return database.ErrKeyAlreadyExists
+		}
+		return fmt.Errorf("error adding user identity: %w", err)
+	}
+	return nil
+}
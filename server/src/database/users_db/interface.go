@@ -10,12 +10,17 @@ import (
 // UserDatabase interface to interact with the user's database
 // it is used by the service layer
 type UserDatabase interface {
-	// CreateUser creates a new user in the database
-	CreateUser(data model.UserRecord) (model.UserRecord, error)
+	// CreateUser creates a new user in the database, bound to the identity provider account if there is one.
+	// Both are created at once, it returns ErrKeyAlreadyExists if the account is bound to another user
+	CreateUser(data model.UserRecord, identity *model.UserIdentity) (model.UserRecord, error)
 
 	// ModifyUser updates a user in the database
 	ModifyUser(id uuid.UUID, data model.UpdateUserPrivateProfile) (model.UserRecord, error)
 
+	// PatchUser updates only the changed columns of a user and its interests if they changed. If version is not 0
+	// the user is only updated if it still has that version, otherwise it returns ErrVersionMismatch
+	PatchUser(id uuid.UUID, version int, changes model.UserProfileChanges) (model.UserRecord, error)
+
 	// GetUserById retrieves a user from the database by its ID
 	GetUserById(id uuid.UUID) (model.UserRecord, error)
 
@@ -23,62 +28,132 @@ type UserDatabase interface {
 	// it is case sensitive
 	GetUserByEmail(email string) (model.UserRecord, error)
 
+	// GetUsersBatch retrieves the compact profiles of the users with the ids or usernames, ignoring the case of
+	// the usernames, with whether the viewer follows them and they follow it. The blocked users are left out
+	GetUsersBatch(viewerId uuid.UUID, ids []uuid.UUID, usernames []string) ([]model.BatchUser, error)
+
+	// GetUserIdByIdentity returns the id of the user the identity provider account is bound to,
+	// it returns ErrKeyNotFound if it is not bound to any
+	GetUserIdByIdentity(provider string, subject string) (uuid.UUID, error)
+
+	// AddUserIdentity binds an identity provider account to a user, it returns ErrKeyAlreadyExists
+	// if the account is bound to a user or the user already has an account of that provider
+	AddUserIdentity(identity model.UserIdentity) error
+
+	// GetUserIdentities returns the identity provider accounts bound to the user, the oldest first
+	GetUserIdentities(userId uuid.UUID) ([]model.UserIdentity, error)
+
+	// DeleteUserIdentity unbinds the account of the provider from the user, it returns ErrKeyNotFound if it has none
+	DeleteUserIdentity(userId uuid.UUID, provider string) error
+
+	// GetUserByUsername retrieves a user from the database by its current username,
+	// it is case insensitive
+	GetUserByUsername(username string) (model.UserRecord, error)
+
 	// CheckIfUsernameExists checks if a username already exists in the database
 	// it is case insensitive
 	CheckIfUsernameExists(username string) (bool, error)
 
+	// CheckIfUsernameIsReserved checks if a username is held for a user other than the given one after leaving it,
+	// it is case insensitive
+	CheckIfUsernameIsReserved(username string, userId uuid.UUID) (bool, error)
+
+	// GetUsernameHolder retrieves the id of the user that left the username and still holds it,
+	// it is case insensitive
+	GetUsernameHolder(username string) (uuid.UUID, error)
+
+	// GetLastUsernameChange retrieves when the user last changed its username, nil if it never did
+	GetLastUsernameChange(userId uuid.UUID) (*time.Time, error)
+
 	// CheckIfEmailExists checks if a mail already exists in the database
 	// it is case insensitive
 	CheckIfEmailExists(email string) (bool, error)
 
 	// FollowUser associates a follower to a following user
+	// and updates the counters of both users in the same transaction
 	FollowUser(followerId uuid.UUID, followingId uuid.UUID) error
 
 	// UnfollowUser removes a follower from a following user
+	// and updates the counters of both users in the same transaction
 	UnfollowUser(followerId uuid.UUID, followingId uuid.UUID) error
 
 	// CheckIfUserFollows checks if followerID follows followingId
 	CheckIfUserFollows(followerId uuid.UUID, followingId uuid.UUID) (bool, error)
 
-	// GetAmountOfFollowers retrieves the amount of followers for a given user ID
-	GetAmountOfFollowers(userId uuid.UUID) (int, error)
-
-	// GetAmountOfFollowing retrieves the amount of following for a given user ID
-	GetAmountOfFollowing(userId uuid.UUID) (int, error)
-
-	// GetFollowers returns the followers for a given user ID and if there are more followers to retrieve
-	// it also receives a timestamp, skip and limit to paginate the results
-	GetFollowers(userId uuid.UUID, timestamp string, skip int, limit int) ([]model.UserRecord, bool, error)
+	// ReconcileFollowCounters recomputes the followers and following counters stored in the users
+	// from the followers table, returning the amount of users whose counters had drifted
+	ReconcileFollowCounters() (int, error)
 
-	// GetAmountOfFollowersInTimeRange retrieves the amount of followers for a given user ID in a time range
-	GetAmountOfFollowersInTimeRange(userId uuid.UUID, startTime, endTime time.Time) (int, error)
+	// GetFollowedUsersAmong returns the set of the given user IDs that followerId follows
+	GetFollowedUsersAmong(followerId uuid.UUID, userIds []uuid.UUID) (map[uuid.UUID]bool, error)
 
-	// GetAllUsers retrieves all the users in the database
-	// it also receives a timestamp, skip and limit to paginate the results
-	GetAllUsers(timestamp string, skip int, limit int) ([]model.UserRecord, bool, error)
+	// GetFollowersAmong returns the set of the given user IDs that follow followingId
+	GetFollowersAmong(followingId uuid.UUID, userIds []uuid.UUID) (map[uuid.UUID]bool, error)
 
-	// GetFollowing returns the users that a user is following for a given user ID
-	// and if there are more followers to retrieve.
-	// It also receives a timestamp, skip and limit to paginate the results
-	GetFollowing(userId uuid.UUID, timestamp string, skip int, limit int) ([]model.UserRecord, bool, error)
+	// GetMutualCounts returns, for each of the target IDs, how many of the users that userId follows also follow it.
+	// Targets without mutuals are not in the map
+	GetMutualCounts(userId uuid.UUID, targetIds []uuid.UUID) (map[uuid.UUID]int, error)
 
-	// GetUsersWithUsernameContaining returns the users that have a username containing the text
-	// it also receives a timestamp, skip and limit to paginate the results
-	GetUsersWithUsernameContaining(text string, timestamp string, skip int, limit int) ([]model.UserRecord, bool, error)
+	// GetMutuals returns a page of the users that userId follows and that follow targetId, the ones that followed it
+	// most recently first, and the cursor of the next page or nil if there are no more users to retrieve
+	GetMutuals(userId uuid.UUID, targetId uuid.UUID, page model.PageRequest) ([]model.UserRecord, *model.Cursor, error)
 
-	// GetAmountOfUsersWithUsernameContaining returns the amount of users that have a username containing the text
-	GetAmountOfUsersWithUsernameContaining(text string) (int, error)
+	// GetFollowers returns a page of the followers for a given user ID, the most recent ones first,
+	// and the cursor of the next page or nil if there are no more followers to retrieve
+	GetFollowers(userId uuid.UUID, page model.PageRequest) ([]model.UserRecord, *model.Cursor, error)
 
-	// GetUsersWithOnlyNameContaining returns the users that JUST have the name containing the text. 
-	// If the username also has it, it discards it
-	// it also receives a timestamp, skip and limit to paginate the results
-	GetUsersWithOnlyNameContaining(text string, timestamp string, skip int, limit int) ([]model.UserRecord, bool, error)
+	// GetAmountOfFollowersInTimeRange retrieves the amount of followers for a given user ID in a time range
+	GetAmountOfFollowersInTimeRange(userId uuid.UUID, startTime, endTime time.Time) (int, error)
 
-	// GetRecommendations returns the users that are recommended for a given user ID and if there are more users to retrieve
-	// it also receives a timestamp, skip and limit to paginate the results
-	// it calculates the recommendations based on the user's interests and location, returning first the users that share both
-	// then the users that share only one of them
-	GetRecommendations(userId uuid.UUID, timestamp string, skip int, limit int) ([]model.UserRecord, bool, error)
+	// GetAllUsers returns a page of all the users in the database, the newest ones first,
+	// and the cursor of the next page or nil if there are no more users to retrieve
+	GetAllUsers(page model.PageRequest) ([]model.UserRecord, *model.Cursor, error)
+
+	// GetFollowing returns a page of the users that a user is following for a given user ID, the most recent ones first,
+	// and the cursor of the next page or nil if there are no more users to retrieve
+	GetFollowing(userId uuid.UUID, page model.PageRequest) ([]model.UserRecord, *model.Cursor, error)
+
+	// SearchUsers returns a page of the users whose username or name match the text and that pass the filters,
+	// and the cursor of the next page or nil if there are no more users to retrieve.
+	// The users are ranked by relevance: exact username first, then username prefix, username containing the text,
+	// name containing the text, full text matches and finally the ones that are just similar to the text.
+	// If the text is empty all the users that pass the filters are returned, the newest ones first.
+	// The location filter also matches the users in the locations inside it, like the cities of a country,
+	// and the categories filter the users with any interest in them
+	SearchUsers(text string, filters model.UserSearchFilters, page model.PageRequest) ([]model.UserRecord, *model.Cursor, error)
+
+	// GetUsernameSuggestions returns up to limit users whose username starts with the prefix, case insensitive,
+	// excluding the user itself and the blocked users. The users it follows come first, then the exact match
+	// and then the most followed ones
+	GetUsernameSuggestions(userId uuid.UUID, prefix string, limit int) ([]model.UserSuggestion, error)
+
+	// GetNearbyUsers returns a page of the users whose location is at most radiusKm away from the given coordinates,
+	// the closest ones first, excluding the user itself and the blocked users. Users whose location has no
+	// coordinates are never returned
+	GetNearbyUsers(userId uuid.UUID, latitude float64, longitude float64, radiusKm float64, page model.PageRequest) ([]model.NearbyUserRecord, *model.Cursor, error)
+
+	// GetRecommendations returns a page of the users that are recommended for a given user ID
+	// and the cursor of the next page or nil if there are no more users to retrieve.
+	// The users are scored by how many of the followed users follow them, the amount of shared interests,
+	// the amount of shared interest categories in which they have no interest in common,
+	// sharing the location and their popularity. Followed and blocked users are never recommended.
+	// Dismissed users are excluded and the ones shown many times in previous listings are down-ranked.
+	// They are read from the ones precomputed by RefreshRecommendations, or computed on the fly if there are none
+	GetRecommendations(userId uuid.UUID, page model.PageRequest) ([]model.RecommendationRecord, *model.Cursor, error)
+
+	// RefreshRecommendations recomputes and stores the best limit recommendations for a given user ID
+	RefreshRecommendations(userId uuid.UUID, limit int) error
+
+	// GetUsersWithStaleRecommendations returns up to limit users whose recommendations were never refreshed
+	// or were refreshed before refreshedBefore, the ones never refreshed first
+	GetUsersWithStaleRecommendations(refreshedBefore time.Time, limit int) ([]uuid.UUID, error)
+
+	// DismissRecommendation stops recommending dismissedId to userId until expiresAt, or forever if it is nil
+	DismissRecommendation(userId uuid.UUID, dismissedId uuid.UUID, expiresAt *time.Time) error
+
+	// RecordRecommendationImpressions counts that the users were shown as recommendations to userId
+	// in the listing with the listedAt timestamp, each user is counted at most once per listing
+	RecordRecommendationImpressions(userId uuid.UUID, shownIds []uuid.UUID, listedAt time.Time) error
 
 	// BlockUser blocks a user
 	BlockUser(userId uuid.UUID, reason string) error
@@ -88,4 +163,21 @@ type UserDatabase interface {
 
 	// CheckIfUserIsBlocked checks if a user is blocked
 	CheckIfUserIsBlocked(userId uuid.UUID) (bool, error)
+
+	// CreateServiceClient registers a service client with the hash of its secret,
+	// it returns ErrKeyAlreadyExists if there is another client with that name
+	CreateServiceClient(name string, scopes []string, secretHash string) (model.ServiceClientRecord, error)
+
+	// GetServiceClient retrieves a service client, revoked or not, it returns ErrKeyNotFound if it does not exist
+	GetServiceClient(id uuid.UUID) (model.ServiceClientRecord, error)
+
+	// GetServiceClients returns every service client, the revoked ones included, the oldest ones first
+	GetServiceClients() ([]model.ServiceClientRecord, error)
+
+	// RotateServiceClientSecret replaces the secret hash of a client, keeping the previous one until previousExpiresAt.
+	// It returns ErrKeyNotFound if the client does not exist or was revoked
+	RotateServiceClientSecret(id uuid.UUID, secretHash string, previousExpiresAt time.Time) (model.ServiceClientRecord, error)
+
+	// RevokeServiceClient revokes a service client, it returns ErrKeyNotFound if it does not exist or was already revoked
+	RevokeServiceClient(id uuid.UUID) error
 }
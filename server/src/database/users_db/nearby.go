@@ -0,0 +1,82 @@
+package users_db
+
+import (
+	"fmt"
+	"users-service/src/model"
+
+	"github.com/google/uuid"
+)
+
+const (
+	earthRadiusKm       = 6371.0
+	kmPerLatitudeDegree = 111.045
+)
+
+// haversineExpression returns the distance in kilometers between the location l and the given coordinates
+func haversineExpression(latitude, longitude string) string {
+	return fmt.Sprintf(`(2 * %[3]f * ASIN(LEAST(1, SQRT(
+		POWER(SIN(RADIANS(l.latitude - %[1]s::float8) / 2), 2)
+		+ COS(RADIANS(%[1]s::float8)) * COS(RADIANS(l.latitude)) * POWER(SIN(RADIANS(l.longitude - %[2]s::float8) / 2), 2)
+	))))::float8`, latitude, longitude, earthRadiusKm)
+}
+
+// locationSubtreeQuery returns the ids of the location and every location inside it
+func locationSubtreeQuery(locationId string) string {
+	return fmt.Sprintf(`
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM %[1]s WHERE id = %[2]s
+			UNION ALL
+			SELECT l.id FROM %[1]s l JOIN subtree s ON l.parent_id = s.id
+		)
+		SELECT id FROM subtree`, locationsCatalogTable, locationId)
+}
+
+// GetNearbyUsers sorts by the distance as a negated score, so the closest users have the highest one
+// and the pages use the same cursors as the other scored lists
+func (postDB *UsersPostgresDB) GetNearbyUsers(userId uuid.UUID, latitude float64, longitude float64, radiusKm float64, page model.PageRequest) ([]model.NearbyUserRecord, *model.Cursor, error) {
+	q := queryBuilder{}
+	latitudeArg, longitudeArg, radius := q.bind(latitude), q.bind(longitude), q.bind(radiusKm)
+
+	// the latitude band lets the coordinates index discard most locations before measuring the distances
+	after := page.After()
+	query := fmt.Sprintf(`
+		SELECT *
+		FROM (
+			SELECT u.*, -nl.distance AS score
+			FROM (
+				SELECT l.id, %[1]s AS distance
+				FROM %[2]s l
+				WHERE l.latitude BETWEEN %[3]s::float8 - %[5]s::float8 / %[6]f AND %[3]s::float8 + %[5]s::float8 / %[6]f
+				AND l.longitude IS NOT NULL
+			) nl
+			JOIN %[7]s u ON u.location_id = nl.id
+			WHERE nl.distance <= %[5]s::float8
+			AND u.id <> %[8]s
+			AND u.blocked IS NOT TRUE
+			AND u.created_at < %[9]s
+		) s
+		WHERE (s.score, s.created_at, s.id) < (%[10]s, %[11]s, %[12]s)
+		ORDER BY s.score DESC, s.created_at DESC, s.id DESC
+		OFFSET %[13]s
+		LIMIT %[14]s
+	`, haversineExpression(latitudeArg, longitudeArg), locationsCatalogTable, latitudeArg, longitudeArg, radius, kmPerLatitudeDegree,
+		usersTable, q.bind(userId), q.bind(page.Timestamp),
+		q.bind(after.Score), q.bind(after.CreatedAt), q.bind(after.Id), q.bind(page.Offset()), q.bind(page.Limit+1))
+
+	var records []scoredRecord
+	if err := postDB.db.Select(&records, query, q.args...); err != nil {
+		return nil, nil, fmt.Errorf("error getting nearby users: %w", err)
+	}
+
+	records, next := paginate(records, page.Limit, scoredCursor)
+	users := scoredRecordsToUsers(records)
+	if err := postDB.attachCatalogNames(users); err != nil {
+		return nil, nil, fmt.Errorf("error getting interests for users: %w", err)
+	}
+
+	nearby := make([]model.NearbyUserRecord, len(records))
+	for i, record := range records {
+		nearby[i] = model.NearbyUserRecord{User: users[i], DistanceKm: -record.Score}
+	}
+	return nearby, next, nil
+}
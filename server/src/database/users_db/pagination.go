@@ -0,0 +1,59 @@
+package users_db
+
+import (
+	"time"
+	"users-service/src/model"
+)
+
+// followRecord is a user along with the moment the follow relation was created,
+// which is the key the followers and following lists are sorted by
+type followRecord struct {
+	model.UserRecord
+	FollowedAt time.Time `db:"followed_at"`
+}
+
+// scoredRecord is a user along with the score of the list it belongs to
+type scoredRecord struct {
+	model.UserRecord
+	Score float64 `db:"score"`
+}
+
+// paginate drops the extra row fetched to know if there are more items,
+// returning the cursor of the last row kept or nil if it was the last page
+func paginate[T any](rows []T, limit int, cursorOf func(T) model.Cursor) ([]T, *model.Cursor) {
+	if limit <= 0 || len(rows) <= limit {
+		return rows, nil
+	}
+
+	rows = rows[:limit]
+	next := cursorOf(rows[limit-1])
+	return rows, &next
+}
+
+func userCursor(user model.UserRecord) model.Cursor {
+	return model.Cursor{CreatedAt: user.CreatedAt, Id: user.Id}
+}
+
+func followCursor(record followRecord) model.Cursor {
+	return model.Cursor{CreatedAt: record.FollowedAt, Id: record.Id}
+}
+
+func scoredCursor(record scoredRecord) model.Cursor {
+	return model.Cursor{Score: record.Score, CreatedAt: record.CreatedAt, Id: record.Id}
+}
+
+func followRecordsToUsers(records []followRecord) []model.UserRecord {
+	users := make([]model.UserRecord, len(records))
+	for i, record := range records {
+		users[i] = record.UserRecord
+	}
+	return users
+}
+
+func scoredRecordsToUsers(records []scoredRecord) []model.UserRecord {
+	users := make([]model.UserRecord, len(records))
+	for i, record := range records {
+		users[i] = record.UserRecord
+	}
+	return users
+}
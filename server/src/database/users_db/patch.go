@@ -0,0 +1,104 @@
+package users_db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"users-service/src/database"
+	"users-service/src/model"
+
+	"github.com/google/uuid"
+)
+
+func (postDB *UsersPostgresDB) PatchUser(id uuid.UUID, version int, changes model.UserProfileChanges) (model.UserRecord, error) {
+	q := queryBuilder{}
+	q.bind(id)
+
+	assignments := []string{"version = version + 1"}
+	set := func(column string, value interface{}) {
+		assignments = append(assignments, fmt.Sprintf("%s = %s", column, q.bind(value)))
+	}
+	if changes.UserName != nil {
+		set("username", *changes.UserName)
+	}
+	if changes.FirstName != nil {
+		set("first_name", *changes.FirstName)
+	}
+	if changes.LastName != nil {
+		set("last_name", *changes.LastName)
+	}
+	if changes.PicturePath != nil {
+		set("picture_path", *changes.PicturePath)
+	}
+	if changes.LocationId != nil {
+		set("location_id", *changes.LocationId)
+	}
+	if changes.Bio != nil {
+		set("bio", *changes.Bio)
+	}
+	if changes.Website != nil {
+		set("website", *changes.Website)
+	}
+	if changes.BirthDate != nil {
+		set("birth_date", *changes.BirthDate)
+	}
+	if changes.Pronouns != nil {
+		set("pronouns", *changes.Pronouns)
+	}
+	if changes.BannerPath != nil {
+		set("banner_path", *changes.BannerPath)
+	}
+	if changes.Visibility != nil {
+		set("field_visibility", changes.Visibility)
+	}
+
+	q.conditions = append(q.conditions, "id = $1")
+	if version != 0 {
+		q.conditions = append(q.conditions, "version = "+q.bind(version))
+	}
+
+	tx, err := postDB.db.Beginx()
+	if err != nil {
+		return model.UserRecord{}, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	previousUsername, err := lockUsername(tx, id)
+	if err != nil {
+		return model.UserRecord{}, err
+	}
+
+	var user model.UserRecord
+	query := fmt.Sprintf(`UPDATE %s SET %s WHERE %s RETURNING *`, usersTable, strings.Join(assignments, ", "), strings.Join(q.conditions, " AND "))
+	if err := tx.Get(&user, query, q.args...); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return model.UserRecord{}, fmt.Errorf("error patching user: %w", err)
+		}
+		// the user is locked, so it has another version
+		return model.UserRecord{}, database.ErrVersionMismatch
+	}
+
+	if err := recordUsernameChange(tx, id, previousUsername, user.UserName); err != nil {
+		return model.UserRecord{}, err
+	}
+
+	if changes.InterestIds != nil {
+		if _, err := tx.Exec(`DELETE FROM user_interests WHERE user_id = $1`, id); err != nil {
+			return model.UserRecord{}, fmt.Errorf("error deleting user interests: %w", err)
+		}
+		for _, interestId := range changes.InterestIds {
+			if _, err := tx.Exec(`INSERT INTO user_interests (user_id, interest_id) VALUES ($1, $2)`, id, interestId); err != nil {
+				return model.UserRecord{}, fmt.Errorf("error inserting interest record: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.UserRecord{}, fmt.Errorf("error committing patch: %w", err)
+	}
+	if err := postDB.attachCatalogNamesToUser(&user); err != nil {
+		return model.UserRecord{}, fmt.Errorf("error getting interests for user: %w", err)
+	}
+	return user, nil
+}
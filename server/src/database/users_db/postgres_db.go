@@ -18,9 +18,17 @@ import (
 )
 
 const (
-	usersTable     = "users"
-	interestsTable = "user_interests"
-	followersTable = "followers"
+	usersTable                   = "users"
+	interestsTable               = "user_interests"
+	followersTable               = "followers"
+	dismissalsTable              = "recommendation_dismissals"
+	impressionsTable             = "recommendation_impressions"
+	recommendationsTable         = "user_recommendations"
+	recommendationRefreshesTable = "recommendation_refreshes"
+
+	// the catalogs are owned by the catalog database, the users reference their entries by id
+	interestsCatalogTable = "interests"
+	locationsCatalogTable = "locations"
 )
 
 type UsersPostgresDB struct {
@@ -46,13 +54,21 @@ func createTables(db *sqlx.DB, test bool) error {
 			DROP TABLE IF EXISTS %s CASCADE;
 			DROP TABLE IF EXISTS %s CASCADE;
 			DROP TABLE IF EXISTS %s CASCADE;
-			`, usersTable, interestsTable, followersTable)
+			DROP TABLE IF EXISTS %s CASCADE;
+			DROP TABLE IF EXISTS %s CASCADE;
+			DROP TABLE IF EXISTS %s CASCADE;
+			DROP TABLE IF EXISTS %s CASCADE;
+			DROP TABLE IF EXISTS %s CASCADE;
+			DROP TABLE IF EXISTS %s CASCADE;
+			DROP TABLE IF EXISTS %s CASCADE;
+			`, usersTable, interestsTable, followersTable, dismissalsTable, impressionsTable, recommendationsTable, recommendationRefreshesTable, usernameHistoryTable, serviceClientsTable, userIdentitiesTable)
 
 		if _, err := db.Exec(dropTables); err != nil {
 			return fmt.Errorf("failed to drop database: %w", err)
 		}
 	}
 
+	// the usernames are unique regardless of their case, the index on them was created as a plain one at first
 	schemaUsers := fmt.Sprintf(`
 		CREATE TABLE IF NOT EXISTS %s (
 			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
@@ -62,20 +78,45 @@ func createTables(db *sqlx.DB, test bool) error {
 			last_name VARCHAR(%d) NOT NULL,
 			email VARCHAR(%d) NOT NULL UNIQUE,
 			password TEXT NOT NULL,
-			location VARCHAR(255) NOT NULL,
+			location_id INTEGER NOT NULL REFERENCES locations(id),
 			blocked BOOLEAN DEFAULT FALSE,
+			followers_count INT NOT NULL DEFAULT 0,
+			following_count INT NOT NULL DEFAULT 0,
 			created_at TIMESTAMPTZ NOT NULL DEFAULT now()
 		);
+
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS followers_count INT NOT NULL DEFAULT 0;
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS following_count INT NOT NULL DEFAULT 0;
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS bio VARCHAR(%[6]d) NOT NULL DEFAULT '';
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS website VARCHAR(%[7]d) NOT NULL DEFAULT '';
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS birth_date DATE;
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS pronouns VARCHAR(%[8]d) NOT NULL DEFAULT '';
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS banner_path TEXT NOT NULL DEFAULT '';
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS field_visibility JSONB NOT NULL DEFAULT '{}';
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
 		
 		CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users(username);
 		CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users(email);
-		`, usersTable, constants.MaxUsernameLength, constants.MaxFirstNameLength, constants.MaxLastNameLength, constants.MaxEmailLength)
+		DO $$
+		BEGIN
+			IF EXISTS (
+				SELECT 1
+				FROM pg_index i
+				JOIN pg_class c ON c.oid = i.indexrelid
+				WHERE c.relname = 'idx_users_lower_username' AND NOT i.indisunique
+			) THEN
+				DROP INDEX idx_users_lower_username;
+			END IF;
+		END $$;
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_users_lower_username ON users(LOWER(username));
+		`, usersTable, constants.MaxUsernameLength, constants.MaxFirstNameLength, constants.MaxLastNameLength, constants.MaxEmailLength,
+		constants.MaxBioLength, constants.MaxWebsiteLength, constants.MaxPronounsLength)
 
 	schemaInterests := fmt.Sprintf(`
 		CREATE TABLE IF NOT EXISTS %s (
 			user_id UUID NOT NULL,
-			interest VARCHAR(255) NOT NULL,
-			PRIMARY KEY (user_id, interest),
+			interest_id INTEGER NOT NULL REFERENCES interests(id),
+			PRIMARY KEY (user_id, interest_id),
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 			);
 		`, interestsTable)
@@ -97,68 +138,134 @@ func createTables(db *sqlx.DB, test bool) error {
 	if _, err := db.Exec(schemaInterests); err != nil {
 		return fmt.Errorf("failed to create table: %w", err)
 	}
+	if err := migrateToCatalogIds(db); err != nil {
+		return fmt.Errorf("failed to migrate users to catalog ids: %w", err)
+	}
 	if _, err := db.Exec(schemaFollowers); err != nil {
 		return fmt.Errorf("failed to create table: %w", err)
 	}
+	if err := createRecommendationTables(db); err != nil {
+		return fmt.Errorf("failed to create recommendation tables: %w", err)
+	}
+	if err := createSearchIndexes(db); err != nil {
+		return fmt.Errorf("failed to create search indexes: %w", err)
+	}
+	if err := createUsernameHistoryTable(db); err != nil {
+		return fmt.Errorf("failed to create username history table: %w", err)
+	}
+	if err := createServiceClientsTable(db); err != nil {
+		return fmt.Errorf("failed to create service clients table: %w", err)
+	}
+	if err := createUserIdentitiesTable(db); err != nil {
+		return fmt.Errorf("failed to create user identities table: %w", err)
+	}
 
 	return nil
 }
 
-func (postDB *UsersPostgresDB) associateInterestsToUser(userId uuid.UUID, interests []string) ([]string, error) {
-	var insertedInterests []string
+// migrateToCatalogIds replaces the location and interest names stored before the catalogs existed with their ids.
+// Names that are not in the catalogs are added as deprecated entries, so no user loses them
+func migrateToCatalogIds(db *sqlx.DB) error {
+	migration := `
+	DO $$
+	BEGIN
+		IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'users' AND column_name = 'location') THEN
+			INSERT INTO locations (name, deprecated)
+			SELECT DISTINCT u.location, TRUE FROM users u
+			WHERE NOT EXISTS (SELECT 1 FROM locations l WHERE l.name = u.location);
+
+			ALTER TABLE users ADD COLUMN IF NOT EXISTS location_id INTEGER REFERENCES locations(id);
+			UPDATE users u SET location_id = l.id FROM locations l WHERE l.name = u.location;
+			ALTER TABLE users DROP COLUMN location;
+			ALTER TABLE users ALTER COLUMN location_id SET NOT NULL;
+		END IF;
+
+		IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'user_interests' AND column_name = 'interest') THEN
+			INSERT INTO interests (name, deprecated)
+			SELECT DISTINCT ui.interest, TRUE FROM user_interests ui
+			WHERE NOT EXISTS (SELECT 1 FROM interests i WHERE i.name = ui.interest);
+
+			ALTER TABLE user_interests ADD COLUMN interest_id INTEGER REFERENCES interests(id);
+			UPDATE user_interests ui SET interest_id = i.id FROM interests i WHERE i.name = ui.interest;
+			ALTER TABLE user_interests DROP COLUMN interest;
+			ALTER TABLE user_interests ALTER COLUMN interest_id SET NOT NULL;
+			ALTER TABLE user_interests ADD PRIMARY KEY (user_id, interest_id);
+		END IF;
+	END $$;
+	`
+
+	_, err := db.Exec(migration)
+	return err
+}
+
+func associateInterestsToUser(e sqlx.Execer, userId uuid.UUID, interestIds []int) error {
 	query := `
-		INSERT INTO user_interests (user_id, interest)
+		INSERT INTO user_interests (user_id, interest_id)
 		VALUES ($1, $2)
-		RETURNING interest;
 	`
 
-	for _, interest := range interests {
-		var interestRecord string
-		err := postDB.db.QueryRow(query, userId, interest).Scan(&interestRecord)
-		if err != nil {
-			return nil, fmt.Errorf("error inserting interest record: %w", err)
+	for _, interestId := range interestIds {
+		if _, err := e.Exec(query, userId, interestId); err != nil {
+			return fmt.Errorf("error inserting interest record: %w", err)
 		}
-		insertedInterests = append(insertedInterests, interestRecord)
 	}
 
-	return insertedInterests, nil
+	return nil
 }
 
 
-func (postDB *UsersPostgresDB) updateUserInterests(userId uuid.UUID, interests []string) ([]string, error) {
+func updateUserInterests(e sqlx.Execer, userId uuid.UUID, interestIds []int) error {
 	query := `DELETE FROM user_interests WHERE user_id = $1`
-	_, err := postDB.db.Exec(query, userId)
+	_, err := e.Exec(query, userId)
 	if err != nil {
-		return nil, fmt.Errorf("error deleting user interests: %w", err)
+		return fmt.Errorf("error deleting user interests: %w", err)
 	}
 
-	return postDB.associateInterestsToUser(userId, interests)
+	return associateInterestsToUser(e, userId, interestIds)
 }
 
-func (postDB *UsersPostgresDB) CreateUser(data model.UserRecord) (model.UserRecord, error) {
+func (postDB *UsersPostgresDB) CreateUser(data model.UserRecord, identity *model.UserIdentity) (model.UserRecord, error) {
 	var user model.UserRecord
 	query := `
-        INSERT INTO users (username, first_name, last_name, email, password, location)
-        VALUES (:username, :first_name, :last_name, :email, :password, :location)
-        RETURNING id, username, first_name, last_name, email, password, location, created_at;
+        INSERT INTO users (username, first_name, last_name, email, password, location_id, birth_date)
+        VALUES (:username, :first_name, :last_name, :email, :password, :location_id, :birth_date)
+        RETURNING id, username, first_name, last_name, email, password, location_id, followers_count, following_count,
+            bio, website, birth_date, pronouns, banner_path, field_visibility, version, created_at;
     `
 
-	rows, err := postDB.db.NamedQuery(query, data)
+	tx, err := postDB.db.Beginx()
 	if err != nil {
-		return model.UserRecord{}, err
+		return model.UserRecord{}, fmt.Errorf("error starting transaction: %w", err)
 	}
-	defer rows.Close()
+	defer func() { _ = tx.Rollback() }()
 
+	rows, err := tx.NamedQuery(query, data)
+	if err != nil {
+		return model.UserRecord{}, err
+	}
 	if rows.Next() {
 		if err := rows.StructScan(&user); err != nil {
+			rows.Close()
 			return model.UserRecord{}, fmt.Errorf("error scanning user data: %w", err)
 		}
 	}
+	rows.Close()
 
-	user.Interests, err = postDB.associateInterestsToUser(user.Id, data.Interests)
-	if err != nil {
+	if err := associateInterestsToUser(tx, user.Id, data.InterestIds); err != nil {
 		return model.UserRecord{}, fmt.Errorf("error associating interests to user: %w", err)
 	}
+	if identity != nil {
+		identity.UserId = user.Id
+		if err := insertUserIdentity(tx, *identity); err != nil {
+			return model.UserRecord{}, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return model.UserRecord{}, fmt.Errorf("error committing user creation: %w", err)
+	}
+	if err := postDB.attachCatalogNamesToUser(&user); err != nil {
+		return model.UserRecord{}, fmt.Errorf("error getting interests for user: %w", err)
+	}
 	return user, nil
 }
 
@@ -166,36 +273,67 @@ func (postDB *UsersPostgresDB) ModifyUser(id uuid.UUID, data model.UpdateUserPri
 	var user model.UserRecord
 	query := `
 		UPDATE users
-		SET username = :username, first_name = :first_name, last_name = :last_name, location = :location, picture_path = :picture_path
+		SET username = :username, first_name = :first_name, last_name = :last_name, location_id = :location_id, picture_path = :picture_path,
+			bio = :bio, website = :website, birth_date = :birth_date, pronouns = :pronouns, banner_path = :banner_path, field_visibility = :field_visibility,
+			version = version + 1
 		WHERE id = :id
-		RETURNING id, username, first_name, last_name, email, location, picture_path
+		RETURNING id, username, first_name, last_name, email, location_id, picture_path, followers_count, following_count,
+			bio, website, birth_date, pronouns, banner_path, field_visibility, version
 	`
 
-	rows, err := postDB.db.NamedQuery(query, map[string]interface{}{
+	tx, err := postDB.db.Beginx()
+	if err != nil {
+		return model.UserRecord{}, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	previousUsername, err := lockUsername(tx, id)
+	if err != nil {
+		return model.UserRecord{}, err
+	}
+
+	rows, err := tx.NamedQuery(query, map[string]interface{}{
 		"id":         id,
 		"username":   data.UserName,
 		"first_name": data.FirstName,
 		"last_name":  data.LastName,
-		"location":   data.Location,
+		"location_id": data.LocationId,
 		"picture_path": data.PicturePath,
+		"bio":          data.Bio,
+		"website":      data.Website,
+		"birth_date":   data.BirthDate,
+		"pronouns":     data.Pronouns,
+		"banner_path":  data.BannerPath,
+		"field_visibility": data.Visibility,
 	})
 	if err != nil {
 		return model.UserRecord{}, err
 	}
-	defer rows.Close()
 
 	if rows.Next() {
 		if err := rows.StructScan(&user); err != nil {
+			rows.Close()
 			return model.UserRecord{}, fmt.Errorf("error scanning user data: %w", err)
 		}
 	} else {
+		rows.Close()
 		return model.UserRecord{}, fmt.Errorf("error: no user updated")
 	}
+	rows.Close()
 
-	user.Interests, err = postDB.updateUserInterests(id, data.Interests)
-	if err != nil {
+	if err := recordUsernameChange(tx, id, previousUsername, user.UserName); err != nil {
+		return model.UserRecord{}, err
+	}
+	if err := updateUserInterests(tx, id, data.InterestIds); err != nil {
 		return model.UserRecord{}, fmt.Errorf("error updating user interests: %w", err)
 	}
+	if err := tx.Commit(); err != nil {
+		return model.UserRecord{}, fmt.Errorf("error committing user update: %w", err)
+	}
+
+	if err := postDB.attachCatalogNamesToUser(&user); err != nil {
+		return model.UserRecord{}, fmt.Errorf("error getting interests for user: %w", err)
+	}
 	return user, nil
 }
 
@@ -231,8 +369,7 @@ func (postDB *UsersPostgresDB) GetUserById(id uuid.UUID) (model.UserRecord, erro
 		return model.UserRecord{}, fmt.Errorf("error fetching user by Id: %w", err)
 	}
 
-	user.Interests, err = postDB.getInterestsForUserId(id)
-	if err != nil {
+	if err := postDB.attachCatalogNamesToUser(&user); err != nil {
 		return model.UserRecord{}, fmt.Errorf("error getting interests for user: %w", err)
 	}
 	fmt.Println("user in db: ", user)
@@ -251,8 +388,25 @@ func (postDB *UsersPostgresDB) GetUserByEmail(email string) (model.UserRecord, e
 		return model.UserRecord{}, fmt.Errorf("error fetching user by email: %w", err)
 	}
 
-	user.Interests, err = postDB.getInterestsForUserId(user.Id)
+	if err := postDB.attachCatalogNamesToUser(&user); err != nil {
+		return model.UserRecord{}, fmt.Errorf("error getting interests for user: %w", err)
+	}
+	return user, nil
+}
+
+func (postDB *UsersPostgresDB) GetUserByUsername(username string) (model.UserRecord, error) {
+	var user model.UserRecord
+	query := `SELECT * FROM users WHERE LOWER(username) = LOWER($1) LIMIT 1`
+	err := postDB.db.Get(&user, query, username)
+
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.UserRecord{}, database.ErrKeyNotFound
+		}
+		return model.UserRecord{}, fmt.Errorf("error fetching user by username: %w", err)
+	}
+
+	if err := postDB.attachCatalogNamesToUser(&user); err != nil {
 		return model.UserRecord{}, fmt.Errorf("error getting interests for user: %w", err)
 	}
 	return user, nil
@@ -282,39 +436,128 @@ func (postDB *UsersPostgresDB) CheckIfEmailExists(email string) (bool, error) {
 	return exists, nil
 }
 
-func (postDB *UsersPostgresDB) getInterestsForUserId(id uuid.UUID) ([]string, error) {
-	var interests []string
-	query := `
-		SELECT interest
-		FROM user_interests
-		WHERE user_id = $1
-	`
+// userInterest is an interest of the catalog chosen by a user
+type userInterest struct {
+	UserId uuid.UUID `db:"user_id"`
+	Id     int       `db:"id"`
+	Name   string    `db:"name"`
+}
+
+// getInterestsForUserIds retrieves the interests of all the given users in a single query, sorted as in the catalog
+func (postDB *UsersPostgresDB) getInterestsForUserIds(ids []uuid.UUID) (map[uuid.UUID][]userInterest, error) {
+	interests := make(map[uuid.UUID][]userInterest, len(ids))
+	if len(ids) == 0 {
+		return interests, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT ui.user_id, i.id, i.name
+		FROM %s ui
+		JOIN %s i ON i.id = ui.interest_id
+		WHERE ui.user_id = ANY($1)
+		ORDER BY i.position, i.id
+	`, interestsTable, interestsCatalogTable)
+
+	var rows []userInterest
+	if err := postDB.db.Select(&rows, query, pq.Array(ids)); err != nil {
+		return nil, fmt.Errorf("error getting interests for users: %w", err)
+	}
+
+	for _, row := range rows {
+		interests[row.UserId] = append(interests[row.UserId], row)
+	}
+	return interests, nil
+}
+
+// getLocationNames retrieves the current names of the given locations of the catalog, followed by the names
+// of the locations they are in, like "Rosario, Santa Fe, Argentina"
+func (postDB *UsersPostgresDB) getLocationNames(ids []int) (map[int]string, error) {
+	names := make(map[int]string, len(ids))
+	if len(ids) == 0 {
+		return names, nil
+	}
+
+	var rows []struct {
+		Id   int    `db:"id"`
+		Name string `db:"name"`
+	}
+	query := fmt.Sprintf(`
+		WITH RECURSIVE path AS (
+			SELECT id AS location_id, parent_id, name::text AS name
+			FROM %[1]s
+			WHERE id = ANY($1)
+			UNION ALL
+			SELECT p.location_id, l.parent_id, p.name || ', ' || l.name
+			FROM path p
+			JOIN %[1]s l ON l.id = p.parent_id
+		)
+		SELECT location_id AS id, name FROM path WHERE parent_id IS NULL
+	`, locationsCatalogTable)
+	if err := postDB.db.Select(&rows, query, pq.Array(ids)); err != nil {
+		return nil, fmt.Errorf("error getting location names: %w", err)
+	}
+
+	for _, row := range rows {
+		names[row.Id] = row.Name
+	}
+	return names, nil
+}
+
+// attachCatalogNames fills the interests and the location name of the given users with a query for each,
+// the names are read from the catalogs so they are always the current ones
+func (postDB *UsersPostgresDB) attachCatalogNames(users []model.UserRecord) error {
+	ids := make([]uuid.UUID, len(users))
+	locationIds := make([]int, len(users))
+	for i, user := range users {
+		ids[i] = user.Id
+		locationIds[i] = user.LocationId
+	}
+
+	interests, err := postDB.getInterestsForUserIds(ids)
+	if err != nil {
+		return err
+	}
 
-	rows, err := postDB.db.Query(query, id)
+	locations, err := postDB.getLocationNames(locationIds)
 	if err != nil {
-		return nil, fmt.Errorf("error getting interests for user: %w", err)
+		return err
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var interest string
-		err := rows.Scan(&interest)
-		if err != nil {
-			return nil, fmt.Errorf("error scanning interest: %w", err)
+	for i := range users {
+		users[i].Location = locations[users[i].LocationId]
+		users[i].InterestIds = make([]int, len(interests[users[i].Id]))
+		users[i].Interests = make([]string, len(interests[users[i].Id]))
+		for j, interest := range interests[users[i].Id] {
+			users[i].InterestIds[j] = interest.Id
+			users[i].Interests[j] = interest.Name
 		}
-		interests = append(interests, interest)
 	}
+	return nil
+}
 
-	return interests, nil
+// attachCatalogNamesToUser fills the interests and the location name of a single user
+func (postDB *UsersPostgresDB) attachCatalogNamesToUser(user *model.UserRecord) error {
+	users := []model.UserRecord{*user}
+	if err := postDB.attachCatalogNames(users); err != nil {
+		return err
+	}
+	*user = users[0]
+	return nil
 }
 
 func (postDB *UsersPostgresDB) FollowUser(followerId uuid.UUID, followingId uuid.UUID) error {
+	tx, err := postDB.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
 	query := `
 		INSERT INTO followers (follower_id, following_id)
 		VALUES ($1, $2)
 	`
 
-	_, err := postDB.db.Exec(query, followerId, followingId)
+	_, err = tx.Exec(query, followerId, followingId)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
 			if pqErr.Code == "23505" { // Código de error para violación de unicidad en PostgreSQL
@@ -323,16 +566,30 @@ func (postDB *UsersPostgresDB) FollowUser(followerId uuid.UUID, followingId uuid
 		}
 		return fmt.Errorf("error following user: %w", err)
 	}
+
+	if err := updateFollowCounters(tx, followerId, followingId, 1); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing follow: %w", err)
+	}
 	return nil
 }
 
 func (postDB *UsersPostgresDB) UnfollowUser(followerId uuid.UUID, followingId uuid.UUID) error {
+	tx, err := postDB.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
 	query := `
 		DELETE FROM followers
 		WHERE follower_id = $1 AND following_id = $2
 	`
 
-	res, err := postDB.db.Exec(query, followerId, followingId)
+	res, err := tx.Exec(query, followerId, followingId)
 	if err != nil {
 		return fmt.Errorf("error unfollowing user: %w", err)
 	}
@@ -345,6 +602,28 @@ func (postDB *UsersPostgresDB) UnfollowUser(followerId uuid.UUID, followingId uu
 	if rowsAffected == 0 {
 		return database.ErrKeyNotFound
 	}
+
+	if err := updateFollowCounters(tx, followerId, followingId, -1); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing unfollow: %w", err)
+	}
+	return nil
+}
+
+// updateFollowCounters adds delta to the following counter of the follower and to the followers counter of the followed user
+func updateFollowCounters(tx *sqlx.Tx, followerId uuid.UUID, followingId uuid.UUID, delta int) error {
+	query := `UPDATE users SET following_count = GREATEST(following_count + $2, 0) WHERE id = $1`
+	if _, err := tx.Exec(query, followerId, delta); err != nil {
+		return fmt.Errorf("error updating following counter: %w", err)
+	}
+
+	query = `UPDATE users SET followers_count = GREATEST(followers_count + $2, 0) WHERE id = $1`
+	if _, err := tx.Exec(query, followingId, delta); err != nil {
+		return fmt.Errorf("error updating followers counter: %w", err)
+	}
 	return nil
 }
 
@@ -360,83 +639,113 @@ func (postDB *UsersPostgresDB) CheckIfUserFollows(followerId uuid.UUID, followin
 	return exists, nil
 }
 
-func (postDB *UsersPostgresDB) GetAmountOfFollowers(userId uuid.UUID) (int, error) {
-	var followers int
-	query := `SELECT COUNT(*) FROM followers WHERE following_id = $1`
-	err := postDB.db.Get(&followers, query, userId)
+func (postDB *UsersPostgresDB) ReconcileFollowCounters() (int, error) {
+	query := `
+		WITH counts AS (
+			SELECT
+				u.id,
+				(SELECT COUNT(*) FROM followers f WHERE f.following_id = u.id) AS followers,
+				(SELECT COUNT(*) FROM followers f WHERE f.follower_id = u.id) AS following
+			FROM users u
+		)
+		UPDATE users u
+		SET followers_count = c.followers, following_count = c.following
+		FROM counts c
+		WHERE u.id = c.id
+		AND (u.followers_count <> c.followers OR u.following_count <> c.following)
+	`
 
+	res, err := postDB.db.Exec(query)
 	if err != nil {
-		return 0, fmt.Errorf("error getting amount of followers: %w", err)
+		return 0, fmt.Errorf("error reconciling follow counters: %w", err)
 	}
-	return followers, nil
+
+	repaired, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error getting rows affected: %w", err)
+	}
+	return int(repaired), nil
 }
 
-func (postDB *UsersPostgresDB) GetAmountOfFollowing(userId uuid.UUID) (int, error) {
-	var following int
-	query := `SELECT COUNT(*) FROM followers WHERE follower_id = $1`
-	err := postDB.db.Get(&following, query, userId)
+func (postDB *UsersPostgresDB) GetFollowedUsersAmong(followerId uuid.UUID, userIds []uuid.UUID) (map[uuid.UUID]bool, error) {
+	followed := make(map[uuid.UUID]bool, len(userIds))
+	if len(userIds) == 0 {
+		return followed, nil
+	}
 
+	var followedIds []uuid.UUID
+	query := `
+		SELECT following_id
+		FROM followers
+		WHERE follower_id = $1
+		AND following_id = ANY($2)
+	`
+
+	err := postDB.db.Select(&followedIds, query, followerId, pq.Array(userIds))
 	if err != nil {
-		return 0, fmt.Errorf("error getting amount of following: %w", err)
+		return nil, fmt.Errorf("error getting followed users: %w", err)
+	}
+
+	for _, id := range followedIds {
+		followed[id] = true
 	}
-	return following, nil
+	return followed, nil
 }
 
-func (postDB *UsersPostgresDB) GetFollowers(userId uuid.UUID, timestamp string, skip int, limit int) ([]model.UserRecord, bool, error) {
-	var followers []model.UserRecord
+func (postDB *UsersPostgresDB) GetFollowers(userId uuid.UUID, page model.PageRequest) ([]model.UserRecord, *model.Cursor, error) {
+	var followers []followRecord
 	query := `
-		SELECT u.*
+		SELECT u.*, f.created_at AS followed_at
 		FROM users u
 		JOIN followers f ON u.id = f.follower_id
 		WHERE f.following_id = $1
-		AND f.created_at < $2
-		ORDER BY f.created_at DESC
-		OFFSET $3
-		LIMIT $4
+		AND (f.created_at, u.id) < ($2, $3)
+		ORDER BY f.created_at DESC, u.id DESC
+		OFFSET $4
+		LIMIT $5
 	`
 
-	err := postDB.db.Select(&followers, query, userId, timestamp, skip, limit+1)
+	after := page.After()
+	err := postDB.db.Select(&followers, query, userId, after.CreatedAt, after.Id, page.Offset(), page.Limit+1)
 	if err != nil {
-		return nil, false, fmt.Errorf("error getting followers: %w", err)
-	}
-
-	if len(followers) == limit+1 {
-		return followers[:limit], true, nil
+		return nil, nil, fmt.Errorf("error getting followers: %w", err)
 	}
 
-	for i := range followers {
-		followers[i].Interests, err = postDB.getInterestsForUserId(followers[i].Id)
-		if err != nil {
-			return nil, false, fmt.Errorf("error getting interests for user: %w", err)
-		}
+	followers, next := paginate(followers, page.Limit, followCursor)
+	users := followRecordsToUsers(followers)
+	if err := postDB.attachCatalogNames(users); err != nil {
+		return nil, nil, fmt.Errorf("error getting interests for users: %w", err)
 	}
 
-	return followers, false, nil
+	return users, next, nil
 }
 
-func (postDB *UsersPostgresDB) GetFollowing(userId uuid.UUID, timestamp string, skip int, limit int) ([]model.UserRecord, bool, error) {
-	var following []model.UserRecord
+func (postDB *UsersPostgresDB) GetFollowing(userId uuid.UUID, page model.PageRequest) ([]model.UserRecord, *model.Cursor, error) {
+	var following []followRecord
 	query := `
-		SELECT u.*
+		SELECT u.*, f.created_at AS followed_at
 		FROM users u
 		JOIN followers f ON u.id = f.following_id
 		WHERE f.follower_id = $1
-		AND f.created_at < $2
-		ORDER BY f.created_at DESC
-		OFFSET $3
-		LIMIT $4
+		AND (f.created_at, u.id) < ($2, $3)
+		ORDER BY f.created_at DESC, u.id DESC
+		OFFSET $4
+		LIMIT $5
 	`
 
-	err := postDB.db.Select(&following, query, userId, timestamp, skip, limit+1)
+	after := page.After()
+	err := postDB.db.Select(&following, query, userId, after.CreatedAt, after.Id, page.Offset(), page.Limit+1)
 	if err != nil {
-		return nil, false, fmt.Errorf("error getting following: %w", err)
+		return nil, nil, fmt.Errorf("error getting following: %w", err)
 	}
 
-	if len(following) == limit+1 {
-		return following[:limit], true, nil
+	following, next := paginate(following, page.Limit, followCursor)
+	users := followRecordsToUsers(following)
+	if err := postDB.attachCatalogNames(users); err != nil {
+		return nil, nil, fmt.Errorf("error getting interests for users: %w", err)
 	}
 
-	return following, false, nil
+	return users, next, nil
 }
 
 func (postDB *UsersPostgresDB) GetAmountOfFollowersInTimeRange(userId uuid.UUID, startTime, endTime time.Time) (int, error) {
@@ -450,196 +759,29 @@ func (postDB *UsersPostgresDB) GetAmountOfFollowersInTimeRange(userId uuid.UUID,
 	return followers, nil
 }
 
-func (postDB *UsersPostgresDB) GetAllUsers(timestamp string, skip int, limit int) ([]model.UserRecord, bool, error) {
+func (postDB *UsersPostgresDB) GetAllUsers(page model.PageRequest) ([]model.UserRecord, *model.Cursor, error) {
 	var users []model.UserRecord
 	query := `
 		SELECT *
 		FROM users
-		WHERE created_at < $1
-		ORDER BY created_at DESC
-		OFFSET $2
-		LIMIT $3
-	`
-
-	err := postDB.db.Select(&users, query, timestamp, skip, limit+1)
-	if err != nil {
-		return nil, false, fmt.Errorf("error getting all users: %w", err)
-	}
-
-	if len(users) == limit+1 {
-		return users[:limit], true, nil
-	}
-
-	for i := range users {
-		users[i].Interests, err = postDB.getInterestsForUserId(users[i].Id)
-		if err != nil {
-			return nil, false, fmt.Errorf("error getting interests for user: %w", err)
-		}
-	}
-
-	return users, false, nil
-}
-
-func (postDB *UsersPostgresDB) GetUsersWithUsernameContaining(text string, timestamp string, skip int, limit int) ([]model.UserRecord, bool, error) {
-	var users []model.UserRecord
-	query := `
-		SELECT *
-		FROM users
-		WHERE username ILIKE $1
-		AND created_at < $2
-		ORDER BY created_at DESC
-		OFFSET $3
-		LIMIT $4
-	`
-
-	err := postDB.db.Select(&users, query, "%"+text+"%", timestamp, skip, limit+1)
-	if err != nil {
-		return nil, false, fmt.Errorf("error getting users with username containing: %w", err)
-	}
-
-	if len(users) == limit+1 {
-		return users[:limit], true, nil
-	}
-
-	for i := range users {
-		users[i].Interests, err = postDB.getInterestsForUserId(users[i].Id)
-		if err != nil {
-			return nil, false, fmt.Errorf("error getting interests for user: %w", err)
-		}
-	}
-
-	return users, false, nil
-}
-
-func (postDB *UsersPostgresDB) GetAmountOfUsersWithUsernameContaining(text string) (int, error) {
-	var amount int
-	query := `SELECT COUNT(*) FROM users WHERE username ILIKE $1`
-	err := postDB.db.Get(&amount, query, "%"+text+"%")
-
-	if err != nil {
-		return 0, fmt.Errorf("error getting amount of users with username containing: %w", err)
-	}
-
-	return amount, nil
-}
-
-func (postDB *UsersPostgresDB) GetUsersWithOnlyNameContaining(text string, timestamp string, skip int, limit int) ([]model.UserRecord, bool, error) {
-	var users []model.UserRecord
-	query := `
-		SELECT *
-		FROM users
-		WHERE (first_name ILIKE $1 OR last_name ILIKE $1)
-		AND username NOT ILIKE $1
-		AND created_at < $2
-		ORDER BY created_at DESC
+		WHERE (created_at, id) < ($1, $2)
+		ORDER BY created_at DESC, id DESC
 		OFFSET $3
 		LIMIT $4
 	`
 
-	err := postDB.db.Select(&users, query, "%"+text+"%", timestamp, skip, limit+1)
+	after := page.After()
+	err := postDB.db.Select(&users, query, after.CreatedAt, after.Id, page.Offset(), page.Limit+1)
 	if err != nil {
-		return nil, false, fmt.Errorf("error getting users with name containing: %w", err)
+		return nil, nil, fmt.Errorf("error getting all users: %w", err)
 	}
 
-	if len(users) == limit+1 {
-		return users[:limit], true, nil
-	}
-
-	return users, false, nil
-}
-
-func (postDB *UsersPostgresDB) GetRecommendations(userId uuid.UUID, timestamp string, skip int, limit int) ([]model.UserRecord, bool, error) {
-	var users []model.UserRecord
-	query := `
-		WITH temp AS (
-			SELECT DISTINCT ON (id)
-				id, 
-				username, 
-				first_name, 
-				last_name, 
-				email, 
-				location, 
-				created_at, 
-				priority
-			FROM (
-				(SELECT u.id, u.username, u.first_name, u.last_name, u.email, u.location, u.created_at, 1 AS priority
-				FROM users u
-				LEFT JOIN followers f ON u.id = f.following_id AND f.follower_id = $1
-				JOIN user_interests ui ON u.id = ui.user_id
-				JOIN users u2 ON u.location = u2.location AND u2.id = $1
-				WHERE u.id != $1
-				AND f.following_id IS NULL
-				AND EXISTS (
-					SELECT 1
-					FROM user_interests ui2
-					WHERE ui2.user_id = $1
-						AND ui2.interest = ui.interest
-				)
-				AND u.created_at < $2
-				ORDER BY u.created_at DESC
-				LIMIT $4)
-
-				UNION ALL
-
-				(SELECT u.id, u.username, u.first_name, u.last_name, u.email, u.location, u.created_at, 2 AS priority
-				FROM users u
-				LEFT JOIN followers f ON u.id = f.following_id AND f.follower_id = $1
-				JOIN users u2 ON u.location = u2.location AND u2.id = $1
-				WHERE u.id != $1
-				AND f.following_id IS NULL
-				AND u.created_at < $2
-				ORDER BY u.created_at DESC
-				LIMIT $4)
-
-				UNION ALL
-
-				(SELECT u.id, u.username, u.first_name, u.last_name, u.email, u.location, u.created_at, 3 AS priority
-				FROM users u
-				LEFT JOIN followers f ON u.id = f.following_id AND f.follower_id = $1
-				JOIN user_interests ui ON u.id = ui.user_id
-				WHERE u.id != $1
-				AND f.following_id IS NULL
-				AND EXISTS (
-					SELECT 1
-					FROM user_interests ui2
-					WHERE ui2.user_id = $1
-						AND ui2.interest = ui.interest
-				)
-				AND u.created_at < $2
-				ORDER BY u.created_at DESC
-				LIMIT $4)
-			)
-		)
-		SELECT 
-			p.id, 
-			p.username, 
-			p.first_name, 
-			p.last_name, 
-			p.email, 
-			p.location
-		FROM temp p
-		ORDER BY p.priority ASC, p.created_at DESC
-		OFFSET $3
-		LIMIT $4;
-	`
-
-	err := postDB.db.Select(&users, query, userId, timestamp, skip, limit+1)
-	if err != nil {
-		return nil, false, fmt.Errorf("error getting users with name containing: %w", err)
-	}
-
-	if len(users) == limit+1 {
-		return users[:limit], true, nil
-	}
-
-	for i := range users {
-		users[i].Interests, err = postDB.getInterestsForUserId(users[i].Id)
-		if err != nil {
-			return nil, false, fmt.Errorf("error getting interests for user: %w", err)
-		}
+	users, next := paginate(users, page.Limit, userCursor)
+	if err := postDB.attachCatalogNames(users); err != nil {
+		return nil, nil, fmt.Errorf("error getting interests for users: %w", err)
 	}
 
-	return users, false, nil
+	return users, next, nil
 }
 
 func (postDB *UsersPostgresDB) BlockUser(userId uuid.UUID, reason string) error {
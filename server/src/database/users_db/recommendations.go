@@ -0,0 +1,321 @@
+package users_db
+
+import (
+	"fmt"
+	"time"
+	"users-service/src/constants"
+	"users-service/src/model"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// createRecommendationTables creates the tables with the recommendations each user dismissed,
+// the amount of times each recommendation was shown to them and the precomputed recommendations
+func createRecommendationTables(db *sqlx.DB) error {
+	schema := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %[1]s (
+			user_id UUID NOT NULL,
+			dismissed_id UUID NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			expires_at TIMESTAMPTZ,
+			PRIMARY KEY (user_id, dismissed_id),
+			FOREIGN KEY (user_id) REFERENCES %[3]s(id) ON DELETE CASCADE,
+			FOREIGN KEY (dismissed_id) REFERENCES %[3]s(id) ON DELETE CASCADE
+		);
+
+		CREATE TABLE IF NOT EXISTS %[2]s (
+			user_id UUID NOT NULL,
+			shown_id UUID NOT NULL,
+			impressions INT NOT NULL DEFAULT 1,
+			listed_at TIMESTAMPTZ NOT NULL,
+			last_shown_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			PRIMARY KEY (user_id, shown_id),
+			FOREIGN KEY (user_id) REFERENCES %[3]s(id) ON DELETE CASCADE,
+			FOREIGN KEY (shown_id) REFERENCES %[3]s(id) ON DELETE CASCADE
+		);
+
+		CREATE TABLE IF NOT EXISTS %[4]s (
+			user_id UUID NOT NULL,
+			recommended_id UUID NOT NULL,
+			followed_by_following INT NOT NULL,
+			shared_interests INT NOT NULL,
+			same_location BOOLEAN NOT NULL,
+			score FLOAT8 NOT NULL,
+			PRIMARY KEY (user_id, recommended_id),
+			FOREIGN KEY (user_id) REFERENCES %[3]s(id) ON DELETE CASCADE,
+			FOREIGN KEY (recommended_id) REFERENCES %[3]s(id) ON DELETE CASCADE
+		);
+		ALTER TABLE %[4]s ADD COLUMN IF NOT EXISTS shared_categories INT NOT NULL DEFAULT 0;
+
+		CREATE TABLE IF NOT EXISTS %[5]s (
+			user_id UUID PRIMARY KEY,
+			refreshed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			FOREIGN KEY (user_id) REFERENCES %[3]s(id) ON DELETE CASCADE
+		);
+	`, dismissalsTable, impressionsTable, usersTable, recommendationsTable, recommendationRefreshesTable)
+
+	_, err := db.Exec(schema)
+	return err
+}
+
+// recommendationRecord is a recommended user along with its score and the signals it was computed from
+type recommendationRecord struct {
+	model.UserRecord
+	Score               float64 `db:"score"`
+	FollowedByFollowing int     `db:"followed_by_following"`
+	SharedInterests     int     `db:"shared_interests"`
+	SharedCategories    int     `db:"shared_categories"`
+	SameLocation        bool    `db:"same_location"`
+}
+
+// recommendationCursor keeps the time the listing started, so its next pages discount the same impressions
+func recommendationCursor(listedAt time.Time) func(recommendationRecord) model.Cursor {
+	return func(record recommendationRecord) model.Cursor {
+		return model.Cursor{Score: record.Score, CreatedAt: record.CreatedAt, Id: record.Id, ListedAt: listedAt}
+	}
+}
+
+// recommendationCandidatesQuery scores every user that could be recommended to the user bound to user,
+// before applying the dismissals and the impressions, which change too often to be precomputed.
+// The shared categories only count the ones where the users have no interest in common, so two users
+// into different kinds of movies still match while the shared interests aren't counted twice
+func recommendationCandidatesQuery(q *queryBuilder, user string) string {
+	return fmt.Sprintf(`
+		WITH following AS (
+			SELECT following_id AS id
+			FROM %[2]s
+			WHERE follower_id = %[4]s
+		),
+		second_degree AS (
+			SELECT f.following_id AS id, COUNT(*) AS amount
+			FROM %[2]s f
+			JOIN following ON following.id = f.follower_id
+			GROUP BY f.following_id
+		),
+		shared_interests AS (
+			SELECT ui.user_id AS id, COUNT(*) AS amount
+			FROM %[3]s ui
+			JOIN %[3]s own ON own.interest_id = ui.interest_id AND own.user_id = %[4]s
+			GROUP BY ui.user_id
+		),
+		own_categories AS (
+			SELECT c.category_id AS id, ARRAY_AGG(own.interest_id) AS interests
+			FROM %[3]s own
+			JOIN %[9]s c ON c.id = own.interest_id
+			WHERE own.user_id = %[4]s AND c.category_id IS NOT NULL
+			GROUP BY c.category_id
+		),
+		shared_categories AS (
+			SELECT ui.user_id AS id, COUNT(DISTINCT oc.id) AS amount
+			FROM %[3]s ui
+			JOIN %[9]s c ON c.id = ui.interest_id
+			JOIN own_categories oc ON oc.id = c.category_id
+			WHERE NOT EXISTS (
+				SELECT 1
+				FROM %[3]s other
+				WHERE other.user_id = ui.user_id AND other.interest_id = ANY(oc.interests)
+			)
+			GROUP BY ui.user_id
+		)
+		SELECT u.id,
+			COALESCE(sd.amount, 0)::int AS followed_by_following,
+			COALESCE(si.amount, 0)::int AS shared_interests,
+			COALESCE(sc.amount, 0)::int AS shared_categories,
+			(u.location_id = s.location_id) AS same_location,
+			(COALESCE(sd.amount, 0) * %[5]s::float8
+				+ COALESCE(si.amount, 0) * %[6]s::float8
+				+ COALESCE(sc.amount, 0) * %[10]s::float8
+				+ (CASE WHEN u.location_id = s.location_id THEN %[7]s::float8 ELSE 0 END)
+				+ LN(1 + u.followers_count) * %[8]s::float8
+			)::float8 AS score
+		FROM %[1]s u
+		JOIN %[1]s s ON s.id = %[4]s
+		LEFT JOIN second_degree sd ON sd.id = u.id
+		LEFT JOIN shared_interests si ON si.id = u.id
+		LEFT JOIN shared_categories sc ON sc.id = u.id
+		WHERE u.id <> %[4]s
+		AND u.blocked IS NOT TRUE
+		AND u.id NOT IN (SELECT id FROM following)
+		AND (sd.id IS NOT NULL OR si.id IS NOT NULL OR sc.id IS NOT NULL OR u.location_id = s.location_id)
+	`, usersTable, followersTable, interestsTable, user,
+		q.bind(constants.RecommendationSecondDegreeWeight), q.bind(constants.RecommendationSharedInterestWeight),
+		q.bind(constants.RecommendationSameLocationWeight), q.bind(constants.RecommendationPopularityWeight),
+		interestsCatalogTable, q.bind(constants.RecommendationSharedCategoryWeight))
+}
+
+// GetRecommendations reads the precomputed recommendations of the user, falling back to computing them
+// for the users that were never refreshed, like the new ones. The precomputed ones are checked again
+// against the follows and blocks, since they may have changed after the refresh.
+// It doesn't count the impression made by the listing being paginated, identified by the time it started,
+// so the scores don't change between its pages
+func (postDB *UsersPostgresDB) GetRecommendations(userId uuid.UUID, page model.PageRequest) ([]model.RecommendationRecord, *model.Cursor, error) {
+	var refreshed bool
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE user_id = $1)`, recommendationRefreshesTable)
+	if err := postDB.db.Get(&refreshed, query, userId); err != nil {
+		return nil, nil, fmt.Errorf("error checking if the recommendations were refreshed: %w", err)
+	}
+
+	q := queryBuilder{}
+	user := q.bind(userId)
+
+	source := fmt.Sprintf(`(%s)`, recommendationCandidatesQuery(&q, user))
+	if refreshed {
+		source = fmt.Sprintf(`(
+			SELECT r.recommended_id AS id, r.followed_by_following, r.shared_interests, r.shared_categories, r.same_location, r.score
+			FROM %[1]s r
+			WHERE r.user_id = %[2]s
+			AND NOT EXISTS (
+				SELECT 1
+				FROM %[3]s f
+				WHERE f.follower_id = %[2]s AND f.following_id = r.recommended_id
+			)
+		)`, recommendationsTable, user, followersTable)
+	}
+
+	listedAt := page.ListedAt()
+	timestamp := q.bind(listedAt)
+	after := page.After()
+	query = fmt.Sprintf(`
+		SELECT *
+		FROM (
+			SELECT u.*, c.followed_by_following, c.shared_interests, c.shared_categories, c.same_location,
+				(c.score / (1 + %[4]s::float8 * GREATEST(COALESCE(i.impressions, 0) - (CASE WHEN i.listed_at = %[6]s THEN 1 ELSE 0 END) - %[5]s, 0)))::float8 AS score
+			FROM %[1]s c
+			JOIN %[7]s u ON u.id = c.id
+			LEFT JOIN %[3]s i ON i.user_id = %[2]s AND i.shown_id = u.id
+			WHERE u.created_at < %[6]s
+			AND u.blocked IS NOT TRUE
+			AND NOT EXISTS (
+				SELECT 1
+				FROM %[8]s d
+				WHERE d.user_id = %[2]s AND d.dismissed_id = u.id
+				AND (d.expires_at IS NULL OR d.expires_at > now())
+			)
+		) r
+		WHERE (r.score, r.created_at, r.id) < (%[9]s, %[10]s, %[11]s)
+		ORDER BY r.score DESC, r.created_at DESC, r.id DESC
+		OFFSET %[12]s
+		LIMIT %[13]s
+	`, source, user, impressionsTable,
+		q.bind(constants.RecommendationImpressionPenalty), q.bind(constants.RecommendationFreeImpressions), timestamp,
+		usersTable, dismissalsTable,
+		q.bind(after.Score), q.bind(after.CreatedAt), q.bind(after.Id), q.bind(page.Offset()), q.bind(page.Limit+1))
+
+	var records []recommendationRecord
+	if err := postDB.db.Select(&records, query, q.args...); err != nil {
+		return nil, nil, fmt.Errorf("error getting recommendations: %w", err)
+	}
+
+	records, next := paginate(records, page.Limit, recommendationCursor(listedAt))
+	users := make([]model.UserRecord, len(records))
+	for i, record := range records {
+		users[i] = record.UserRecord
+	}
+	if err := postDB.attachCatalogNames(users); err != nil {
+		return nil, nil, fmt.Errorf("error getting interests for users: %w", err)
+	}
+
+	recommendations := make([]model.RecommendationRecord, len(records))
+	for i, record := range records {
+		recommendations[i] = model.RecommendationRecord{
+			User:                users[i],
+			FollowedByFollowing: record.FollowedByFollowing,
+			SharedInterests:     record.SharedInterests,
+			SharedCategories:    record.SharedCategories,
+			SameLocation:        record.SameLocation,
+		}
+	}
+
+	return recommendations, next, nil
+}
+
+func (postDB *UsersPostgresDB) RefreshRecommendations(userId uuid.UUID, limit int) error {
+	tx, err := postDB.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE user_id = $1`, recommendationsTable)
+	if _, err := tx.Exec(query, userId); err != nil {
+		return fmt.Errorf("error deleting old recommendations: %w", err)
+	}
+
+	q := queryBuilder{}
+	user := q.bind(userId)
+	query = fmt.Sprintf(`
+		INSERT INTO %s (user_id, recommended_id, followed_by_following, shared_interests, shared_categories, same_location, score)
+		SELECT %s::uuid, c.id, c.followed_by_following, c.shared_interests, c.shared_categories, c.same_location, c.score
+		FROM (%s) c
+		ORDER BY c.score DESC
+		LIMIT %s
+	`, recommendationsTable, user, recommendationCandidatesQuery(&q, user), q.bind(limit))
+	if _, err := tx.Exec(query, q.args...); err != nil {
+		return fmt.Errorf("error storing recommendations: %w", err)
+	}
+
+	query = fmt.Sprintf(`
+		INSERT INTO %s (user_id)
+		VALUES ($1)
+		ON CONFLICT (user_id) DO UPDATE SET refreshed_at = now()
+	`, recommendationRefreshesTable)
+	if _, err := tx.Exec(query, userId); err != nil {
+		return fmt.Errorf("error storing recommendations refresh: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing recommendations: %w", err)
+	}
+	return nil
+}
+
+func (postDB *UsersPostgresDB) GetUsersWithStaleRecommendations(refreshedBefore time.Time, limit int) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	query := fmt.Sprintf(`
+		SELECT u.id
+		FROM %s u
+		LEFT JOIN %s r ON r.user_id = u.id
+		WHERE u.blocked IS NOT TRUE
+		AND (r.refreshed_at IS NULL OR r.refreshed_at < $1)
+		ORDER BY r.refreshed_at NULLS FIRST, u.id
+		LIMIT $2
+	`, usersTable, recommendationRefreshesTable)
+
+	if err := postDB.db.Select(&ids, query, refreshedBefore, limit); err != nil {
+		return nil, fmt.Errorf("error getting users with stale recommendations: %w", err)
+	}
+	return ids, nil
+}
+
+func (postDB *UsersPostgresDB) DismissRecommendation(userId uuid.UUID, dismissedId uuid.UUID, expiresAt *time.Time) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (user_id, dismissed_id, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, dismissed_id) DO UPDATE SET created_at = now(), expires_at = EXCLUDED.expires_at
+	`, dismissalsTable)
+
+	if _, err := postDB.db.Exec(query, userId, dismissedId, expiresAt); err != nil {
+		return fmt.Errorf("error dismissing recommendation: %w", err)
+	}
+	return nil
+}
+
+func (postDB *UsersPostgresDB) RecordRecommendationImpressions(userId uuid.UUID, shownIds []uuid.UUID, listedAt time.Time) error {
+	if len(shownIds) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %[1]s AS i (user_id, shown_id, listed_at)
+		SELECT $1, shown_id, $3 FROM UNNEST($2::uuid[]) AS shown_id
+		ON CONFLICT (user_id, shown_id) DO UPDATE SET impressions = i.impressions + 1, listed_at = $3, last_shown_at = now()
+		WHERE i.listed_at <> $3
+	`, impressionsTable)
+
+	if _, err := postDB.db.Exec(query, userId, pq.Array(shownIds), listedAt); err != nil {
+		return fmt.Errorf("error recording recommendation impressions: %w", err)
+	}
+	return nil
+}
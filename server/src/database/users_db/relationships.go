@@ -0,0 +1,90 @@
+package users_db
+
+import (
+	"fmt"
+	"users-service/src/model"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+func (postDB *UsersPostgresDB) GetFollowersAmong(followingId uuid.UUID, userIds []uuid.UUID) (map[uuid.UUID]bool, error) {
+	followers := make(map[uuid.UUID]bool, len(userIds))
+	if len(userIds) == 0 {
+		return followers, nil
+	}
+
+	var followerIds []uuid.UUID
+	query := fmt.Sprintf(`
+		SELECT follower_id
+		FROM %s
+		WHERE following_id = $1
+		AND follower_id = ANY($2)
+	`, followersTable)
+
+	if err := postDB.db.Select(&followerIds, query, followingId, pq.Array(userIds)); err != nil {
+		return nil, fmt.Errorf("error getting followers among users: %w", err)
+	}
+
+	for _, id := range followerIds {
+		followers[id] = true
+	}
+	return followers, nil
+}
+
+func (postDB *UsersPostgresDB) GetMutualCounts(userId uuid.UUID, targetIds []uuid.UUID) (map[uuid.UUID]int, error) {
+	counts := make(map[uuid.UUID]int, len(targetIds))
+	if len(targetIds) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		Id    uuid.UUID `db:"id"`
+		Count int       `db:"count"`
+	}
+	query := fmt.Sprintf(`
+		SELECT target.following_id AS id, COUNT(*) AS count
+		FROM %[1]s own
+		JOIN %[1]s target ON target.follower_id = own.following_id
+		WHERE own.follower_id = $1
+		AND target.following_id = ANY($2)
+		GROUP BY target.following_id
+	`, followersTable)
+
+	if err := postDB.db.Select(&rows, query, userId, pq.Array(targetIds)); err != nil {
+		return nil, fmt.Errorf("error getting mutual counts: %w", err)
+	}
+
+	for _, row := range rows {
+		counts[row.Id] = row.Count
+	}
+	return counts, nil
+}
+
+func (postDB *UsersPostgresDB) GetMutuals(userId uuid.UUID, targetId uuid.UUID, page model.PageRequest) ([]model.UserRecord, *model.Cursor, error) {
+	var mutuals []followRecord
+	query := fmt.Sprintf(`
+		SELECT u.*, target.created_at AS followed_at
+		FROM %[1]s u
+		JOIN %[2]s own ON own.following_id = u.id AND own.follower_id = $1
+		JOIN %[2]s target ON target.follower_id = u.id AND target.following_id = $2
+		WHERE (target.created_at, u.id) < ($3, $4)
+		ORDER BY target.created_at DESC, u.id DESC
+		OFFSET $5
+		LIMIT $6
+	`, usersTable, followersTable)
+
+	after := page.After()
+	err := postDB.db.Select(&mutuals, query, userId, targetId, after.CreatedAt, after.Id, page.Offset(), page.Limit+1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting mutuals: %w", err)
+	}
+
+	mutuals, next := paginate(mutuals, page.Limit, followCursor)
+	users := followRecordsToUsers(mutuals)
+	if err := postDB.attachCatalogNames(users); err != nil {
+		return nil, nil, fmt.Errorf("error getting interests for users: %w", err)
+	}
+
+	return users, next, nil
+}
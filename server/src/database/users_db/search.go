@@ -0,0 +1,147 @@
+package users_db
+
+import (
+	"fmt"
+	"strings"
+	"users-service/src/model"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// searchScoreExpression ranks the users in tiers: exact username, username prefix, username containing the text,
+// name containing the text and full text match. Users that only match by trigram similarity come last,
+// scored by how similar they are, which is always lower than 1
+func searchScoreExpression(text, pattern string) string {
+	return fmt.Sprintf(`
+	(CASE
+		WHEN LOWER(u.username) = LOWER(%[1]s::text) THEN 5
+		WHEN u.username ILIKE (%[2]s::text || '%%') THEN 4
+		WHEN u.username ILIKE ('%%' || %[2]s::text || '%%') THEN 3
+		WHEN (u.first_name || ' ' || u.last_name) ILIKE ('%%' || %[2]s::text || '%%') THEN 2
+		WHEN to_tsvector('simple', u.username || ' ' || u.first_name || ' ' || u.last_name) @@ plainto_tsquery('simple', %[1]s::text) THEN 1
+		ELSE GREATEST(similarity(u.username, %[1]s::text), word_similarity(%[1]s::text, u.first_name || ' ' || u.last_name))
+	END)::float8`, text, pattern)
+}
+
+func searchMatchCondition(text, pattern string) string {
+	return fmt.Sprintf(`
+	(
+		u.username ILIKE ('%%' || %[2]s::text || '%%')
+		OR (u.first_name || ' ' || u.last_name) ILIKE ('%%' || %[2]s::text || '%%')
+		OR to_tsvector('simple', u.username || ' ' || u.first_name || ' ' || u.last_name) @@ plainto_tsquery('simple', %[1]s::text)
+		OR u.username %% %[1]s::text
+		OR %[1]s::text <%% (u.first_name || ' ' || u.last_name)
+	)`, text, pattern)
+}
+
+// createSearchIndexes enables the trigram extension and creates the indexes used by the search and its filters
+func createSearchIndexes(db *sqlx.DB) error {
+	schema := fmt.Sprintf(`
+		CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+		CREATE INDEX IF NOT EXISTS idx_users_username_trgm ON %[1]s USING GIN (username gin_trgm_ops);
+		CREATE INDEX IF NOT EXISTS idx_users_full_name_trgm ON %[1]s USING GIN ((first_name || ' ' || last_name) gin_trgm_ops);
+		CREATE INDEX IF NOT EXISTS idx_users_search_vector ON %[1]s USING GIN (to_tsvector('simple', username || ' ' || first_name || ' ' || last_name));
+		CREATE INDEX IF NOT EXISTS idx_users_username_prefix ON %[1]s (LOWER(username) text_pattern_ops);
+		CREATE INDEX IF NOT EXISTS idx_users_location_id ON %[1]s (location_id);
+		CREATE INDEX IF NOT EXISTS idx_user_interests_interest_id ON %[2]s (interest_id);
+	`, usersTable, interestsTable)
+
+	_, err := db.Exec(schema)
+	return err
+}
+
+// escapeLikePattern escapes the wildcards of a text so it is matched literally by LIKE and ILIKE
+func escapeLikePattern(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(text)
+}
+
+// queryBuilder accumulates the conditions of a query built at runtime and the arguments they are bound to
+type queryBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+// bind adds an argument to the query and returns its placeholder
+func (q *queryBuilder) bind(value interface{}) string {
+	q.args = append(q.args, value)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+func (postDB *UsersPostgresDB) SearchUsers(text string, filters model.UserSearchFilters, page model.PageRequest) ([]model.UserRecord, *model.Cursor, error) {
+	q := queryBuilder{}
+	q.conditions = append(q.conditions, "u.created_at < "+q.bind(page.Timestamp))
+
+	// without text the users are just browsed by the filters, the newest ones first
+	score := "0::float8"
+	if text != "" {
+		textArg, patternArg := q.bind(text), q.bind(escapeLikePattern(text))
+		score = searchScoreExpression(textArg, patternArg)
+		q.conditions = append(q.conditions, searchMatchCondition(textArg, patternArg))
+	}
+	if filters.LocationId != nil {
+		q.conditions = append(q.conditions, fmt.Sprintf("u.location_id IN (%s)", locationSubtreeQuery(q.bind(*filters.LocationId))))
+	}
+	if len(filters.InterestIds) > 0 {
+		q.conditions = append(q.conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM %s ui WHERE ui.user_id = u.id AND ui.interest_id = ANY(%s))",
+			interestsTable, q.bind(pq.Array(filters.InterestIds))))
+	}
+	if len(filters.CategoryIds) > 0 {
+		q.conditions = append(q.conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM %s ui JOIN %s i ON i.id = ui.interest_id WHERE ui.user_id = u.id AND i.category_id = ANY(%s))",
+			interestsTable, interestsCatalogTable, q.bind(pq.Array(filters.CategoryIds))))
+	}
+
+	after := page.After()
+	query := fmt.Sprintf(`
+		SELECT *
+		FROM (
+			SELECT u.*, %s AS score
+			FROM users u
+			WHERE %s
+		) s
+		WHERE (s.score, s.created_at, s.id) < (%s, %s, %s)
+		ORDER BY s.score DESC, s.created_at DESC, s.id DESC
+		OFFSET %s
+		LIMIT %s
+	`, score, strings.Join(q.conditions, " AND "),
+		q.bind(after.Score), q.bind(after.CreatedAt), q.bind(after.Id), q.bind(page.Offset()), q.bind(page.Limit+1))
+
+	var records []scoredRecord
+	if err := postDB.db.Select(&records, query, q.args...); err != nil {
+		return nil, nil, fmt.Errorf("error searching users: %w", err)
+	}
+
+	records, next := paginate(records, page.Limit, scoredCursor)
+	users := scoredRecordsToUsers(records)
+	if err := postDB.attachCatalogNames(users); err != nil {
+		return nil, nil, fmt.Errorf("error getting interests for users: %w", err)
+	}
+
+	return users, next, nil
+}
+
+func (postDB *UsersPostgresDB) GetUsernameSuggestions(userId uuid.UUID, prefix string, limit int) ([]model.UserSuggestion, error) {
+	suggestions := []model.UserSuggestion{}
+	query := fmt.Sprintf(`
+		SELECT u.id, u.username, u.first_name || ' ' || u.last_name AS name, COALESCE(u.picture_path, '') AS picture_path
+		FROM %s u
+		LEFT JOIN %s f ON f.follower_id = $1 AND f.following_id = u.id
+		WHERE LOWER(u.username) LIKE $2
+		AND u.id <> $1
+		AND u.blocked IS NOT TRUE
+		ORDER BY (f.following_id IS NOT NULL) DESC, (LOWER(u.username) = $3) DESC, u.followers_count DESC, LOWER(u.username)
+		LIMIT $4
+	`, usersTable, followersTable)
+
+	prefix = strings.ToLower(prefix)
+	err := postDB.db.Select(&suggestions, query, userId, escapeLikePattern(prefix)+"%", prefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error getting username suggestions: %w", err)
+	}
+
+	return suggestions, nil
+}
@@ -0,0 +1,144 @@
+package users_db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"users-service/src/constants"
+	"users-service/src/database"
+	"users-service/src/model"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const serviceClientsTable = "service_clients"
+
+// createServiceClientsTable creates the table with the services that can call the internal endpoints,
+// the revoked ones are kept so their tokens can be rejected
+func createServiceClientsTable(db *sqlx.DB) error {
+	schema := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %[1]s (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			name VARCHAR(%[2]d) NOT NULL UNIQUE,
+			scopes TEXT[] NOT NULL DEFAULT '{}',
+			secret_hash TEXT NOT NULL,
+			previous_secret_hash TEXT NOT NULL DEFAULT '',
+			previous_secret_expires_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			rotated_at TIMESTAMPTZ,
+			revoked_at TIMESTAMPTZ
+		);
+	`, serviceClientsTable, constants.MaxServiceClientNameLength)
+
+	_, err := db.Exec(schema)
+	return err
+}
+
+// serviceClientRow is a service client as stored, the scopes need pq to be scanned
+type serviceClientRow struct {
+	Id                      uuid.UUID      `db:"id"`
+	Name                    string         `db:"name"`
+	Scopes                  pq.StringArray `db:"scopes"`
+	SecretHash              string         `db:"secret_hash"`
+	PreviousSecretHash      string         `db:"previous_secret_hash"`
+	PreviousSecretExpiresAt *time.Time     `db:"previous_secret_expires_at"`
+	CreatedAt               time.Time      `db:"created_at"`
+	RotatedAt               *time.Time     `db:"rotated_at"`
+	RevokedAt               *time.Time     `db:"revoked_at"`
+}
+
+func (row serviceClientRow) toRecord() model.ServiceClientRecord {
+	return model.ServiceClientRecord{
+		Id:                      row.Id,
+		Name:                    row.Name,
+		Scopes:                  []string(row.Scopes),
+		SecretHash:              row.SecretHash,
+		PreviousSecretHash:      row.PreviousSecretHash,
+		PreviousSecretExpiresAt: row.PreviousSecretExpiresAt,
+		CreatedAt:               row.CreatedAt,
+		RotatedAt:               row.RotatedAt,
+		RevokedAt:               row.RevokedAt,
+	}
+}
+
+func (postDB *UsersPostgresDB) CreateServiceClient(name string, scopes []string, secretHash string) (model.ServiceClientRecord, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (name, scopes, secret_hash)
+		VALUES ($1, $2, $3)
+		RETURNING *`, serviceClientsTable)
+
+	var row serviceClientRow
+	if err := postDB.db.Get(&row, query, name, pq.Array(scopes), secretHash); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return model.ServiceClientRecord{}, database.ErrKeyAlreadyExists
+		}
+		return model.ServiceClientRecord{}, fmt.Errorf("error creating service client: %w", err)
+	}
+	return row.toRecord(), nil
+}
+
+func (postDB *UsersPostgresDB) GetServiceClient(id uuid.UUID) (model.ServiceClientRecord, error) {
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE id = $1`, serviceClientsTable)
+
+	var row serviceClientRow
+	if err := postDB.db.Get(&row, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ServiceClientRecord{}, database.ErrKeyNotFound
+		}
+		return model.ServiceClientRecord{}, fmt.Errorf("error getting service client: %w", err)
+	}
+	return row.toRecord(), nil
+}
+
+func (postDB *UsersPostgresDB) GetServiceClients() ([]model.ServiceClientRecord, error) {
+	query := fmt.Sprintf(`SELECT * FROM %s ORDER BY created_at, name`, serviceClientsTable)
+
+	rows := []serviceClientRow{}
+	if err := postDB.db.Select(&rows, query); err != nil {
+		return nil, fmt.Errorf("error getting service clients: %w", err)
+	}
+
+	clients := make([]model.ServiceClientRecord, len(rows))
+	for i, row := range rows {
+		clients[i] = row.toRecord()
+	}
+	return clients, nil
+}
+
+func (postDB *UsersPostgresDB) RotateServiceClientSecret(id uuid.UUID, secretHash string, previousExpiresAt time.Time) (model.ServiceClientRecord, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET previous_secret_hash = secret_hash, previous_secret_expires_at = $3, secret_hash = $2, rotated_at = now()
+		WHERE id = $1 AND revoked_at IS NULL
+		RETURNING *`, serviceClientsTable)
+
+	var row serviceClientRow
+	if err := postDB.db.Get(&row, query, id, secretHash, previousExpiresAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ServiceClientRecord{}, database.ErrKeyNotFound
+		}
+		return model.ServiceClientRecord{}, fmt.Errorf("error rotating service client secret: %w", err)
+	}
+	return row.toRecord(), nil
+}
+
+func (postDB *UsersPostgresDB) RevokeServiceClient(id uuid.UUID) error {
+	query := fmt.Sprintf(`UPDATE %s SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`, serviceClientsTable)
+
+	result, err := postDB.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("error revoking service client: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error revoking service client: %w", err)
+	}
+	if affected == 0 {
+		return database.ErrKeyNotFound
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package users_db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"users-service/src/constants"
+	"users-service/src/database"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const usernameHistoryTable = "username_history"
+
+// createUsernameHistoryTable creates the table with the usernames the users left, each one is held for them
+// until reserved_until
+func createUsernameHistoryTable(db *sqlx.DB) error {
+	schema := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %[1]s (
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			username VARCHAR(%[2]d) NOT NULL,
+			released_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			reserved_until TIMESTAMPTZ NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_username_history_username ON %[1]s (LOWER(username), reserved_until);
+		CREATE INDEX IF NOT EXISTS idx_username_history_user_id ON %[1]s (user_id, released_at);
+	`, usernameHistoryTable, constants.MaxUsernameLength)
+
+	_, err := db.Exec(schema)
+	return err
+}
+
+// recordUsernameChange keeps the previous username of the user in the history if it changed,
+// changing only its case doesn't release it
+func recordUsernameChange(tx *sqlx.Tx, userId uuid.UUID, previous string, current string) error {
+	if strings.EqualFold(previous, current) {
+		return nil
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, username, reserved_until) VALUES ($1, $2, $3)`, usernameHistoryTable)
+	if _, err := tx.Exec(query, userId, previous, time.Now().Add(constants.UsernameHoldPeriod)); err != nil {
+		return fmt.Errorf("error recording username change: %w", err)
+	}
+	return nil
+}
+
+// lockUsername locks the user until the transaction ends and returns its username
+func lockUsername(tx *sqlx.Tx, userId uuid.UUID) (string, error) {
+	var username string
+	if err := tx.Get(&username, `SELECT username FROM users WHERE id = $1 FOR UPDATE`, userId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", database.ErrKeyNotFound
+		}
+		return "", fmt.Errorf("error locking user: %w", err)
+	}
+	return username, nil
+}
+
+func (postDB *UsersPostgresDB) GetLastUsernameChange(userId uuid.UUID) (*time.Time, error) {
+	var lastChange *time.Time
+	query := fmt.Sprintf(`SELECT MAX(released_at) FROM %s WHERE user_id = $1`, usernameHistoryTable)
+	if err := postDB.db.Get(&lastChange, query, userId); err != nil {
+		return nil, fmt.Errorf("error getting last username change: %w", err)
+	}
+	return lastChange, nil
+}
+
+func (postDB *UsersPostgresDB) CheckIfUsernameIsReserved(username string, userId uuid.UUID) (bool, error) {
+	var reserved bool
+	query := fmt.Sprintf(`
+		SELECT EXISTS(
+			SELECT 1 FROM %s
+			WHERE LOWER(username) = LOWER($1) AND reserved_until > now() AND user_id <> $2
+		)`, usernameHistoryTable)
+	if err := postDB.db.Get(&reserved, query, username, userId); err != nil {
+		return false, fmt.Errorf("error checking if username is reserved: %w", err)
+	}
+	return reserved, nil
+}
+
+func (postDB *UsersPostgresDB) GetUsernameHolder(username string) (uuid.UUID, error) {
+	var userId uuid.UUID
+	query := fmt.Sprintf(`
+		SELECT user_id FROM %s
+		WHERE LOWER(username) = LOWER($1) AND reserved_until > now()
+		ORDER BY released_at DESC
+		LIMIT 1`, usernameHistoryTable)
+	if err := postDB.db.Get(&userId, query, username); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return uuid.Nil, database.ErrKeyNotFound
+		}
+		return uuid.Nil, fmt.Errorf("error getting username holder: %w", err)
+	}
+	return userId, nil
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"users-service/src/constants"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// LanguageMiddleware picks the supported language that best matches the Accept-Language header
+// and stores it in the context, falling back to the default language when none of them match
+func LanguageMiddleware() gin.HandlerFunc {
+	tags := make([]language.Tag, len(constants.SupportedLanguages))
+	for i, lang := range constants.SupportedLanguages {
+		tags[i] = language.MustParse(lang)
+	}
+	matcher := language.NewMatcher(tags)
+
+	return func(c *gin.Context) {
+		lang := constants.DefaultLanguage
+		if header := c.GetHeader("Accept-Language"); header != "" {
+			if _, index, confidence := matcher.Match(parseAcceptLanguage(header)...); confidence != language.No {
+				lang = constants.SupportedLanguages[index]
+			}
+		}
+
+		c.Set("language", lang)
+		c.Header("Content-Language", lang)
+		c.Header("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
+// parseAcceptLanguage returns the languages of the header by preference, a malformed header counts as empty
+func parseAcceptLanguage(header string) []language.Tag {
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil {
+		return nil
+	}
+	return tags
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"users-service/src/app_errors"
+	"users-service/src/auth"
+	"users-service/src/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ServiceAuthMiddleware only lets through the requests with a token issued to a service client that was not revoked,
+// the users tokens are rejected
+func ServiceAuthMiddleware(service *service.User) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bearerToken := strings.Split(c.GetHeader("Authorization"), " ")
+		if len(bearerToken) != 2 || strings.ToLower(bearerToken[0]) != "bearer" {
+			slog.Error("Invalid service authorization header")
+			err := app_errors.NewAppError(http.StatusUnauthorized, "Unauthorized", fmt.Errorf("invalid authorization header"))
+			_ = c.AbortWithError(err.Code, err)
+			return
+		}
+
+		claims, err := auth.ValidateServiceToken(bearerToken[1])
+		if err != nil {
+			slog.Error("Invalid service token")
+			err := app_errors.NewAppError(http.StatusUnauthorized, "Unauthorized", err)
+			_ = c.AbortWithError(err.Code, err)
+			return
+		}
+
+		clientId, err := uuid.Parse(claims.ClientId)
+		if err != nil {
+			err := app_errors.NewAppError(http.StatusUnauthorized, "Unauthorized", fmt.Errorf("invalid client id in token: %w", err))
+			_ = c.AbortWithError(err.Code, err)
+			return
+		}
+
+		isActive, err := service.CheckIfServiceClientIsActive(clientId)
+		if err != nil {
+			err := app_errors.NewAppError(http.StatusInternalServerError, "Error checking the service client", err)
+			_ = c.AbortWithError(err.Code, err)
+			return
+		}
+		if !isActive {
+			err := app_errors.NewAppError(http.StatusUnauthorized, "Unauthorized", fmt.Errorf("the service client %s was revoked", clientId))
+			_ = c.AbortWithError(err.Code, err)
+			return
+		}
+
+		c.Set("service_client_id", claims.ClientId)
+		c.Set("service_scopes", claims.Scopes())
+
+		c.Next()
+	}
+}
+
+// RequireScope only lets through the service clients whose token has the scope, it goes after ServiceAuthMiddleware
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !slices.Contains(c.GetStringSlice("service_scopes"), scope) {
+			err := app_errors.NewAppError(http.StatusForbidden, "Forbidden", fmt.Errorf("the token lacks the scope %s", scope))
+			_ = c.AbortWithError(err.Code, err)
+			return
+		}
+
+		c.Next()
+	}
+}
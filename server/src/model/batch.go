@@ -0,0 +1,32 @@
+package model
+
+import "github.com/google/uuid"
+
+// UserBatchRequest asks for the profiles of several users at once, by id or by username
+type UserBatchRequest struct {
+	Ids       []string `json:"ids"`
+	Usernames []string `json:"usernames"`
+}
+
+// CompactUserProfile is the public profile of a user without the fields that have to be computed
+type CompactUserProfile struct {
+	Id          uuid.UUID `json:"id" db:"id"`
+	UserName    string    `json:"username" db:"username"`
+	FirstName   string    `json:"first_name" db:"first_name"`
+	LastName    string    `json:"last_name" db:"last_name"`
+	PicturePath string    `json:"picture_path" db:"picture_path"`
+}
+
+// BatchUser is a user of a batch, with how it is related to the session user
+type BatchUser struct {
+	CompactUserProfile `json:"profile"`
+	Follows            bool `json:"follows" db:"follows"`
+	FollowedBy         bool `json:"followed_by" db:"followed_by"`
+}
+
+// UserBatchResponse has the users of a batch keyed by id, and the ids of the requested usernames.
+// The ids and usernames that were not found are null
+type UserBatchResponse struct {
+	Users     map[string]*BatchUser  `json:"users"`
+	Usernames map[string]*uuid.UUID  `json:"usernames"`
+}
@@ -0,0 +1,82 @@
+package model
+
+// Catalog identifies one of the catalogs of options users pick from
+type Catalog string
+
+const (
+	InterestsCatalog          Catalog = "interests"
+	LocationsCatalog          Catalog = "locations"
+	InterestCategoriesCatalog Catalog = "interest_categories"
+)
+
+// IsValid tells if the catalog is one of the known ones
+func (c Catalog) IsValid() bool {
+	return c == InterestsCatalog || c == LocationsCatalog || c == InterestCategoriesCatalog
+}
+
+// HasMetadata tells if the entries of the catalog have an icon and a description
+func (c Catalog) HasMetadata() bool {
+	return c == InterestsCatalog || c == InterestCategoriesCatalog
+}
+
+// Kinds of the locations, from the widest to the narrowest
+const (
+	CountryLocation  = "country"
+	ProvinceLocation = "province"
+	CityLocation     = "city"
+)
+
+// CatalogEntry is an option of a catalog as stored in the database.
+// Deprecated entries are kept for the users that already have them, but can't be chosen anymore.
+// Only the locations have a parent, a kind and coordinates, the position orders the entries among their siblings.
+// The interests and their categories have an icon and a description, and the interests may belong to a category.
+// The translations are the names of the entry by language, the name is shown in the languages it lacks
+type CatalogEntry struct {
+	Id           int               `json:"id" db:"id"`
+	Name         string            `json:"name" db:"name"`
+	Position     int               `json:"position" db:"position"`
+	Deprecated   bool              `json:"deprecated" db:"deprecated"`
+	ParentId     *int              `json:"parent_id,omitempty" db:"parent_id"`
+	Kind         string            `json:"kind,omitempty" db:"kind"`
+	Latitude     *float64          `json:"latitude,omitempty" db:"latitude"`
+	Longitude    *float64          `json:"longitude,omitempty" db:"longitude"`
+	CategoryId   *int              `json:"category_id,omitempty" db:"category_id"`
+	Icon         string            `json:"icon,omitempty" db:"icon"`
+	Description  string            `json:"description,omitempty" db:"description"`
+	Translations map[string]string `json:"translations,omitempty" db:"-"`
+}
+
+// HasCoordinates tells if the distance to the entry can be measured
+func (e CatalogEntry) HasCoordinates() bool {
+	return e.Latitude != nil && e.Longitude != nil
+}
+
+// CreateCatalogEntryRequest is the body to add an entry to a catalog, only the interests and their categories
+// take an icon and a description, and only the interests a category
+type CreateCatalogEntryRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Icon        string `json:"icon"`
+	Description string `json:"description"`
+	CategoryId  *int   `json:"category_id"`
+}
+
+// UpdateCatalogEntryRequest is the body to edit and deprecate an entry, the fields not sent are kept
+type UpdateCatalogEntryRequest struct {
+	Name        *string `json:"name"`
+	Deprecated  *bool   `json:"deprecated"`
+	Icon        *string `json:"icon"`
+	Description *string `json:"description"`
+	CategoryId  *int    `json:"category_id"`
+}
+
+// SetCatalogTranslationRequest is the body to set the name of an entry in a language
+type SetCatalogTranslationRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// ReorderCatalogRequest lists every entry id with the given parent in the order they must be shown,
+// without a parent they are the top level ones
+type ReorderCatalogRequest struct {
+	ParentId *int  `json:"parent_id"`
+	Ids      []int `json:"ids" binding:"required"`
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cursor is the position of the last item of a page, it is sent to the clients as an opaque string
+// Score is only used by the lists that are not sorted just by date, like search or recommendations,
+// and ListedAt by the lists whose scores change while they are paginated, like recommendations
+type Cursor struct {
+	Score     float64   `json:"s,omitempty"`
+	CreatedAt time.Time `json:"t"`
+	Id        uuid.UUID `json:"i"`
+	ListedAt  time.Time `json:"l,omitempty"`
+}
+
+// Encode returns the opaque representation of the cursor
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor previously returned by Encode
+func DecodeCursor(encoded string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("error decoding cursor: %w", err)
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return Cursor{}, fmt.Errorf("error parsing cursor: %w", err)
+	}
+
+	if cursor.CreatedAt.IsZero() || cursor.Id == uuid.Nil {
+		return Cursor{}, fmt.Errorf("cursor is missing its position")
+	}
+	return cursor, nil
+}
+
+// PageRequest holds the pagination params of a list request.
+// Clients can send the cursor of the previous page or the legacy time and skip params
+type PageRequest struct {
+	Cursor    *Cursor
+	Timestamp time.Time
+	Skip      int
+	Limit     int
+}
+
+// After returns the position from which the page starts.
+// Without a cursor it is the requested time, so every item created before it is included
+func (p PageRequest) After() Cursor {
+	if p.Cursor != nil {
+		return *p.Cursor
+	}
+	return Cursor{
+		Score:     math.MaxFloat64,
+		CreatedAt: p.Timestamp,
+		Id:        uuid.Nil,
+	}
+}
+
+// ListedAt returns the time the listing being paginated started, which the cursor carries after its first page
+func (p PageRequest) ListedAt() time.Time {
+	if p.Cursor != nil && !p.Cursor.ListedAt.IsZero() {
+		return p.Cursor.ListedAt
+	}
+	return p.Timestamp
+}
+
+// Offset returns the amount of items to skip, it is only used by the legacy params
+func (p PageRequest) Offset() int {
+	if p.Cursor != nil {
+		return 0
+	}
+	return p.Skip
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity is the account of an identity provider a user signs in with, identified by the provider
+// and its subject. The email is the one the provider verified when it was bound
+type UserIdentity struct {
+	UserId    uuid.UUID `json:"-" db:"user_id"`
+	Provider  string    `json:"provider" db:"provider"`
+	Subject   string    `json:"-" db:"subject"`
+	Email     string    `json:"email" db:"email"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// LinkIdentityRequest has the token of the identity provider account to link, with the metadata the resolver takes
+type LinkIdentityRequest struct {
+	Provider string          `json:"type" binding:"required"`
+	Metadata json.RawMessage `json:"metadata" binding:"required"`
+}
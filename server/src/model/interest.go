@@ -1,8 +1,19 @@
 package model
 
 type Interest struct {
-	Id       int    `json:"id"`
-	Interest string `json:"name"`
+	Id          int    `json:"id"`
+	Interest    string `json:"name"`
+	CategoryId  *int   `json:"category_id,omitempty"`
+	Icon        string `json:"icon,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// InterestCategory groups related interests
+type InterestCategory struct {
+	Id          int    `json:"id"`
+	Name        string `json:"name"`
+	Icon        string `json:"icon,omitempty"`
+	Description string `json:"description,omitempty"`
 }
 
 type UserInterest struct {
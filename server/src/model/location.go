@@ -3,4 +3,5 @@ package model
 type Location struct {
 	Id   int    `json:"id"`
 	Name string `json:"name"`
+	Kind string `json:"kind"`
 }
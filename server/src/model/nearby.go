@@ -0,0 +1,7 @@
+package model
+
+// NearbyUserRecord is a user along with how far its location is from the one of the user looking for it
+type NearbyUserRecord struct {
+	User       UserRecord
+	DistanceKm float64
+}
@@ -2,8 +2,9 @@ package model
 
 
 type Pagination struct {
-	NextOffset int `json:"next_offset,omitempty"`
-	Limit      int `json:"limit" binding:"required"`
+	NextOffset int    `json:"next_offset,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Limit      int    `json:"limit" binding:"required"`
 }
 
 type PaginationResponse[T any] struct {
@@ -11,17 +12,21 @@ type PaginationResponse[T any] struct {
 	Pagination Pagination `json:"pagination" binding:"required"`
 }
 
-func CreatePaginationResponse[T any](data []T, limit, skip int, hasMore bool) PaginationResponse[T] {
+// CreatePaginationResponse builds the response of a page, next is nil when there are no more items to fetch
+func CreatePaginationResponse[T any](data []T, page PageRequest, next *Cursor) PaginationResponse[T] {
 	response := PaginationResponse[T]{
 		Data: data,
 		Pagination: Pagination{
-			Limit: limit,
+			Limit: page.Limit,
 		},
 	}
 
-	if hasMore {
-		response.Pagination.NextOffset = skip + limit
+	if next != nil {
+		response.Pagination.NextCursor = next.Encode()
+		if page.Cursor == nil {
+			response.Pagination.NextOffset = page.Skip + page.Limit
+		}
 	}
 
 	return response
-}
\ No newline at end of file
+}
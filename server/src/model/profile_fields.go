@@ -0,0 +1,115 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Optional fields of the profile whose visibility each user chooses
+const (
+	ProfileFieldBio       = "bio"
+	ProfileFieldWebsite   = "website"
+	ProfileFieldBirthDate = "birth_date"
+	ProfileFieldPronouns  = "pronouns"
+	ProfileFieldBanner    = "banner_path"
+)
+
+const (
+	VisibilityPublic  = "public"
+	VisibilityPrivate = "private"
+)
+
+// defaultFieldVisibility is the visibility of the fields the user didn't choose one for,
+// the birth date is only shown to other users if asked for
+var defaultFieldVisibility = map[string]string{
+	ProfileFieldBio:       VisibilityPublic,
+	ProfileFieldWebsite:   VisibilityPublic,
+	ProfileFieldBirthDate: VisibilityPrivate,
+	ProfileFieldPronouns:  VisibilityPublic,
+	ProfileFieldBanner:    VisibilityPublic,
+}
+
+// BirthDateLayout is the format of the birth dates in the requests and responses
+const BirthDateLayout = "2006-01-02"
+
+// FieldVisibility maps the optional fields of a profile to their visibility, it is stored as JSON
+type FieldVisibility map[string]string
+
+// IsProfileField tells if the field is one of the optional fields of the profile
+func IsProfileField(field string) bool {
+	_, ok := defaultFieldVisibility[field]
+	return ok
+}
+
+// ProfileFields returns the optional fields of the profile
+func ProfileFields() []string {
+	fields := make([]string, 0, len(defaultFieldVisibility))
+	for field := range defaultFieldVisibility {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// IsPublic tells if the field is shown to other users
+func (v FieldVisibility) IsPublic(field string) bool {
+	if visibility, ok := v[field]; ok {
+		return visibility == VisibilityPublic
+	}
+	return defaultFieldVisibility[field] == VisibilityPublic
+}
+
+// WithDefaults returns the visibility of every optional field, including the ones the user didn't choose
+func (v FieldVisibility) WithDefaults() FieldVisibility {
+	visibility := make(FieldVisibility, len(defaultFieldVisibility))
+	for field, value := range defaultFieldVisibility {
+		visibility[field] = value
+	}
+	for field, value := range v {
+		visibility[field] = value
+	}
+	return visibility
+}
+
+// Patch returns the visibility with the given changes applied, the fields set to nil go back to their default
+func (v FieldVisibility) Patch(changes map[string]*string) FieldVisibility {
+	patched := make(FieldVisibility, len(v)+len(changes))
+	for field, value := range v {
+		patched[field] = value
+	}
+	for field, value := range changes {
+		if value == nil {
+			delete(patched, field)
+		} else {
+			patched[field] = *value
+		}
+	}
+	return patched
+}
+
+func (v FieldVisibility) Value() (driver.Value, error) {
+	if v == nil {
+		return "{}", nil
+	}
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
+
+func (v *FieldVisibility) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(value, v)
+	case string:
+		return json.Unmarshal([]byte(value), v)
+	default:
+		return fmt.Errorf("cannot scan %T into FieldVisibility", src)
+	}
+}
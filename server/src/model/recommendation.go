@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// RecommendationRecord is a user recommended to another one along with the signals it was recommended by
+type RecommendationRecord struct {
+	User                UserRecord
+	FollowedByFollowing int
+	SharedInterests     int
+	SharedCategories    int
+	SameLocation        bool
+}
+
+// DismissRecommendationRequest is the optional body of a dismissal, without expiration it is permanent
+type DismissRecommendationRequest struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+}
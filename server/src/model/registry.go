@@ -2,6 +2,7 @@ package model
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -20,6 +21,11 @@ type GoogleAuthMetadata struct {
 	FirebaseTokenId string `json:"firebase_token_id" validate:"required"`
 }
 
+// OIDCAuthMetadata has the ID token issued by an OpenID Connect provider
+type OIDCAuthMetadata struct {
+	IdToken string `json:"id_token" validate:"required"`
+}
+
 type ResolveWithProviderMetadata struct {
 	Token   string             `json:"access_token"`
 	Profile UserPrivateProfile `json:"profile"`
@@ -36,14 +42,16 @@ type UserPersonalInfoRequest struct {
 	UserName   string `json:"username" validate:"usernamevalidator"`
 	Password   string `json:"password" validate:"passwordvalidator"`
 	LocationId int    `json:"location" validate:"locationvalidator"`
+	BirthDate  string `json:"birth_date" validate:"omitempty,birthdatevalidator"`
 }
 
 type UserPersonalInfoRecord struct {
-	FirstName string `json:"first_name" db:"first_name" validate:"required"`
-	LastName  string `json:"last_name" db:"last_name" validate:"required"`
-	UserName  string `json:"username" db:"username" validate:"required"`
-	Password  string `json:"password" db:"password" validate:"required"`
-	Location  string `json:"location" db:"location" validate:"required"`
+	FirstName  string `json:"first_name" db:"first_name" validate:"required"`
+	LastName   string `json:"last_name" db:"last_name" validate:"required"`
+	UserName   string `json:"username" db:"username" validate:"required"`
+	Password   string `json:"password" db:"password" validate:"required"`
+	LocationId int    `json:"location" db:"location_id" validate:"required"`
+	BirthDate  *time.Time `json:"birth_date" db:"birth_date"`
 }
 
 type RegistryEntry struct {
@@ -51,6 +59,8 @@ type RegistryEntry struct {
 	Email         		string                 `json:"email" db:"email" validate:"required"`
 	EmailVerified 		bool                   `json:"email_verified" db:"email_verified" validate:"required"`
 	PersonalInfo  		UserPersonalInfoRecord `json:"personal_info" db:"personal_info" validate:"required"`
-	Interests     		[]string               `json:"interests" db:"interests" validate:"required"`
+	InterestIds   		[]int                  `json:"interests" db:"interests" validate:"required"`
 	IdentityProvider     string        			`json:"identity_provider" db:"identity_provider" validate:"required"`
+	IdentitySubject      string                 `json:"-" db:"identity_subject"`
+	HasLocation          bool                   `json:"-" db:"-"`
 }
@@ -0,0 +1,23 @@
+package model
+
+// SearchFiltersRequest holds the optional filters of the users search as sent in the request,
+// using the ids of the catalogs of locations, interests and interest categories
+type SearchFiltersRequest struct {
+	LocationId  *int
+	InterestIds []int
+	CategoryIds []int
+}
+
+// IsEmpty returns true if no filter was given
+func (f SearchFiltersRequest) IsEmpty() bool {
+	return f.LocationId == nil && len(f.InterestIds) == 0 && len(f.CategoryIds) == 0
+}
+
+// UserSearchFilters are the filters of the users search once checked against the catalogs.
+// Empty fields don't filter, a user matches the interests if it has any of them
+// and the categories if it has any interest in them
+type UserSearchFilters struct {
+	LocationId  *int
+	InterestIds []int
+	CategoryIds []int
+}
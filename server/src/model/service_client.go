@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ServiceClientRecord is another service of the platform that can call the internal endpoints allowed by its scopes.
+// Only the hashes of its secrets are stored, the previous one is accepted until PreviousSecretExpiresAt
+type ServiceClientRecord struct {
+	Id                      uuid.UUID
+	Name                    string
+	Scopes                  []string
+	SecretHash              string
+	PreviousSecretHash      string
+	PreviousSecretExpiresAt *time.Time
+	CreatedAt               time.Time
+	RotatedAt               *time.Time
+	RevokedAt               *time.Time
+}
+
+// CreateServiceClientRequest registers a new service client with the given scopes
+type CreateServiceClientRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required"`
+}
+
+// ServiceClient is a service client as shown to the admins, without its secrets
+type ServiceClient struct {
+	Id        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"created_at"`
+	RotatedAt *time.Time `json:"rotated_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
+
+// ServiceClientCredentials is a service client with its secret, it is only returned when the secret is generated
+type ServiceClientCredentials struct {
+	Client       ServiceClient `json:"client"`
+	ClientSecret string        `json:"client_secret"`
+}
+
+// ServiceTokenRequest asks for a token with the client credentials grant, it can be sent as JSON or as a form.
+// If no scope is asked for the token has every scope of the client
+type ServiceTokenRequest struct {
+	GrantType    string `json:"grant_type" form:"grant_type"`
+	ClientId     string `json:"client_id" form:"client_id"`
+	ClientSecret string `json:"client_secret" form:"client_secret"`
+	Scope        string `json:"scope" form:"scope"`
+}
+
+// ServiceTokenResponse is the token issued to a service client, it expires in ExpiresIn seconds
+type ServiceTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Scope       string `json:"scope"`
+}
@@ -1,16 +1,34 @@
 package model
 
 import (
+	"database/sql"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 // UserProfileResponse is a struct that represents a user profile in the HTTP response
+// FollowedBy tells if the user follows the session user and MutualCount how many of the users
+// the session user follows also follow it.
+// Highlights is only sent in the search results, with the matched parts of each field wrapped in <em> tags,
+// Reasons only in the recommendations, explaining why the user was recommended,
+// and DistanceKm only in the nearby users
 type UserProfileResponse struct {
-	OwnProfile bool        `json:"own_profile" binding:"required"`
-	Follows    bool        `json:"follows" binding:"required"`
-	Profile    interface{} `json:"profile" binding:"required"`
+	OwnProfile  bool              `json:"own_profile" binding:"required"`
+	Follows     bool              `json:"follows" binding:"required"`
+	FollowedBy  bool              `json:"followed_by"`
+	MutualCount int               `json:"mutual_count"`
+	Profile     interface{}       `json:"profile" binding:"required"`
+	Highlights  map[string]string `json:"highlights,omitempty"`
+	Reasons     []string          `json:"reasons,omitempty"`
+	DistanceKm  *float64          `json:"distance_km,omitempty"`
+}
+
+// RelationshipResponse describes how the session user and another user are related
+type RelationshipResponse struct {
+	Follows    bool `json:"follows"`
+	FollowedBy bool `json:"followed_by"`
 }
 
 type UserInformationResponse struct {
@@ -24,8 +42,14 @@ type UpdateUserPrivateProfile struct {
 	PicturePath string `json:"picture_path" db:"picture_path"`
 	FirstName   string `json:"first_name" binding:"required"`
 	LastName    string `json:"last_name" binding:"required"`
-	Location    string    `json:"location" binding:"required"`
-	Interests   []string  `json:"interests" binding:"required"`
+	LocationId  int       `json:"location" binding:"required"`
+	InterestIds []int     `json:"interests" binding:"required"`
+	Bio         string
+	Website     string
+	BirthDate   *time.Time
+	Pronouns    string
+	BannerPath  string
+	Visibility  FieldVisibility
 }
 
 type UpdateUserPrivateProfileRequest struct {
@@ -35,6 +59,13 @@ type UpdateUserPrivateProfileRequest struct {
     UserName   string `json:"username" validate:"usernamevalidator"`
 	Location    int    `json:"location" validate:"locationvalidator"`
 	Interests   []int  `json:"interests" validate:"interestsvalidator"`
+	Bio         string `json:"bio"`
+	Website     string `json:"website"`
+	BirthDate   string `json:"birth_date"`
+	Pronouns    string `json:"pronouns"`
+	BannerPath  string `json:"banner_path"`
+	// Visibility changes the visibility of the given fields, the rest keep theirs
+	Visibility  map[string]string `json:"visibility"`
 }
 
 type UpdateUserPrivateProfileData struct {
@@ -43,9 +74,57 @@ type UpdateUserPrivateProfileData struct {
 	LastName   string `json:"last_name" validate:"lastnamevalidator"`
 	Location    int    `json:"location" validate:"locationvalidator"`
 	Interests   []int  `json:"interests" validate:"interestsvalidator"`
+	Bio         string `json:"bio" validate:"omitempty,biovalidator"`
+	Website     string `json:"website" validate:"omitempty,websitevalidator"`
+	BirthDate   string `json:"birth_date" validate:"omitempty,birthdatevalidator"`
+	Pronouns    string `json:"pronouns" validate:"omitempty,pronounsvalidator"`
+	BannerPath  string `json:"banner_path"`
+	Visibility  map[string]string `json:"visibility" validate:"omitempty,visibilityvalidator"`
+}
+
+// UserProfilePatch is a JSON merge patch of the private profile, the fields that are not in it are nil.
+// The optional fields set to null in the patch are cleared, so they come as empty strings, and so do the
+// visibilities set to null, that go back to their default
+type UserProfilePatch struct {
+	UserName    *string
+	FirstName   *string
+	LastName    *string
+	PicturePath *string
+	LocationId  *int
+	InterestIds *[]int
+	Bio         *string
+	Website     *string
+	BirthDate   *string
+	Pronouns    *string
+	BannerPath  *string
+	Visibility  map[string]*string
+}
+
+// UserProfileChanges are the columns of a user that changed with a patch, the ones that didn't are nil
+type UserProfileChanges struct {
+	UserName    *string
+	FirstName   *string
+	LastName    *string
+	PicturePath *string
+	LocationId  *int
+	InterestIds []int
+	Bio         *string
+	Website     *string
+	BirthDate   *sql.NullTime
+	Pronouns    *string
+	BannerPath  *string
+	Visibility  FieldVisibility
+}
+
+// IsEmpty tells if nothing changed
+func (c UserProfileChanges) IsEmpty() bool {
+	return c.UserName == nil && c.FirstName == nil && c.LastName == nil && c.PicturePath == nil &&
+		c.LocationId == nil && c.InterestIds == nil && c.Bio == nil && c.Website == nil &&
+		c.BirthDate == nil && c.Pronouns == nil && c.BannerPath == nil && c.Visibility == nil
 }
 
-// UserPrivateProfile is a struct that represents a user in the HTTP response
+// UserPrivateProfile is a struct that represents a user in the HTTP response.
+// Version changes each time the profile is modified, it is sent as its ETag
 type UserPrivateProfile struct {
 	Id          uuid.UUID `json:"id" binding:"required"`
 	UserName    string    `json:"username" binding:"required"`
@@ -57,9 +136,27 @@ type UserPrivateProfile struct {
 	Interests   []string  `json:"interests" binding:"required"`
 	Followers   int       `json:"followers" binding:"required"`
 	Following   int       `json:"following" binding:"required"`
+	Bio         string    `json:"bio"`
+	Website     string    `json:"website"`
+	BirthDate   string    `json:"birth_date,omitempty"`
+	Pronouns    string    `json:"pronouns"`
+	BannerPath  string    `json:"banner_path"`
+	Visibility  FieldVisibility `json:"visibility"`
+	Version     int       `json:"version"`
 }
 
-// UserPrivateProfile is a struct that represents a user in the HTTP response
+// ETag returns the entity tag of the version of the profile
+func (p UserPrivateProfile) ETag() string {
+	return VersionETag(p.Version)
+}
+
+// VersionETag returns the entity tag of a version of a resource
+func VersionETag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// UserPublicProfile is a struct that represents a user in the HTTP response,
+// the optional fields are only sent when the user made them public
 type UserPublicProfile struct {
 	Id          uuid.UUID `json:"id" binding:"required"`
 	UserName    string    `json:"username" binding:"required"`
@@ -69,9 +166,15 @@ type UserPublicProfile struct {
 	Location    string    `json:"location" binding:"required"`
 	Followers   int       `json:"followers" binding:"required"`
 	Following   int       `json:"following" binding:"required"`
+	Bio         string    `json:"bio,omitempty"`
+	Website     string    `json:"website,omitempty"`
+	BirthDate   string    `json:"birth_date,omitempty"`
+	Pronouns    string    `json:"pronouns,omitempty"`
+	BannerPath  string    `json:"banner_path,omitempty"`
 }
 
 // UserRecord is a struct that represents a user in the database
+// The location and interests are stored as ids of the catalogs, Location and Interests hold their current names
 type UserRecord struct {
 	Id          uuid.UUID `json:"id" db:"id"`
 	UserName    string    `json:"username" db:"username"`
@@ -80,9 +183,20 @@ type UserRecord struct {
 	LastName    string    `json:"last_name" db:"last_name"`
 	Email       string    `json:"email" db:"email"`
 	Password    string    `json:"password" db:"password"`
-	Location    string    `json:"location" db:"location"`
-	Interests	[]string  `json:"interests" db:"interests"`
+	LocationId  int       `json:"location_id" db:"location_id"`
+	Location    string    `json:"location" db:"-"`
+	InterestIds []int     `json:"interest_ids" db:"-"`
+	Interests	[]string  `json:"interests" db:"-"`
 	Blocked     bool      `json:"blocked" db:"blocked"`
+	FollowersCount int    `json:"followers_count" db:"followers_count"`
+	FollowingCount int    `json:"following_count" db:"following_count"`
+	Bio         string    `json:"bio" db:"bio"`
+	Website     string    `json:"website" db:"website"`
+	BirthDate   *time.Time `json:"birth_date" db:"birth_date"`
+	Pronouns    string    `json:"pronouns" db:"pronouns"`
+	BannerPath  string    `json:"banner_path" db:"banner_path"`
+	Visibility  FieldVisibility `json:"visibility" db:"field_visibility"`
+	Version     int       `json:"version" db:"version"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 }
 
@@ -90,3 +204,11 @@ type UserLoginRequest struct {
 	Email    string `json:"email" validate:"required"`
 	Password string `json:"password" validate:"required"`
 }
+
+// UserSuggestion is the compact profile returned by the username autocomplete
+type UserSuggestion struct {
+	Id          uuid.UUID `json:"id" db:"id"`
+	UserName    string    `json:"username" db:"username"`
+	Name        string    `json:"name" db:"name"`
+	PicturePath string    `json:"picture_path" db:"picture_path"`
+}
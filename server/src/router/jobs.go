@@ -0,0 +1,27 @@
+package router
+
+import (
+	"log/slog"
+	"time"
+)
+
+// schedulePeriodicJob runs the job once and then every interval in the background.
+// A non positive interval disables the job
+func schedulePeriodicJob(name string, interval time.Duration, job func() error) {
+	if interval <= 0 {
+		slog.Info("periodic job disabled", slog.String("job", name))
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := job(); err != nil {
+				slog.Error("periodic job failed", slog.String("job", name), slog.String("error", err.Error()))
+			}
+			<-ticker.C
+		}
+	}()
+}
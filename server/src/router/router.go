@@ -1,13 +1,17 @@
 package router
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"testing"
+	"users-service/src/auth"
 	"users-service/src/config"
+	"users-service/src/constants"
 	"users-service/src/controller"
+	"users-service/src/database/catalog_db"
 	"users-service/src/database/registry_db"
 	"users-service/src/database/users_db"
 	"users-service/src/middleware"
@@ -110,52 +114,130 @@ func createDBConnection(cfg *config.Config) (*sqlx.DB, error) {
 	return db, nil
 }
 
-// Creates the databases for the users, interests and registry
-func createDatabases(cfg *config.Config) (users_db.UserDatabase, registry_db.RegistryDatabase, error) {
+// Creates the databases for the users, registry and the catalogs of interests and locations.
+// The catalogs go first since the users and registry reference them
+func createDatabases(cfg *config.Config) (users_db.UserDatabase, registry_db.RegistryDatabase, catalog_db.CatalogDatabase, error) {
 	var userDb users_db.UserDatabase
 	var registryDb registry_db.RegistryDatabase
+	var catalogDb catalog_db.CatalogDatabase
 	var err error
 
 	db, err := createDBConnection(cfg)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	test := false
-	if cfg.Environment == "testing" || testing.Testing() {
-		test = true
-	}
+	test := isTestEnvironment(cfg)
 
+	catalogDb, err = catalog_db.CreateCatalogPostgresDB(db, test)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to connect to catalog database: %w", err)
+	}
 	userDb, err = users_db.CreateUsersPostgresDB(db, test)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to connect to users database: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to connect to users database: %w", err)
 	}
 	registryDb, err = registry_db.CreateRegistryPostgresDB(db, test)
 
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to connect to registry database: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to connect to registry database: %w", err)
+	}
+	return userDb, registryDb, catalogDb, nil
+}
+
+// registerGoogleVerifier registers the verifier of the Google provider, it is built once from the Firebase credentials.
+// Without credentials no one can sign in with Google
+func registerGoogleVerifier(cfg *config.Config, identityProviders *auth.ProviderRegistry) error {
+	if cfg.FirebaseCredentials == nil {
+		slog.Warn("no firebase credentials, signing in with Google is disabled")
+		return nil
+	}
+
+	verifier, err := auth.NewGoogleVerifier(context.Background(), cfg.FirebaseCredentials, cfg.IdentityProviderTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to create google verifier: %w", err)
+	}
+	identityProviders.Register(constants.GoogleProvider, verifier)
+	return nil
+}
+
+// loadSigningKeys loads the signing keys at startup, so a missing or unreadable directory stops the service.
+// Outside the tests the directory is required, the keys only kept in memory would log everyone out on every restart
+// and differ between the instances
+func loadSigningKeys(cfg *config.Config) error {
+	if isTestEnvironment(cfg) {
+		return nil
+	}
+	if cfg.SigningKeysDir == "" {
+		return fmt.Errorf("JWT_KEYS_DIR is not set, it is required outside the tests")
+	}
+	if err := auth.RotateSigningKeys(); err != nil {
+		return fmt.Errorf("failed to load signing keys: %w", err)
+	}
+	return nil
+}
+
+// isTestEnvironment tells if the service is running the tests, where the tables are recreated and no background jobs are run
+func isTestEnvironment(cfg *config.Config) bool {
+	return cfg.Environment == "testing" || testing.Testing()
+}
+
+// startBackgroundJobs schedules the periodic maintenance jobs of the service
+func startBackgroundJobs(cfg *config.Config, userService *service.User) {
+	if isTestEnvironment(cfg) {
+		return
 	}
-	return userDb, registryDb, nil
+
+	schedulePeriodicJob("signing keys rotation", cfg.SigningKeysRotationCheckInterval, auth.RotateSigningKeys)
+	schedulePeriodicJob("follow counters reconciliation", cfg.FollowCountersReconciliationInterval, userService.ReconcileFollowCounters)
+
+	userService.StartRecommendationsRefresher(constants.RecommendationsRefreshQueueSize)
+	schedulePeriodicJob("recommendations refresh", cfg.RecommendationsRefreshInterval, userService.RefreshStaleRecommendations)
 }
 
 func addCorsConfiguration(r *Router) {
 	config := cors.DefaultConfig()
 	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization"}
+	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization", "If-Match"}
+	config.ExposeHeaders = []string{"ETag"}
 	config.AllowAllOrigins = true
 	config.AllowCredentials = true
 	r.Engine.Use(cors.New(config))
 }
 
+// Option changes the dependencies the router is created with, they can only be set in code and never
+// from the configuration
+type Option func(*routerOptions)
+
+type routerOptions struct {
+	identityVerifiers map[string]auth.IdentityVerifier
+}
+
+// WithIdentityVerifier makes the provider verify its tokens with the verifier instead of the configured one,
+// the tests use it to sign in with providers that can't be reached from them
+func WithIdentityVerifier(provider string, verifier auth.IdentityVerifier) Option {
+	return func(o *routerOptions) {
+		o.identityVerifiers[provider] = verifier
+	}
+}
+
 // Creates a new router with the configuration provided in the env file
-func CreateRouter() (*Router, error) {
+func CreateRouter(options ...Option) (*Router, error) {
+	opts := routerOptions{identityVerifiers: map[string]auth.IdentityVerifier{}}
+	for _, option := range options {
+		option(&opts)
+	}
+
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
+	if err := loadSigningKeys(cfg); err != nil {
+		return nil, err
+	}
 	r := createRouterFromConfig(cfg)
 
-	userDb, registryDb, err := createDatabases(cfg)
+	userDb, registryDb, catalogDb, err := createDatabases(cfg)
 	if err != nil {
 		slog.Error("failed to create databases", slog.String("error", err.Error()))
 		return nil, err
@@ -167,6 +249,7 @@ func CreateRouter() (*Router, error) {
 
 	r.Engine.Use(middleware.RequestLogger())
 	r.Engine.Use(middleware.ErrorHandler())
+	r.Engine.Use(middleware.LanguageMiddleware())
 
 	addCorsConfiguration(r)
 
@@ -174,15 +257,29 @@ func CreateRouter() (*Router, error) {
 	if err != nil {
 		slog.Error("failed to create producer", slog.String("error", err.Error()))
 	}
-	userService := service.CreateUserService(userDb, registryDb, amqp)
+	identityProviders := auth.NewProviderRegistry()
+	if err := registerGoogleVerifier(cfg, identityProviders); err != nil {
+		return nil, err
+	}
+	if err := identityProviders.LoadOIDCProviders(cfg.OIDCProvidersFile, cfg.IdentityProviderTimeout); err != nil {
+		return nil, fmt.Errorf("failed to load identity providers: %w", err)
+	}
+	for provider, verifier := range opts.identityVerifiers {
+		identityProviders.Register(provider, verifier)
+	}
+
+	userService := service.CreateUserService(userDb, registryDb, catalogDb, amqp, identityProviders, cfg.AutocompleteCacheSize, cfg.AutocompleteCacheTTL, cfg.CatalogCacheTTL)
 	userController := controller.CreateUserController(userService)
 
+	startBackgroundJobs(cfg, userService)
+
 	public := r.Engine.Group("/")
 	{
 		public.POST("/users/resolver", userController.ResolveUserEmail)
 
 		public.GET("/users/info/locations", userController.GetLocations)
 		public.GET("/users/info/interests", userController.GetInterests)
+		public.GET("/users/info/interests/categories", userController.GetInterestCategories)
 		public.POST("/users/register/:id/send-email", userController.SendVerificationEmail)
 		public.POST("/users/register/:id/verify-email", userController.VerifyEmail)
 		public.PUT("/users/register/:id/personal-info", userController.AddPersonalInfo)
@@ -190,6 +287,8 @@ func CreateRouter() (*Router, error) {
 		public.POST("/users/register/:id/complete", userController.CompleteRegistry)
 
 		public.POST("/users/login", userController.Login)
+
+		public.GET("/.well-known/jwks.json", userController.GetJWKS)
 	}
 
 	private := r.Engine.Group("/")
@@ -197,25 +296,60 @@ func CreateRouter() (*Router, error) {
 	private.Use(middleware.UserBlockedMiddleware(userService))
 	{
 		private.GET("/users/:id", userController.GetUserProfileById)
+		private.GET("/users/by-username/:username", userController.GetUserProfileByUsername)
+		private.POST("/users/batch", userController.GetUsersBatch)
 		private.PUT("/users/profile", userController.ModifyUserProfile)
+		private.PATCH("/users/profile", userController.PatchUserProfile)
 		private.GET("/users/:id/information", userController.GetUserInformation)
 
+		private.GET("/users/identities", userController.GetUserIdentities)
+		private.POST("/users/identities", userController.LinkUserIdentity)
+		private.DELETE("/users/identities/:provider", userController.UnlinkUserIdentity)
+
 		private.POST("/users/:id/follow", userController.FollowUser)
 		private.DELETE("/users/:id/follow", userController.UnfollowUser)
 		private.GET("/users/:id/followers", userController.GetFollowers)
 		private.GET("/users/:id/following", userController.GetFollowing)
+		private.GET("/users/:id/mutuals", userController.GetMutuals)
+		private.GET("/users/:id/relationship", userController.GetRelationship)
 		private.POST("/users/:id/block", userController.BlockUser)
 		private.POST("/users/:id/unblock", userController.UnblockUser)
 
 		private.GET("/users/search", userController.SearchUsers)
+		private.GET("/users/autocomplete", userController.AutocompleteUsernames)
+		private.GET("/users/nearby", userController.GetNearbyUsers)
 
 		private.GET("/users/recommendations", userController.RecommendUsers)
+		private.POST("/users/recommendations/:id/dismiss", userController.DismissRecommendation)
 
 		private.GET("/users/all", userController.GetAllUsers)
 
+		private.GET("/users/admin/catalogs/:catalog", userController.GetCatalogEntries)
+		private.POST("/users/admin/catalogs/:catalog", userController.AddCatalogEntry)
+		private.PATCH("/users/admin/catalogs/:catalog/:entry_id", userController.UpdateCatalogEntry)
+		private.PUT("/users/admin/catalogs/:catalog/order", userController.ReorderCatalog)
+		private.PUT("/users/admin/catalogs/:catalog/:entry_id/translations/:language", userController.SetCatalogEntryTranslation)
+		private.DELETE("/users/admin/catalogs/:catalog/:entry_id/translations/:language", userController.DeleteCatalogEntryTranslation)
+
+		private.GET("/users/admin/service-clients", userController.GetServiceClients)
+		private.POST("/users/admin/service-clients", userController.CreateServiceClient)
+		private.POST("/users/admin/service-clients/:id/rotate", userController.RotateServiceClientSecret)
+		private.DELETE("/users/admin/service-clients/:id", userController.RevokeServiceClient)
+
 		private.GET("/users/metrics/followers", userController.GetAmountOfFollowers)
 	}
 
+	// the internal endpoints are only for the other services of the platform, each one needs its scope
+	r.Engine.POST("/internal/token", userController.IssueServiceToken)
+
+	internal := r.Engine.Group("/internal")
+	internal.Use(middleware.ServiceAuthMiddleware(userService))
+	{
+		internal.GET("/users/:id", middleware.RequireScope(constants.ScopeUsersRead), userController.GetInternalUserProfile)
+		internal.POST("/users/batch", middleware.RequireScope(constants.ScopeUsersRead), userController.GetInternalUsersBatch)
+		internal.GET("/users/:id/metrics/followers", middleware.RequireScope(constants.ScopeMetricsRead), userController.GetInternalAmountOfFollowers)
+	}
+
 	r.Engine.NoRoute(userController.HandleNoRoute)
 	return r, nil
 }
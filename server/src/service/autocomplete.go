@@ -0,0 +1,38 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"users-service/src/app_errors"
+	"users-service/src/model"
+
+	"github.com/google/uuid"
+)
+
+// suggestionsKey identifies the cached suggestions of a user for a prefix,
+// they are per user because the followed users come first
+type suggestionsKey struct {
+	userId uuid.UUID
+	prefix string
+	limit  int
+}
+
+// AutocompleteUsernames returns up to limit compact profiles of the users whose username starts with the prefix,
+// the ones the user follows first. The results are cached for a short time, if the cache is enabled,
+// so they may not reflect follows made in the meantime
+func (u *User) AutocompleteUsernames(userSessionId uuid.UUID, prefix string, limit int) ([]model.UserSuggestion, error) {
+	key := suggestionsKey{userId: userSessionId, prefix: strings.ToLower(prefix), limit: limit}
+	if suggestions, found := u.suggestionsCache.Get(key); found {
+		return suggestions, nil
+	}
+
+	suggestions, err := u.userDb.GetUsernameSuggestions(userSessionId, prefix, limit)
+	if err != nil {
+		err = app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting suggestions for %s: %w", prefix, err))
+		return nil, err
+	}
+
+	u.suggestionsCache.Add(key, suggestions)
+	return suggestions, nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"users-service/src/app_errors"
+	"users-service/src/constants"
+	"users-service/src/model"
+
+	"github.com/google/uuid"
+)
+
+// GetUsersBatch returns the compact profiles of the users with the ids or usernames of the request in a single
+// query, keyed by id, with whether the session user follows them and they follow it
+func (u *User) GetUsersBatch(userSessionId uuid.UUID, request model.UserBatchRequest) (model.UserBatchResponse, error) {
+	requested := len(request.Ids) + len(request.Usernames)
+	if requested == 0 || requested > constants.MaxBatchUsers {
+		return model.UserBatchResponse{}, app_errors.NewAppError(http.StatusBadRequest, InvalidUserBatch, fmt.Errorf("a batch must have between 1 and %d users, it has %d", constants.MaxBatchUsers, requested))
+	}
+
+	ids := make([]uuid.UUID, len(request.Ids))
+	for i, id := range request.Ids {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			return model.UserBatchResponse{}, app_errors.NewAppError(http.StatusBadRequest, InvalidUserBatch, fmt.Errorf("invalid id %s: %w", id, err))
+		}
+		ids[i] = parsed
+	}
+
+	users, err := u.userDb.GetUsersBatch(userSessionId, ids, request.Usernames)
+	if err != nil {
+		return model.UserBatchResponse{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting batch of users: %w", err))
+	}
+
+	response := model.UserBatchResponse{
+		Users:     make(map[string]*model.BatchUser, requested),
+		Usernames: make(map[string]*uuid.UUID, len(request.Usernames)),
+	}
+	for _, id := range ids {
+		response.Users[id.String()] = nil
+	}
+	for _, username := range request.Usernames {
+		response.Usernames[username] = nil
+	}
+
+	byUsername := make(map[string]uuid.UUID, len(users))
+	for i := range users {
+		response.Users[users[i].Id.String()] = &users[i]
+		byUsername[strings.ToLower(users[i].UserName)] = users[i].Id
+	}
+	for _, username := range request.Usernames {
+		if id, found := byUsername[strings.ToLower(username)]; found {
+			response.Usernames[username] = &id
+		}
+	}
+
+	return response, nil
+}
@@ -0,0 +1,494 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"users-service/src/app_errors"
+	"users-service/src/cache"
+	"users-service/src/constants"
+	"users-service/src/database"
+	"users-service/src/database/catalog_db"
+	"users-service/src/model"
+)
+
+// catalogs serves the entries of the catalogs of interests, their categories and locations. They are read on every registration,
+// profile edit and search but rarely change, so they are cached for the ttl. An edit clears the cache of
+// this instance right away, the other instances see it once their cache expires
+type catalogs struct {
+	db    catalog_db.CatalogDatabase
+	cache *cache.LRU[model.Catalog, catalogSnapshot]
+}
+
+// catalogSnapshot holds the entries of a catalog sorted by position, indexed by their id
+type catalogSnapshot struct {
+	entries []model.CatalogEntry
+	byId    map[int]model.CatalogEntry
+}
+
+// newCatalogs creates the catalogs, a non positive ttl disables the cache
+func newCatalogs(db catalog_db.CatalogDatabase, ttl time.Duration) *catalogs {
+	capacity := 0
+	if ttl > 0 {
+		capacity = 3
+	}
+
+	return &catalogs{
+		db:    db,
+		cache: cache.NewLRU[model.Catalog, catalogSnapshot](capacity, ttl),
+	}
+}
+
+func (c *catalogs) get(catalog model.Catalog) (catalogSnapshot, error) {
+	if snapshot, ok := c.cache.Get(catalog); ok {
+		return snapshot, nil
+	}
+
+	entries, err := c.db.GetCatalogEntries(catalog)
+	if err != nil {
+		return catalogSnapshot{}, err
+	}
+
+	snapshot := catalogSnapshot{entries: entries, byId: make(map[int]model.CatalogEntry, len(entries))}
+	for _, entry := range entries {
+		snapshot.byId[entry.Id] = entry
+	}
+
+	c.cache.Add(catalog, snapshot)
+	return snapshot, nil
+}
+
+func (c *catalogs) invalidate(catalog model.Catalog) {
+	c.cache.Remove(catalog)
+}
+
+// exists tells if the catalog has an entry with the id, deprecated or not
+func (s catalogSnapshot) exists(id int) bool {
+	_, exists := s.byId[id]
+	return exists
+}
+
+// selectable tells if the entry with the id can be chosen by the users, that is, it exists and is not deprecated
+func (s catalogSnapshot) selectable(id int) bool {
+	entry, exists := s.byId[id]
+	return exists && !entry.Deprecated
+}
+
+// selectableEntries returns the entries that can be chosen by the users, in order
+func (s catalogSnapshot) selectableEntries() []model.CatalogEntry {
+	entries := []model.CatalogEntry{}
+	for _, entry := range s.entries {
+		if !entry.Deprecated {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// localizedName returns the name of the entry in the language, falling back to the name of the entry,
+// it returns false if the catalog has no entry with the id
+func (s catalogSnapshot) localizedName(id int, language string) (string, bool) {
+	entry, exists := s.byId[id]
+	if !exists {
+		return "", false
+	}
+	return localizedEntryName(entry, language), true
+}
+
+func localizedEntryName(entry model.CatalogEntry, language string) string {
+	if name, ok := entry.Translations[language]; ok {
+		return name
+	}
+	return entry.Name
+}
+
+// localizedPath returns the names of the location and the ones it is inside of in the language,
+// from the narrowest to the widest, like "Rosario, Santa Fe, Argentina"
+func (s catalogSnapshot) localizedPath(id int, language string) (string, bool) {
+	names := []string{}
+	for current := &id; current != nil; {
+		entry, exists := s.byId[*current]
+		if !exists {
+			return "", false
+		}
+		names = append(names, localizedEntryName(entry, language))
+		current = entry.ParentId
+	}
+	return strings.Join(names, ", "), true
+}
+
+// isChildOf tells if the entry is right inside the parent, a nil parent means the entry is a top level one
+func isChildOf(entry model.CatalogEntry, parentId *int) bool {
+	if parentId == nil || entry.ParentId == nil {
+		return parentId == nil && entry.ParentId == nil
+	}
+	return *entry.ParentId == *parentId
+}
+
+// localizeUsers sets the names of the location and interests of the users in the language. The users already
+// have them in the names of the entries, those are kept if the catalogs can't be read or lack an entry
+func (u *User) localizeUsers(users []model.UserRecord, language string) {
+	locations, err := u.catalogs.get(model.LocationsCatalog)
+	if err != nil {
+		slog.Warn("error getting locations to localize users", slog.String("error", err.Error()))
+		return
+	}
+	interests, err := u.catalogs.get(model.InterestsCatalog)
+	if err != nil {
+		slog.Warn("error getting interests to localize users", slog.String("error", err.Error()))
+		return
+	}
+
+	for i := range users {
+		if location, ok := locations.localizedPath(users[i].LocationId, language); ok {
+			users[i].Location = location
+		}
+		if len(users[i].InterestIds) != len(users[i].Interests) {
+			continue
+		}
+		for j, id := range users[i].InterestIds {
+			if interest, ok := interests.localizedName(id, language); ok {
+				users[i].Interests[j] = interest
+			}
+		}
+	}
+}
+
+func (u *User) localizeUser(user *model.UserRecord, language string) {
+	users := []model.UserRecord{*user}
+	u.localizeUsers(users, language)
+	*user = users[0]
+}
+
+// GetLocations returns the locations that can be chosen right inside the parent, or the countries without it,
+// named in the language
+func (u *User) GetLocations(parentId *int, language string) (map[string]interface{}, error) {
+	snapshot, err := u.catalogs.get(model.LocationsCatalog)
+	if err != nil {
+		return nil, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting locations: %w", err))
+	}
+	if parentId != nil && !snapshot.exists(*parentId) {
+		return nil, app_errors.NewAppError(http.StatusBadRequest, InvalidLocation, fmt.Errorf("invalid location id: %d", *parentId))
+	}
+
+	locations := []model.Location{}
+	for _, entry := range snapshot.selectableEntries() {
+		if isChildOf(entry, parentId) {
+			locations = append(locations, model.Location{Id: entry.Id, Name: localizedEntryName(entry, language), Kind: entry.Kind})
+		}
+	}
+
+	slog.Info("locations retrieved successfully")
+	return map[string]interface{}{
+		"locations": locations,
+	}, nil
+}
+
+// GetInterests returns the interests that can be chosen, named in the language. With a category,
+// just the ones in it
+func (u *User) GetInterests(categoryId *int, language string) (map[string]interface{}, error) {
+	if categoryId != nil {
+		categories, err := u.catalogs.get(model.InterestCategoriesCatalog)
+		if err != nil {
+			return nil, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting interest categories: %w", err))
+		}
+		if !categories.exists(*categoryId) {
+			return nil, app_errors.NewAppError(http.StatusBadRequest, InvalidInterestCategory, fmt.Errorf("invalid interest category id: %d", *categoryId))
+		}
+	}
+
+	snapshot, err := u.catalogs.get(model.InterestsCatalog)
+	if err != nil {
+		return nil, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting interests: %w", err))
+	}
+
+	interests := []model.Interest{}
+	for _, entry := range snapshot.selectableEntries() {
+		if categoryId != nil && (entry.CategoryId == nil || *entry.CategoryId != *categoryId) {
+			continue
+		}
+		interests = append(interests, model.Interest{
+			Id:          entry.Id,
+			Interest:    localizedEntryName(entry, language),
+			CategoryId:  entry.CategoryId,
+			Icon:        entry.Icon,
+			Description: entry.Description,
+		})
+	}
+
+	slog.Info("interests retrieved successfully")
+	return map[string]interface{}{
+		"interests": interests,
+	}, nil
+}
+
+// GetInterestCategories returns the categories of interests that can be chosen, named in the language
+func (u *User) GetInterestCategories(language string) (map[string]interface{}, error) {
+	snapshot, err := u.catalogs.get(model.InterestCategoriesCatalog)
+	if err != nil {
+		return nil, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting interest categories: %w", err))
+	}
+
+	categories := []model.InterestCategory{}
+	for _, entry := range snapshot.selectableEntries() {
+		categories = append(categories, model.InterestCategory{
+			Id:          entry.Id,
+			Name:        localizedEntryName(entry, language),
+			Icon:        entry.Icon,
+			Description: entry.Description,
+		})
+	}
+
+	slog.Info("interest categories retrieved successfully")
+	return map[string]interface{}{
+		"categories": categories,
+	}, nil
+}
+
+func validateCatalogAdmin(userSessionIsAdmin bool, catalog model.Catalog) error {
+	if !userSessionIsAdmin {
+		return app_errors.NewAppError(http.StatusForbidden, UserIsNotAdmin, ErrUserIsNotAdmin)
+	}
+	if !catalog.IsValid() {
+		return app_errors.NewAppError(http.StatusNotFound, CatalogNotFound, fmt.Errorf("unknown catalog: %s", catalog))
+	}
+	return nil
+}
+
+func validateLanguage(language string) error {
+	if !slices.Contains(constants.SupportedLanguages, language) {
+		return app_errors.NewAppError(http.StatusBadRequest, UnsupportedLanguage, fmt.Errorf("unsupported language: '%s'", language))
+	}
+	return nil
+}
+
+func validateCatalogEntryName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > constants.MaxCatalogEntryNameLength {
+		return "", app_errors.NewAppError(http.StatusBadRequest, InvalidCatalogEntryName, fmt.Errorf("invalid catalog entry name: '%s'", name))
+	}
+	return name, nil
+}
+
+// validateCatalogEntryMetadata checks that only the catalogs with metadata get an icon and a description,
+// that only the interests get a category and that the category can be chosen
+func (u *User) validateCatalogEntryMetadata(catalog model.Catalog, icon string, description string, categoryId *int) error {
+	if !catalog.HasMetadata() && (icon != "" || description != "") {
+		return app_errors.NewAppError(http.StatusBadRequest, InvalidCatalogEntryMetadata, fmt.Errorf("the entries of %s have no icon nor description", catalog))
+	}
+	if len(icon) > constants.MaxCatalogEntryIconLength || len(description) > constants.MaxCatalogEntryDescriptionLength {
+		return app_errors.NewAppError(http.StatusBadRequest, InvalidCatalogEntryMetadata, fmt.Errorf("the icon or description is too long"))
+	}
+	if categoryId == nil {
+		return nil
+	}
+
+	if catalog != model.InterestsCatalog {
+		return app_errors.NewAppError(http.StatusBadRequest, InvalidCatalogEntryMetadata, fmt.Errorf("the entries of %s have no category", catalog))
+	}
+	categories, err := u.catalogs.get(model.InterestCategoriesCatalog)
+	if err != nil {
+		return app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting interest categories: %w", err))
+	}
+	if !categories.selectable(*categoryId) {
+		return app_errors.NewAppError(http.StatusBadRequest, InvalidInterestCategory, fmt.Errorf("invalid interest category id: %d", *categoryId))
+	}
+	return nil
+}
+
+// GetCatalogEntries returns every entry of the catalog, deprecated ones included, it is just for admins
+func (u *User) GetCatalogEntries(userSessionIsAdmin bool, catalog model.Catalog) ([]model.CatalogEntry, error) {
+	if err := validateCatalogAdmin(userSessionIsAdmin, catalog); err != nil {
+		return nil, err
+	}
+
+	entries, err := u.catalogDb.GetCatalogEntries(catalog)
+	if err != nil {
+		return nil, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting %s: %w", catalog, err))
+	}
+	return entries, nil
+}
+
+// AddCatalogEntry adds an entry at the end of the catalog, it is just for admins
+func (u *User) AddCatalogEntry(userSessionIsAdmin bool, catalog model.Catalog, request model.CreateCatalogEntryRequest) (model.CatalogEntry, error) {
+	if err := validateCatalogAdmin(userSessionIsAdmin, catalog); err != nil {
+		return model.CatalogEntry{}, err
+	}
+
+	name, err := validateCatalogEntryName(request.Name)
+	if err != nil {
+		return model.CatalogEntry{}, err
+	}
+
+	icon, description := strings.TrimSpace(request.Icon), strings.TrimSpace(request.Description)
+	if err := u.validateCatalogEntryMetadata(catalog, icon, description, request.CategoryId); err != nil {
+		return model.CatalogEntry{}, err
+	}
+
+	entry, err := u.catalogDb.AddCatalogEntry(catalog, model.CatalogEntry{Name: name, Icon: icon, Description: description, CategoryId: request.CategoryId})
+	if err != nil {
+		if errors.Is(err, database.ErrKeyAlreadyExists) {
+			return model.CatalogEntry{}, app_errors.NewAppError(http.StatusConflict, CatalogEntryAlreadyExists, err)
+		}
+		return model.CatalogEntry{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error adding entry to %s: %w", catalog, err))
+	}
+	u.catalogs.invalidate(catalog)
+
+	slog.Info("catalog entry added", slog.String("catalog", string(catalog)), slog.Int("id", entry.Id))
+	return entry, nil
+}
+
+// getCatalogEntry returns the current entry of the catalog with the id, skipping the cache
+func (u *User) getCatalogEntry(catalog model.Catalog, id int) (model.CatalogEntry, error) {
+	entries, err := u.catalogDb.GetCatalogEntries(catalog)
+	if err != nil {
+		return model.CatalogEntry{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting %s: %w", catalog, err))
+	}
+
+	for _, entry := range entries {
+		if entry.Id == id {
+			return entry, nil
+		}
+	}
+	return model.CatalogEntry{}, app_errors.NewAppError(http.StatusNotFound, CatalogEntryNotFound, fmt.Errorf("entry %d not found in %s", id, catalog))
+}
+
+// UpdateCatalogEntry edits and deprecates an entry, it is just for admins.
+// The users reference the entries by id, so they see the new name right away
+func (u *User) UpdateCatalogEntry(userSessionIsAdmin bool, catalog model.Catalog, id int, request model.UpdateCatalogEntryRequest) (model.CatalogEntry, error) {
+	if err := validateCatalogAdmin(userSessionIsAdmin, catalog); err != nil {
+		return model.CatalogEntry{}, err
+	}
+
+	current, err := u.getCatalogEntry(catalog, id)
+	if err != nil {
+		return model.CatalogEntry{}, err
+	}
+
+	updated := current
+	if request.Name != nil {
+		if updated.Name, err = validateCatalogEntryName(*request.Name); err != nil {
+			return model.CatalogEntry{}, err
+		}
+	}
+	if request.Deprecated != nil {
+		updated.Deprecated = *request.Deprecated
+	}
+	if request.Icon != nil {
+		updated.Icon = strings.TrimSpace(*request.Icon)
+	}
+	if request.Description != nil {
+		updated.Description = strings.TrimSpace(*request.Description)
+	}
+	if request.CategoryId != nil {
+		updated.CategoryId = request.CategoryId
+	}
+
+	// the category is checked just when it changes, the entry may stay in one that was deprecated later
+	if err := u.validateCatalogEntryMetadata(catalog, updated.Icon, updated.Description, request.CategoryId); err != nil {
+		return model.CatalogEntry{}, err
+	}
+
+	entry, err := u.catalogDb.UpdateCatalogEntry(catalog, updated)
+	if err != nil {
+		if errors.Is(err, database.ErrKeyNotFound) {
+			return model.CatalogEntry{}, app_errors.NewAppError(http.StatusNotFound, CatalogEntryNotFound, err)
+		}
+		if errors.Is(err, database.ErrKeyAlreadyExists) {
+			return model.CatalogEntry{}, app_errors.NewAppError(http.StatusConflict, CatalogEntryAlreadyExists, err)
+		}
+		return model.CatalogEntry{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error updating entry of %s: %w", catalog, err))
+	}
+	u.catalogs.invalidate(catalog)
+
+	slog.Info("catalog entry updated", slog.String("catalog", string(catalog)), slog.Int("id", entry.Id))
+	return entry, nil
+}
+
+// ReorderCatalog sets the order in which the entries with the given parent are shown, it is just for admins.
+// The ids must list every one of those entries, deprecated ones included, exactly once
+func (u *User) ReorderCatalog(userSessionIsAdmin bool, catalog model.Catalog, parentId *int, ids []int) ([]model.CatalogEntry, error) {
+	if err := validateCatalogAdmin(userSessionIsAdmin, catalog); err != nil {
+		return nil, err
+	}
+
+	entries, err := u.catalogDb.GetCatalogEntries(catalog)
+	if err != nil {
+		return nil, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting %s: %w", catalog, err))
+	}
+
+	pending := make(map[int]bool, len(entries))
+	for _, entry := range entries {
+		if isChildOf(entry, parentId) {
+			pending[entry.Id] = true
+		}
+	}
+	for _, id := range ids {
+		if !pending[id] {
+			return nil, app_errors.NewAppError(http.StatusBadRequest, InvalidCatalogOrder, fmt.Errorf("unknown or repeated entry %d in the order of %s", id, catalog))
+		}
+		delete(pending, id)
+	}
+	if len(pending) > 0 {
+		return nil, app_errors.NewAppError(http.StatusBadRequest, InvalidCatalogOrder, fmt.Errorf("the order of %s is missing %d entries", catalog, len(pending)))
+	}
+
+	if err := u.catalogDb.ReorderCatalog(catalog, ids); err != nil {
+		return nil, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error reordering %s: %w", catalog, err))
+	}
+	u.catalogs.invalidate(catalog)
+
+	slog.Info("catalog reordered", slog.String("catalog", string(catalog)))
+	return u.GetCatalogEntries(userSessionIsAdmin, catalog)
+}
+
+// SetCatalogEntryTranslation sets the name of an entry in one of the supported languages, it is just for admins
+func (u *User) SetCatalogEntryTranslation(userSessionIsAdmin bool, catalog model.Catalog, id int, language string, request model.SetCatalogTranslationRequest) (model.CatalogEntry, error) {
+	if err := validateCatalogAdmin(userSessionIsAdmin, catalog); err != nil {
+		return model.CatalogEntry{}, err
+	}
+	if err := validateLanguage(language); err != nil {
+		return model.CatalogEntry{}, err
+	}
+
+	name, err := validateCatalogEntryName(request.Name)
+	if err != nil {
+		return model.CatalogEntry{}, err
+	}
+
+	if err := u.catalogDb.SetCatalogEntryTranslation(catalog, id, language, name); err != nil {
+		if errors.Is(err, database.ErrKeyNotFound) {
+			return model.CatalogEntry{}, app_errors.NewAppError(http.StatusNotFound, CatalogEntryNotFound, fmt.Errorf("entry %d not found in %s", id, catalog))
+		}
+		return model.CatalogEntry{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error translating entry of %s: %w", catalog, err))
+	}
+	u.catalogs.invalidate(catalog)
+
+	slog.Info("catalog entry translated", slog.String("catalog", string(catalog)), slog.Int("id", id), slog.String("language", language))
+	return u.getCatalogEntry(catalog, id)
+}
+
+// DeleteCatalogEntryTranslation removes the name of an entry in a language, it is just for admins
+func (u *User) DeleteCatalogEntryTranslation(userSessionIsAdmin bool, catalog model.Catalog, id int, language string) error {
+	if err := validateCatalogAdmin(userSessionIsAdmin, catalog); err != nil {
+		return err
+	}
+	if err := validateLanguage(language); err != nil {
+		return err
+	}
+
+	if err := u.catalogDb.DeleteCatalogEntryTranslation(catalog, id, language); err != nil {
+		if errors.Is(err, database.ErrKeyNotFound) {
+			return app_errors.NewAppError(http.StatusNotFound, CatalogTranslationNotFound, fmt.Errorf("entry %d of %s has no %s translation", id, catalog, language))
+		}
+		return app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error deleting translation of %s: %w", catalog, err))
+	}
+	u.catalogs.invalidate(catalog)
+
+	slog.Info("catalog entry translation deleted", slog.String("catalog", string(catalog)), slog.Int("id", id), slog.String("language", language))
+	return nil
+}
@@ -19,10 +19,40 @@ const (
 	InvalidRegistryStep         = "Invalid registry step"
 	VerificationPinNotFound		= "Verification pin not found"
 	InvalidInterest             = "Invalid interest"
+	InvalidLocation             = "Invalid location"
 	CantFollowYourself          = "Can't follow yourself"
 	AlreadyFollowing            = "The user already follows this user"
 	NotFollowing                = "The user is not following this user"
 	UserShouldModifyItself      = "The user should modify its own profile"
 	UserIsNotAdmin              = "The user is not an admin"
 	UserBlocked                 = "User is blocked"
+	CantDismissYourself         = "Can't dismiss yourself"
+	InvalidDismissalExpiration  = "The dismissal expiration must be in the future"
+	CatalogNotFound             = "Catalog not found"
+	CatalogEntryNotFound        = "Catalog entry not found"
+	CatalogEntryAlreadyExists   = "The catalog already has an entry with that name"
+	InvalidCatalogEntryName     = "Invalid catalog entry name"
+	InvalidCatalogOrder         = "The order must list every entry of the catalog with the same parent exactly once"
+	LocationWithoutCoordinates  = "The location of the user has no coordinates"
+	UnsupportedLanguage         = "Unsupported language"
+	CatalogTranslationNotFound  = "Catalog entry translation not found"
+	InvalidCatalogEntryMetadata = "Invalid catalog entry icon, description or category"
+	InvalidInterestCategory     = "Invalid interest category"
+	InvalidProfilePatch         = "Invalid profile patch"
+	ProfileVersionMismatch      = "The profile was modified since it was retrieved"
+	InvalidUserBatch            = "Invalid user batch"
+	ServiceClientNotFound       = "Service client not found"
+	ServiceClientAlreadyExists  = "There is already a service client with that name"
+	InvalidServiceClient        = "Invalid service client name or scopes"
+	UnsupportedGrantType        = "Unsupported grant type"
+	InvalidClientCredentials    = "Invalid client credentials"
+	InvalidServiceScope         = "The client was not granted the requested scope"
+	UnknownProvider             = "Unknown provider type"
+	InvalidProviderToken        = "Invalid token"
+	UnverifiedProviderEmail     = "The identity provider did not verify the email"
+	ProviderEmailMismatch       = "The email does not match the one verified by the identity provider"
+	IdentityAlreadyLinked       = "The account is linked to another account of the identity provider"
+	IdentityLinkedToAnotherUser = "The identity provider account is linked to another user"
+	IdentityNotLinked           = "The account is not linked to the identity provider"
+	LastLoginMethod             = "The account needs at least one way to sign in"
 )
@@ -11,13 +11,17 @@ import (
 	"strconv"
 	"time"
 	"users-service/src/app_errors"
+	"users-service/src/auth"
+	"users-service/src/constants"
 	"users-service/src/database"
 	"users-service/src/model"
 
 	"github.com/google/uuid"
 )
 
-func sendNewFollowerNotification(followerId uuid.UUID, followingId uuid.UUID, token string) error {
+// sendNewFollowerNotification tells the notifications service that the user has a new follower,
+// authenticated as this service instead of as the follower
+func sendNewFollowerNotification(followerId uuid.UUID, followingId uuid.UUID) error {
 	type NewFollowerNotification struct {
 		UserId      uuid.UUID `json:"user_id"`
 		FollowerId	uuid.UUID `json:"follower_id"`
@@ -27,14 +31,18 @@ func sendNewFollowerNotification(followerId uuid.UUID, followingId uuid.UUID, to
 	marshalledData, _ := json.Marshal(NewFollowerNotification{followingId, followerId})
 
 	req, err := http.NewRequest("POST", url, bytes.NewReader(marshalledData))
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+token)
-
 	if err != nil {
 		return errors.New("error creating request")
 	}
 
+	token, err := auth.GenerateServiceToken(constants.UsersServiceClientName, []string{constants.ScopeNotificationsSend}, constants.ServiceTokenDuration)
+	if err != nil {
+		return errors.New("error generating service token, " + err.Error())
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
 	resp, err := http.DefaultClient.Do(req)
 
 	if err != nil {
@@ -50,7 +58,7 @@ func sendNewFollowerNotification(followerId uuid.UUID, followingId uuid.UUID, to
 	return nil
 }
 
-func (u *User) FollowUser(followerId uuid.UUID, followingId uuid.UUID, token string) error {
+func (u *User) FollowUser(followerId uuid.UUID, followingId uuid.UUID) error {
 	userRecord, err := u.userDb.GetUserById(followingId)
 	if err != nil {
 		if errors.Is(err, database.ErrKeyNotFound) {
@@ -71,7 +79,7 @@ func (u *User) FollowUser(followerId uuid.UUID, followingId uuid.UUID, token str
 		return app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error following user: %w", err))
 	}
 
-	err = sendNewFollowerNotification(followerId, followingId, token)
+	err = sendNewFollowerNotification(followerId, followingId)
 	if err != nil {
 		slog.Warn(
 			"Error sending notification",
@@ -80,6 +88,8 @@ func (u *User) FollowUser(followerId uuid.UUID, followingId uuid.UUID, token str
 		)
 	}
 
+	u.recommendationsRefresher.enqueue(followerId)
+
 	slog.Info("user followed succesfully", slog.String("followerId", followerId.String()), slog.String("followingId", userRecord.Id.String()))
 	return nil
 }
@@ -101,78 +111,117 @@ func (u *User) UnfollowUser(followerId uuid.UUID, followingId uuid.UUID) error {
 		return app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error unfollowing user: %w", err))
 	}
 
+	u.recommendationsRefresher.enqueue(followerId)
+
 	slog.Info("user unfollowed succesfully", slog.String("followerId", followerId.String()), slog.String("followingId", userRecord.Id.String()))
 	return nil
 }
 
 // GetFollowers returns the followers of a user and if there are more to fetch
-func (u *User) GetFollowers(id uuid.UUID, userSessionId uuid.UUID, timestamp string, skip, limit int) ([]model.UserProfileResponse, bool, error) {
+func (u *User) GetFollowers(id uuid.UUID, userSessionId uuid.UUID, page model.PageRequest, language string) ([]model.UserProfileResponse, *model.Cursor, error) {
 	userRequested, err := u.userDb.GetUserById(id)
 	if err != nil {
 		if errors.Is(err, database.ErrKeyNotFound) {
-			return nil, false, app_errors.NewAppError(http.StatusNotFound, UsernameNotFound, err)
+			return nil, nil, app_errors.NewAppError(http.StatusNotFound, UsernameNotFound, err)
 		}
-		return nil, false, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error retrieving user: %w", err))
+		return nil, nil, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error retrieving user: %w", err))
 	}
 
 	if userRequested.Id != userSessionId {
 		follows, err := u.userDb.CheckIfUserFollows(userSessionId, userRequested.Id)
 		if err != nil {
-			return nil, false, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error checking if user follows: %w", err))
+			return nil, nil, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error checking if user follows: %w", err))
 		}
 
 		if !follows {
-			return nil, false, app_errors.NewAppError(http.StatusForbidden, NotFollowing, fmt.Errorf("user does not follow the user"))
+			return nil, nil, app_errors.NewAppError(http.StatusForbidden, NotFollowing, fmt.Errorf("user does not follow the user"))
 		}
 	}
 
-	followers, hasMore, err := u.userDb.GetFollowers(userRequested.Id, timestamp, skip, limit)
+	followers, next, err := u.userDb.GetFollowers(userRequested.Id, page)
 	if err != nil {
-		return nil, false, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting followers: %w", err))
+		return nil, nil, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting followers: %w", err))
 	}
 
-	profiles, err := u.getUserProfilesFromUserRecords(followers, userSessionId)
+	profiles, err := u.getUserProfilesFromUserRecords(followers, userSessionId, language)
 	if err != nil {
-		return nil, false, err
+		return nil, nil, err
 	}
 
-	return profiles, hasMore, nil
+	return profiles, next, nil
 }
 
 // GetFollowers returns the user's a user is following and if there are more to fetch
-func (u *User) GetFollowing(id uuid.UUID, userSessionId uuid.UUID, timestamp string, skip, limit int) ([]model.UserProfileResponse, bool, error) {
+func (u *User) GetFollowing(id uuid.UUID, userSessionId uuid.UUID, page model.PageRequest, language string) ([]model.UserProfileResponse, *model.Cursor, error) {
 	userRecord, err := u.userDb.GetUserById(id)
 	if err != nil {
 		if errors.Is(err, database.ErrKeyNotFound) {
-			return nil, false, app_errors.NewAppError(http.StatusNotFound, UsernameNotFound, err)
+			return nil, nil, app_errors.NewAppError(http.StatusNotFound, UsernameNotFound, err)
 		}
-		return nil, false, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error retrieving user: %w", err))
+		return nil, nil, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error retrieving user: %w", err))
 	}
 
 	if userRecord.Id != userSessionId {
 		follows, err := u.userDb.CheckIfUserFollows(userSessionId, userRecord.Id)
 		if err != nil {
-			return nil, false, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error checking if user follows: %w", err))
+			return nil, nil, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error checking if user follows: %w", err))
 		}
 
 		if !follows {
-			return nil, false, app_errors.NewAppError(http.StatusForbidden, NotFollowing, fmt.Errorf("user does not follow the user"))
+			return nil, nil, app_errors.NewAppError(http.StatusForbidden, NotFollowing, fmt.Errorf("user does not follow the user"))
+		}
+	}
+
+	following, next, err := u.userDb.GetFollowing(userRecord.Id, page)
+	if err != nil {
+		return nil, nil, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting following: %w", err))
+	}
+
+	profiles, err := u.getUserProfilesFromUserRecords(following, userSessionId, language)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return profiles, next, nil
+}
+
+// GetMutuals returns the users that the session user follows and that follow the given user, and if there are more to fetch.
+// Unlike the followers, they can be seen without following the user, since the session user already knows them
+func (u *User) GetMutuals(id uuid.UUID, userSessionId uuid.UUID, page model.PageRequest, language string) ([]model.UserProfileResponse, *model.Cursor, error) {
+	userRequested, err := u.userDb.GetUserById(id)
+	if err != nil {
+		if errors.Is(err, database.ErrKeyNotFound) {
+			return nil, nil, app_errors.NewAppError(http.StatusNotFound, UsernameNotFound, err)
 		}
+		return nil, nil, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error retrieving user: %w", err))
 	}
 
-	following, hasMore, err := u.userDb.GetFollowing(userRecord.Id, timestamp, skip, limit)
+	mutuals, next, err := u.userDb.GetMutuals(userSessionId, userRequested.Id, page)
 	if err != nil {
-		return nil, false, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting following: %w", err))
+		return nil, nil, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting mutuals: %w", err))
 	}
 
-	profiles, err := u.getUserProfilesFromUserRecords(following, userSessionId)
+	profiles, err := u.getUserProfilesFromUserRecords(mutuals, userSessionId, language)
 	if err != nil {
-		return nil, false, err
+		return nil, nil, err
 	}
 
-	return profiles, hasMore, nil
+	return profiles, next, nil
 }
 
 func (u *User) GetAmountOfFollowersInTimeRange(userId uuid.UUID, startTime, endTime time.Time) (int, error) {
 	return u.userDb.GetAmountOfFollowersInTimeRange(userId, startTime, endTime)
-}
\ No newline at end of file
+}
+
+// ReconcileFollowCounters repairs the followers and following counters that drifted from the followers table
+func (u *User) ReconcileFollowCounters() error {
+	repaired, err := u.userDb.ReconcileFollowCounters()
+	if err != nil {
+		return fmt.Errorf("error reconciling follow counters: %w", err)
+	}
+
+	if repaired > 0 {
+		slog.Warn("follow counters repaired", slog.Int("users", repaired))
+	}
+	return nil
+}
@@ -11,70 +11,28 @@ import (
 	"github.com/google/uuid"
 )
 
-// SearchUsers retrieves the users that have a username or name containing the text
-// it sends first the ones that contain the text in the username
-// then the ones that contain it in the name
-// it also receives a timestamp, skip and limit to paginate the results
-func (u *User) SearchUsers(userSessionId uuid.UUID, text string, timestamp string, skip int, limit int) ([]model.UserProfileResponse, bool, error) {
-	users, hasMore, err := u.userDb.GetUsersWithUsernameContaining(text, timestamp, skip, limit)
-	if err != nil {
-		err = app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting users with username containing %s: %w", text, err))
-		return nil, false, err
-	}
-
-	if len(users) < limit {
-		var nameUsers []model.UserRecord
-		remainingLimit := limit - len(users)
-		remainingSkip := skip
-		if len(users) == 0 { //case where I have to skip some users with name containing text
-			amntWithUsername, err := u.userDb.GetAmountOfUsersWithUsernameContaining(text)
-			if err != nil {
-				err = app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting amount of users with username containing %s: %w", text, err))
-				return nil, false, err
-			}
-			remainingSkip = skip - amntWithUsername
-		}
-
-		nameUsers, hasMore, err = u.userDb.GetUsersWithOnlyNameContaining(text, timestamp, remainingSkip, remainingLimit)
-		if err != nil {
-			err = app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting users with name containing %s: %w", text, err))
-			return nil, false, err
-		}
-
-		users = append(users, nameUsers...)
-	}
-
-	profiles, err := u.getUserProfilesFromUserRecords(users, userSessionId)
-	if err != nil {
-		return nil, false, err
-	}
-
-	return profiles, hasMore, nil
-}
-
-// GetAllUsers retrieves all the users in the database, it is just for admins
-// it also receives a timestamp, skip and limit to paginate the results
-func (u *User) GetAllUsers(userSessionIsAdmin bool, timestamp string, skip int, limit int) ([]model.UserPublicProfile, bool, error) {
+// GetAllUsers retrieves a page of all the users in the database, it is just for admins
+func (u *User) GetAllUsers(userSessionIsAdmin bool, page model.PageRequest, language string) ([]model.UserPublicProfile, *model.Cursor, error) {
 	if !userSessionIsAdmin {
 		err := app_errors.NewAppError(http.StatusForbidden, UserIsNotAdmin, ErrUserIsNotAdmin)
-		return nil, false, err
+		return nil, nil, err
 	}
-	users, hasMore, err := u.userDb.GetAllUsers(timestamp, skip, limit)
+	users, next, err := u.userDb.GetAllUsers(page)
 	if err != nil {
 		err = app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting all users: %w", err))
-		return nil, false, err
+		return nil, nil, err
 	}
 
-	profiles, err := u.getPublicProfilesFromUserRecords(users)
+	profiles, err := u.getPublicProfilesFromUserRecords(users, language)
 	if err != nil {
 		err = app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting public profiles from user records: %w", err))
-		return nil, false, err
+		return nil, nil, err
 	}
 
-	return profiles, hasMore, nil
+	return profiles, next, nil
 }
 
-func (u *User) GetUserInformation(userSessionId uuid.UUID, userSessionIsAdmin bool, id uuid.UUID) (model.UserInformationResponse, error) {
+func (u *User) GetUserInformation(userSessionId uuid.UUID, userSessionIsAdmin bool, id uuid.UUID, language string) (model.UserInformationResponse, error) {
 	if !userSessionIsAdmin {
 		return model.UserInformationResponse{}, app_errors.NewAppError(http.StatusForbidden, UserIsNotAdmin, ErrUserIsNotAdmin)
 	}
@@ -88,7 +46,7 @@ func (u *User) GetUserInformation(userSessionId uuid.UUID, userSessionIsAdmin bo
 		return model.UserInformationResponse{}, err
 	}
 
-	profile, err := u.getPrivateProfile(userRecord)
+	profile, err := u.getPrivateProfile(userRecord, language)
 	if err != nil {
 		return model.UserInformationResponse{}, err
 	}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+	"users-service/src/app_errors"
+	"users-service/src/database"
+	"users-service/src/model"
+
+	"github.com/google/uuid"
+)
+
+func (u *User) GetUserIdentities(userId uuid.UUID) ([]model.UserIdentity, error) {
+	identities, err := u.userDb.GetUserIdentities(userId)
+	if err != nil {
+		return nil, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting user identities: %w", err))
+	}
+	return identities, nil
+}
+
+// LinkUserIdentity links the identity provider account of the token to the user, so the user can sign in with it.
+// The account can't be linked to another user and the user can link one account of each provider
+func (u *User) LinkUserIdentity(ctx context.Context, userId uuid.UUID, data model.LinkIdentityRequest) (model.UserIdentity, error) {
+	identity, err := u.verifyProviderToken(ctx, data.Provider, data.Metadata)
+	if err != nil {
+		return model.UserIdentity{}, err
+	}
+
+	linkedUserId, err := u.userDb.GetUserIdByIdentity(identity.Provider, identity.Subject)
+	if err == nil {
+		if linkedUserId == userId {
+			return model.UserIdentity{}, app_errors.NewAppError(http.StatusConflict, IdentityAlreadyLinked, fmt.Errorf("the %s identity is already linked to the user", identity.Provider))
+		}
+		return model.UserIdentity{}, app_errors.NewAppError(http.StatusConflict, IdentityLinkedToAnotherUser, fmt.Errorf("the %s identity is linked to another user", identity.Provider))
+	}
+	if !errors.Is(err, database.ErrKeyNotFound) {
+		return model.UserIdentity{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting user by identity: %w", err))
+	}
+
+	userIdentity := model.UserIdentity{
+		UserId:   userId,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	}
+	if err := u.userDb.AddUserIdentity(userIdentity); err != nil {
+		if errors.Is(err, database.ErrKeyAlreadyExists) {
+			return model.UserIdentity{}, app_errors.NewAppError(http.StatusConflict, IdentityAlreadyLinked, fmt.Errorf("the user has another %s identity", identity.Provider))
+		}
+		return model.UserIdentity{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error adding user identity: %w", err))
+	}
+
+	slog.Info("identity linked", slog.String("provider", identity.Provider), slog.String("user_id", userId.String()))
+	userIdentity.CreatedAt = time.Now()
+	return userIdentity, nil
+}
+
+// UnlinkUserIdentity unlinks the account of the provider from the user, as long as the user keeps another way to sign in
+func (u *User) UnlinkUserIdentity(userId uuid.UUID, provider string) error {
+	userRecord, err := u.userDb.GetUserById(userId)
+	if err != nil {
+		return app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting user by id: %w", err))
+	}
+	identities, err := u.userDb.GetUserIdentities(userId)
+	if err != nil {
+		return app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting user identities: %w", err))
+	}
+
+	if !slices.ContainsFunc(identities, func(identity model.UserIdentity) bool { return identity.Provider == provider }) {
+		return app_errors.NewAppError(http.StatusNotFound, IdentityNotLinked, fmt.Errorf("the user has no %s identity", provider))
+	}
+	if userRecord.Password == "" && len(identities) == 1 {
+		return app_errors.NewAppError(http.StatusConflict, LastLoginMethod, fmt.Errorf("the %s identity is the only login method of the user", provider))
+	}
+
+	if err := u.userDb.DeleteUserIdentity(userId, provider); err != nil {
+		if errors.Is(err, database.ErrKeyNotFound) {
+			return app_errors.NewAppError(http.StatusNotFound, IdentityNotLinked, fmt.Errorf("the user has no %s identity", provider))
+		}
+		return app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error deleting user identity: %w", err))
+	}
+
+	slog.Info("identity unlinked", slog.String("provider", provider), slog.String("user_id", userId.String()))
+	return nil
+}
@@ -11,14 +11,14 @@ import (
 	"users-service/src/model"
 )
 
-func (u *User) loginValidUser(userRecord model.UserRecord, provider *string) (string, model.UserPrivateProfile, error) {
+func (u *User) loginValidUser(userRecord model.UserRecord, provider *string, language string) (string, model.UserPrivateProfile, error) {
 	authToken, err := auth.GenerateToken(userRecord.Id.String(), false)
 
 	if err != nil {
 		return "", model.UserPrivateProfile{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error generating token: %w", err))
 	}
 
-	privateProfile, err := u.createUserPrivateProfileFromUserRecord(userRecord)
+	privateProfile, err := u.createUserPrivateProfileFromUserRecord(userRecord, language)
 
 	if err != nil {
 		return "", model.UserPrivateProfile{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error generating private profile: %w", err))
@@ -34,7 +34,7 @@ func (u *User) loginValidUser(userRecord model.UserRecord, provider *string) (st
 	return authToken, privateProfile, nil	
 }
 
-func (u *User) LoginUser(data model.UserLoginRequest) (string, model.UserPrivateProfile, error) {
+func (u *User) LoginUser(data model.UserLoginRequest, language string) (string, model.UserPrivateProfile, error) {
 	slog.Info("checking login information")
 
 	userRecord, err := u.userDb.GetUserByEmail(data.Email)
@@ -61,6 +61,6 @@ func (u *User) LoginUser(data model.UserLoginRequest) (string, model.UserPrivate
 		return "", model.UserPrivateProfile{}, app_errors.NewAppError(http.StatusForbidden, UserBlocked, errors.New("user is blocked"))
 	}
 
-	return u.loginValidUser(userRecord, nil)
+	return u.loginValidUser(userRecord, nil, language)
 }
 
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"users-service/src/app_errors"
+	"users-service/src/database"
+	"users-service/src/model"
+
+	"github.com/google/uuid"
+)
+
+// GetNearbyUsers retrieves a page of the users whose location is at most radiusKm away from the location
+// of the session user, the closest ones first, each with its distance rounded to tenths of a kilometer.
+// The distance is measured between the locations, so users in the same city are at distance 0
+func (u *User) GetNearbyUsers(userSessionId uuid.UUID, radiusKm float64, page model.PageRequest, language string) ([]model.UserProfileResponse, *model.Cursor, error) {
+	user, err := u.userDb.GetUserById(userSessionId)
+	if err != nil {
+		if errors.Is(err, database.ErrKeyNotFound) {
+			return nil, nil, app_errors.NewAppError(http.StatusNotFound, UsernameNotFound, err)
+		}
+		return nil, nil, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error retrieving user: %w", err))
+	}
+
+	locations, err := u.catalogs.get(model.LocationsCatalog)
+	if err != nil {
+		return nil, nil, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting locations: %w", err))
+	}
+
+	location := locations.byId[user.LocationId]
+	if !location.HasCoordinates() {
+		err := fmt.Errorf("location %d of user %s has no coordinates", user.LocationId, userSessionId)
+		return nil, nil, app_errors.NewAppError(http.StatusConflict, LocationWithoutCoordinates, err)
+	}
+
+	nearby, next, err := u.userDb.GetNearbyUsers(userSessionId, *location.Latitude, *location.Longitude, radiusKm, page)
+	if err != nil {
+		return nil, nil, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting nearby users: %w", err))
+	}
+
+	users := make([]model.UserRecord, len(nearby))
+	for i, record := range nearby {
+		users[i] = record.User
+	}
+
+	profiles, err := u.getUserProfilesFromUserRecords(users, userSessionId, language)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i, record := range nearby {
+		distance := math.Round(record.DistanceKm*10) / 10
+		profiles[i].DistanceKm = &distance
+	}
+
+	return profiles, next, nil
+}
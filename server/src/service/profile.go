@@ -8,13 +8,12 @@ import (
 	"strings"
 	"users-service/src/app_errors"
 	"users-service/src/database"
-	"users-service/src/database/register_options"
 	"users-service/src/model"
 
 	"github.com/google/uuid"
 )
 
-func (u *User) GetUserProfileById(userSessionId uuid.UUID, userSessionIsAdmin bool, id uuid.UUID) (model.UserProfileResponse, error) {
+func (u *User) GetUserProfileById(userSessionId uuid.UUID, userSessionIsAdmin bool, id uuid.UUID, language string) (model.UserProfileResponse, error) {
 	userRecord, err := u.userDb.GetUserById(id)
 	if err != nil {
 		if errors.Is(err, database.ErrKeyNotFound) {
@@ -23,28 +22,58 @@ func (u *User) GetUserProfileById(userSessionId uuid.UUID, userSessionIsAdmin bo
 		return model.UserProfileResponse{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error retrieving user: %w", err))
 	}
 
-	if userSessionId == id || userSessionIsAdmin {
-		return u.getPrivateProfile(userRecord)
-	}
-	return u.getPublicProfile(userRecord, userSessionId)
+	return u.getProfile(userSessionId, userSessionIsAdmin, userRecord, language)
 }
 
-func (u *User) getAmountOfFollowersAndFollowing(user model.UserRecord) (int, int, error) {
-	followers, err := u.userDb.GetAmountOfFollowers(user.Id)
+// GetUserProfileByUsername returns the profile of the user with the username, ignoring its case. If it is a
+// username a user left and still holds, the profile is empty and the current username of that user is returned
+// to redirect to it. The blocked users are only found by the admins and by themselves
+func (u *User) GetUserProfileByUsername(userSessionId uuid.UUID, userSessionIsAdmin bool, username string, language string) (model.UserProfileResponse, string, error) {
+	userRecord, err := u.userDb.GetUserByUsername(username)
+	if err == nil {
+		if !canSeeUser(userSessionId, userSessionIsAdmin, userRecord) {
+			return model.UserProfileResponse{}, "", app_errors.NewAppError(http.StatusNotFound, UsernameNotFound, ErrUserNotFound)
+		}
+		profile, err := u.getProfile(userSessionId, userSessionIsAdmin, userRecord, language)
+		return profile, "", err
+	}
+	if !errors.Is(err, database.ErrKeyNotFound) {
+		return model.UserProfileResponse{}, "", app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error retrieving user: %w", err))
+	}
+
+	holderId, err := u.userDb.GetUsernameHolder(username)
 	if err != nil {
-		return 0, 0, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting amount of followers: %w", err))
+		if errors.Is(err, database.ErrKeyNotFound) {
+			return model.UserProfileResponse{}, "", app_errors.NewAppError(http.StatusNotFound, UsernameNotFound, err)
+		}
+		return model.UserProfileResponse{}, "", app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error retrieving username holder: %w", err))
 	}
 
-	following, err := u.userDb.GetAmountOfFollowing(user.Id)
+	holder, err := u.userDb.GetUserById(holderId)
 	if err != nil {
-		return 0, 0, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting amount of following: %w", err))
+		return model.UserProfileResponse{}, "", app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error retrieving user: %w", err))
+	}
+	if !canSeeUser(userSessionId, userSessionIsAdmin, holder) {
+		return model.UserProfileResponse{}, "", app_errors.NewAppError(http.StatusNotFound, UsernameNotFound, ErrUserNotFound)
 	}
+	return model.UserProfileResponse{}, holder.UserName, nil
+}
+
+// canSeeUser tells if the session user can find the user, the blocked ones are hidden from the other users
+func canSeeUser(userSessionId uuid.UUID, userSessionIsAdmin bool, user model.UserRecord) bool {
+	return !user.Blocked || userSessionIsAdmin || userSessionId == user.Id
+}
 
-	return followers, following, nil
+// getProfile returns the private profile of the user to itself and to the admins, and the public one to the rest
+func (u *User) getProfile(userSessionId uuid.UUID, userSessionIsAdmin bool, user model.UserRecord, language string) (model.UserProfileResponse, error) {
+	if userSessionId == user.Id || userSessionIsAdmin {
+		return u.getPrivateProfile(user, language)
+	}
+	return u.getPublicProfile(user, userSessionId, language)
 }
 
-func (u *User) getPrivateProfile(user model.UserRecord) (model.UserProfileResponse, error) {
-	privateProfile, err := u.createUserPrivateProfileFromUserRecord(user)
+func (u *User) getPrivateProfile(user model.UserRecord, language string) (model.UserProfileResponse, error) {
+	privateProfile, err := u.createUserPrivateProfileFromUserRecord(user, language)
 	if err != nil {
 		return model.UserProfileResponse{}, err
 	}
@@ -57,22 +86,39 @@ func (u *User) getPrivateProfile(user model.UserRecord) (model.UserProfileRespon
 	}, nil
 }
 
-func (u *User) getPublicProfile(user model.UserRecord, session_user_id uuid.UUID) (model.UserProfileResponse, error) {
-	profile, err := u.generateUserPublicProfileFromUserRecord(user)
+func (u *User) getPublicProfile(user model.UserRecord, session_user_id uuid.UUID, language string) (model.UserProfileResponse, error) {
+	profile, err := u.generateUserPublicProfileFromUserRecord(user, language)
 	if err != nil {
 		return model.UserProfileResponse{}, err
 	}
 
-	follows, err := u.userDb.CheckIfUserFollows(session_user_id, user.Id)
+	relationships, err := u.getViewerRelationships(session_user_id, []uuid.UUID{user.Id})
 	if err != nil {
-		return model.UserProfileResponse{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error checking if user follows: %w", err))
+		return model.UserProfileResponse{}, err
 	}
 
 	slog.Info("user Public profile retrieved succesfully", slog.String("userId", user.Id.String()))
-	return model.UserProfileResponse{
-		OwnProfile: false,
-		Follows:    follows,
-		Profile:    profile,
+	return relationships.profileResponse(session_user_id, user.Id, profile), nil
+}
+
+// GetRelationship returns how the session user and another user are related
+func (u *User) GetRelationship(userSessionId uuid.UUID, id uuid.UUID) (model.RelationshipResponse, error) {
+	userRecord, err := u.userDb.GetUserById(id)
+	if err != nil {
+		if errors.Is(err, database.ErrKeyNotFound) {
+			return model.RelationshipResponse{}, app_errors.NewAppError(http.StatusNotFound, UsernameNotFound, err)
+		}
+		return model.RelationshipResponse{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error retrieving user: %w", err))
+	}
+
+	relationships, err := u.getViewerRelationships(userSessionId, []uuid.UUID{userRecord.Id})
+	if err != nil {
+		return model.RelationshipResponse{}, err
+	}
+
+	return model.RelationshipResponse{
+		Follows:    relationships.follows[userRecord.Id],
+		FollowedBy: relationships.followedBy[userRecord.Id],
 	}, nil
 }
 
@@ -80,7 +126,7 @@ func (u *User) validateUpdateUserPrivateProfile(data model.UpdateUserPrivateProf
 	totalValErrors := []model.ValidationError{}
 
 	if !strings.EqualFold(data.UserName, userRecord.UserName) {
-		if valErrs, err := u.userValidator.ValidateUpdateUsername(data.UserName); err != nil {
+		if valErrs, err := u.userValidator.ValidateUpdateUsername(userRecord.Id, data.UserName); err != nil {
 			return []model.ValidationError{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error validating username: %w", err))
 		} else if len(valErrs) > 0 {
 			totalValErrors = append(totalValErrors, valErrs...)
@@ -94,6 +140,12 @@ func (u *User) validateUpdateUserPrivateProfile(data model.UpdateUserPrivateProf
 		LastName:    data.LastName,
 		Location:    data.Location,
 		Interests:   data.Interests,
+		Bio:         data.Bio,
+		Website:     data.Website,
+		BirthDate:   data.BirthDate,
+		Pronouns:    data.Pronouns,
+		BannerPath:  data.BannerPath,
+		Visibility:  data.Visibility,
 	}
 
 	if valErrs, err := u.userValidator.ValidateUpdatePrivateProfileData(updateProfileData); err != nil {
@@ -106,7 +158,7 @@ func (u *User) validateUpdateUserPrivateProfile(data model.UpdateUserPrivateProf
 	return totalValErrors, nil
 }
 
-func (u *User) ModifyUserProfile(userSessionId uuid.UUID, data model.UpdateUserPrivateProfileRequest) (model.UserPrivateProfile, error) {
+func (u *User) ModifyUserProfile(userSessionId uuid.UUID, data model.UpdateUserPrivateProfileRequest, language string) (model.UserPrivateProfile, error) {
 	userRecord, err := u.userDb.GetUserById(userSessionId)
 	if err != nil {
 		if errors.Is(err, database.ErrKeyNotFound) {
@@ -123,25 +175,43 @@ func (u *User) ModifyUserProfile(userSessionId uuid.UUID, data model.UpdateUserP
 		return model.UserPrivateProfile{}, app_errors.NewAppValidationError(valErrs)
 	}
 
-	location := register_options.GetLocationName(data.Location)
-	interests := extractInterestNamesFromValidIds(data.Interests)
 	updateData := model.UpdateUserPrivateProfile{
 		UserName:    data.UserName,
 		PicturePath: data.PicturePath,
 		FirstName:   data.FirstName,
 		LastName:    data.LastName,
-		Location:    location,
-		Interests:   interests,
+		LocationId:  data.Location,
+		InterestIds: data.Interests,
+		Bio:         data.Bio,
+		Website:     data.Website,
+		BirthDate:   parseBirthDate(data.BirthDate),
+		Pronouns:    data.Pronouns,
+		BannerPath:  data.BannerPath,
+		Visibility:  mergeFieldVisibility(userRecord.Visibility, data.Visibility),
 	}
 
 	updatedUser, err := u.userDb.ModifyUser(userSessionId, updateData)
 	if err != nil {
 		return model.UserPrivateProfile{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error updating user profile: %w", err))
 	}
-	privateProfile, err := u.createUserPrivateProfileFromUserRecord(updatedUser)
+	privateProfile, err := u.createUserPrivateProfileFromUserRecord(updatedUser, language)
 	if err != nil {
 		return model.UserPrivateProfile{}, err
 	}
+	u.recommendationsRefresher.enqueue(userSessionId)
+
 	slog.Info("user profile updated succesfully", slog.String("userId", userSessionId.String()))
 	return privateProfile, nil
 }
+
+// mergeFieldVisibility returns the visibility of the fields with the given changes applied
+func mergeFieldVisibility(current model.FieldVisibility, changes map[string]string) model.FieldVisibility {
+	merged := make(model.FieldVisibility, len(current)+len(changes))
+	for field, visibility := range current {
+		merged[field] = visibility
+	}
+	for field, visibility := range changes {
+		merged[field] = visibility
+	}
+	return merged
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"users-service/src/app_errors"
+	"users-service/src/database"
+	"users-service/src/model"
+
+	"github.com/google/uuid"
+)
+
+// PatchUserProfile applies a JSON merge patch to the profile of the session user, only the fields in it are
+// validated and only the ones that changed are updated. If ifMatch is not empty the profile is only modified
+// if its ETag is among the given ones
+func (u *User) PatchUserProfile(userSessionId uuid.UUID, body []byte, ifMatch string, language string) (model.UserPrivateProfile, error) {
+	userRecord, err := u.userDb.GetUserById(userSessionId)
+	if err != nil {
+		if errors.Is(err, database.ErrKeyNotFound) {
+			return model.UserPrivateProfile{}, app_errors.NewAppError(http.StatusNotFound, UsernameNotFound, err)
+		}
+		return model.UserPrivateProfile{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error retrieving user: %w", err))
+	}
+
+	expectedVersion := 0
+	if ifMatch != "" {
+		matches, anyVersion := matchesVersion(ifMatch, userRecord.Version)
+		if !matches {
+			return model.UserPrivateProfile{}, app_errors.NewAppError(http.StatusPreconditionFailed, ProfileVersionMismatch, fmt.Errorf("the profile has version %d, expected %s", userRecord.Version, ifMatch))
+		}
+		if !anyVersion {
+			expectedVersion = userRecord.Version
+		}
+	}
+
+	patch, valErrs, err := decodeProfilePatch(body)
+	if err != nil {
+		return model.UserPrivateProfile{}, app_errors.NewAppError(http.StatusBadRequest, InvalidProfilePatch, err)
+	}
+	if len(valErrs) == 0 {
+		if valErrs, err = u.validateProfilePatch(patch, userRecord); err != nil {
+			return model.UserPrivateProfile{}, err
+		}
+	}
+	if len(valErrs) > 0 {
+		return model.UserPrivateProfile{}, app_errors.NewAppValidationError(valErrs)
+	}
+
+	changes := profileChanges(userRecord, patch)
+	if changes.IsEmpty() {
+		return u.createUserPrivateProfileFromUserRecord(userRecord, language)
+	}
+
+	updatedUser, err := u.userDb.PatchUser(userSessionId, expectedVersion, changes)
+	if err != nil {
+		if errors.Is(err, database.ErrVersionMismatch) {
+			return model.UserPrivateProfile{}, app_errors.NewAppError(http.StatusPreconditionFailed, ProfileVersionMismatch, err)
+		}
+		if errors.Is(err, database.ErrKeyNotFound) {
+			return model.UserPrivateProfile{}, app_errors.NewAppError(http.StatusNotFound, UsernameNotFound, err)
+		}
+		return model.UserPrivateProfile{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error patching user profile: %w", err))
+	}
+
+	privateProfile, err := u.createUserPrivateProfileFromUserRecord(updatedUser, language)
+	if err != nil {
+		return model.UserPrivateProfile{}, err
+	}
+	if changes.LocationId != nil || changes.InterestIds != nil {
+		u.recommendationsRefresher.enqueue(userSessionId)
+	}
+
+	slog.Info("user profile patched succesfully", slog.String("userId", userSessionId.String()), slog.Int("version", updatedUser.Version))
+	return privateProfile, nil
+}
+
+// matchesVersion tells if the version is among the entity tags of an If-Match header, and if the header
+// matches any version
+func matchesVersion(ifMatch string, version int) (bool, bool) {
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true, true
+		}
+		if tag == model.VersionETag(version) {
+			return true, false
+		}
+	}
+	return false, false
+}
+
+// decodeProfilePatch decodes a JSON merge patch of the private profile. The fields that can't be removed
+// or have the wrong type and the unknown ones are returned as validation errors
+func decodeProfilePatch(body []byte) (model.UserProfilePatch, []model.ValidationError, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return model.UserProfilePatch{}, nil, fmt.Errorf("the patch must be a JSON object: %w", err)
+	}
+
+	patch := model.UserProfilePatch{}
+	valErrs := []model.ValidationError{}
+	addError := func(field, message string) {
+		valErrs = append(valErrs, model.ValidationError{Field: field, Message: message})
+	}
+
+	for field, value := range fields {
+		isNull := string(value) == "null"
+
+		switch field {
+		case "username", "first_name", "last_name", "location", "interests":
+			if isNull {
+				addError(field, fmt.Sprintf("%s can't be removed", field))
+				continue
+			}
+		}
+
+		var err error
+		switch field {
+		case "username":
+			patch.UserName, err = decodeString(value)
+		case "first_name":
+			patch.FirstName, err = decodeString(value)
+		case "last_name":
+			patch.LastName, err = decodeString(value)
+		case "location":
+			err = json.Unmarshal(value, &patch.LocationId)
+		case "interests":
+			err = json.Unmarshal(value, &patch.InterestIds)
+		case "picture_path":
+			patch.PicturePath, err = decodeString(value)
+		case model.ProfileFieldBio:
+			patch.Bio, err = decodeString(value)
+		case model.ProfileFieldWebsite:
+			patch.Website, err = decodeString(value)
+		case model.ProfileFieldBirthDate:
+			patch.BirthDate, err = decodeString(value)
+		case model.ProfileFieldPronouns:
+			patch.Pronouns, err = decodeString(value)
+		case model.ProfileFieldBanner:
+			patch.BannerPath, err = decodeString(value)
+		case "visibility":
+			patch.Visibility, err = decodeVisibilityPatch(value)
+		default:
+			addError(field, "Unknown field")
+			continue
+		}
+
+		if err != nil {
+			addError(field, fmt.Sprintf("Invalid value for %s", field))
+		}
+	}
+
+	return patch, valErrs, nil
+}
+
+// decodeString decodes a string of the patch, null clears it so it is decoded as empty
+func decodeString(value json.RawMessage) (*string, error) {
+	var decoded *string
+	if err := json.Unmarshal(value, &decoded); err != nil {
+		return nil, err
+	}
+	if decoded == nil {
+		decoded = new(string)
+	}
+	return decoded, nil
+}
+
+// decodeVisibilityPatch decodes the patch of the visibility, null sets every field back to its default
+func decodeVisibilityPatch(value json.RawMessage) (map[string]*string, error) {
+	var visibility map[string]*string
+	if err := json.Unmarshal(value, &visibility); err != nil {
+		return nil, err
+	}
+	if visibility == nil {
+		visibility = make(map[string]*string)
+		for _, field := range model.ProfileFields() {
+			visibility[field] = nil
+		}
+	}
+	return visibility, nil
+}
+
+func (u *User) validateProfilePatch(patch model.UserProfilePatch, userRecord model.UserRecord) ([]model.ValidationError, error) {
+	totalValErrors := []model.ValidationError{}
+
+	if patch.UserName != nil && !strings.EqualFold(*patch.UserName, userRecord.UserName) {
+		if valErrs, err := u.userValidator.ValidateUpdateUsername(userRecord.Id, *patch.UserName); err != nil {
+			return nil, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error validating username: %w", err))
+		} else {
+			totalValErrors = append(totalValErrors, valErrs...)
+		}
+	}
+
+	if valErrs, err := u.userValidator.ValidateProfilePatch(patch); err != nil {
+		return nil, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error validating profile patch: %w", err))
+	} else {
+		totalValErrors = append(totalValErrors, valErrs...)
+	}
+
+	return totalValErrors, nil
+}
+
+// profileChanges returns the fields of the already validated patch that are different from the ones of the user
+func profileChanges(user model.UserRecord, patch model.UserProfilePatch) model.UserProfileChanges {
+	changes := model.UserProfileChanges{}
+	changedString := func(patched *string, current string) *string {
+		if patched == nil || *patched == current {
+			return nil
+		}
+		return patched
+	}
+
+	changes.UserName = changedString(patch.UserName, user.UserName)
+	changes.FirstName = changedString(patch.FirstName, user.FirstName)
+	changes.LastName = changedString(patch.LastName, user.LastName)
+	changes.PicturePath = changedString(patch.PicturePath, user.PicturePath)
+	changes.Bio = changedString(patch.Bio, user.Bio)
+	changes.Website = changedString(patch.Website, user.Website)
+	changes.Pronouns = changedString(patch.Pronouns, user.Pronouns)
+	changes.BannerPath = changedString(patch.BannerPath, user.BannerPath)
+
+	if patch.LocationId != nil && *patch.LocationId != user.LocationId {
+		changes.LocationId = patch.LocationId
+	}
+
+	if patch.InterestIds != nil {
+		patched, current := slices.Clone(*patch.InterestIds), slices.Clone(user.InterestIds)
+		slices.Sort(patched)
+		slices.Sort(current)
+		if !slices.Equal(patched, current) {
+			changes.InterestIds = *patch.InterestIds
+		}
+	}
+
+	if patch.BirthDate != nil && *patch.BirthDate != formatBirthDate(user.BirthDate) {
+		birthDate := sql.NullTime{}
+		if parsed := parseBirthDate(*patch.BirthDate); parsed != nil {
+			birthDate = sql.NullTime{Time: *parsed, Valid: true}
+		}
+		changes.BirthDate = &birthDate
+	}
+
+	if patch.Visibility != nil {
+		patched := user.Visibility.Patch(patch.Visibility)
+		if !maps.Equal(patched.WithDefaults(), user.Visibility.WithDefaults()) {
+			changes.Visibility = patched
+		}
+	}
+
+	return changes
+}
@@ -1,25 +1,97 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"time"
 	"users-service/src/app_errors"
+	"users-service/src/database"
 	"users-service/src/model"
 
 	"github.com/google/uuid"
 )
 
-func (u *User) RecommendUsers(userSessionId uuid.UUID, timestamp string, skip int, limit int) ([]model.UserProfileResponse, bool, error) {
-	users, hasMore, err := u.userDb.GetRecommendations(userSessionId, timestamp, skip, limit)
+// RecommendUsers retrieves a page of the users recommended for the session user, the best ones first,
+// each with the reasons it was recommended for
+func (u *User) RecommendUsers(userSessionId uuid.UUID, page model.PageRequest, language string) ([]model.UserProfileResponse, *model.Cursor, error) {
+	recommendations, next, err := u.userDb.GetRecommendations(userSessionId, page)
 	if err != nil {
 		err = app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting recommendations: %w", err))
-		return nil, false, err
+		return nil, nil, err
 	}
 
-	profiles, err := u.getUserProfilesFromUserRecords(users, userSessionId)
+	users := make([]model.UserRecord, len(recommendations))
+	for i, recommendation := range recommendations {
+		users[i] = recommendation.User
+	}
+
+	profiles, err := u.getUserProfilesFromUserRecords(users, userSessionId, language)
 	if err != nil {
-		return nil, false, err
+		return nil, nil, err
+	}
+
+	for i, recommendation := range recommendations {
+		profiles[i].Reasons = getRecommendationReasons(recommendation)
+	}
+
+	// the impressions only down-rank the recommendations, so failing to record them shouldn't fail the request
+	if err := u.userDb.RecordRecommendationImpressions(userSessionId, getUserIds(users), page.ListedAt()); err != nil {
+		slog.Warn("error recording recommendation impressions", slog.String("error", err.Error()))
 	}
 
-	return profiles, hasMore, nil
-}
\ No newline at end of file
+	return profiles, next, nil
+}
+
+// DismissRecommendation stops recommending a user to the session user until expiresAt, or forever if it is nil
+func (u *User) DismissRecommendation(userSessionId uuid.UUID, dismissedId uuid.UUID, expiresAt *time.Time) error {
+	if userSessionId == dismissedId {
+		return app_errors.NewAppError(http.StatusBadRequest, CantDismissYourself, fmt.Errorf("user %s can't dismiss itself", userSessionId))
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return app_errors.NewAppError(http.StatusBadRequest, InvalidDismissalExpiration, fmt.Errorf("expiration %s is not in the future", expiresAt))
+	}
+
+	if _, err := u.userDb.GetUserById(dismissedId); err != nil {
+		if errors.Is(err, database.ErrKeyNotFound) {
+			return app_errors.NewAppError(http.StatusNotFound, UsernameNotFound, err)
+		}
+		return app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error retrieving user: %w", err))
+	}
+
+	if err := u.userDb.DismissRecommendation(userSessionId, dismissedId, expiresAt); err != nil {
+		return app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error dismissing recommendation: %w", err))
+	}
+	return nil
+}
+
+// getRecommendationReasons describes the signals a user was recommended by, the strongest ones first
+func getRecommendationReasons(recommendation model.RecommendationRecord) []string {
+	var reasons []string
+	switch amount := recommendation.FollowedByFollowing; {
+	case amount == 1:
+		reasons = append(reasons, "followed by 1 person you follow")
+	case amount > 1:
+		reasons = append(reasons, fmt.Sprintf("followed by %d people you follow", amount))
+	}
+
+	switch amount := recommendation.SharedInterests; {
+	case amount == 1:
+		reasons = append(reasons, "1 interest in common")
+	case amount > 1:
+		reasons = append(reasons, fmt.Sprintf("%d interests in common", amount))
+	}
+
+	switch amount := recommendation.SharedCategories; {
+	case amount == 1:
+		reasons = append(reasons, "1 interest category in common")
+	case amount > 1:
+		reasons = append(reasons, fmt.Sprintf("%d interest categories in common", amount))
+	}
+
+	if recommendation.SameLocation {
+		reasons = append(reasons, "also from "+recommendation.User.Location)
+	}
+	return reasons
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+	"users-service/src/constants"
+	"users-service/src/database/users_db"
+
+	"github.com/google/uuid"
+)
+
+// recommendationsRefresher recomputes in the background the precomputed recommendations
+// of the users whose follows or profile changed
+type recommendationsRefresher struct {
+	userDb users_db.UserDatabase
+	queue  chan uuid.UUID
+}
+
+func (r *recommendationsRefresher) run() {
+	for userId := range r.queue {
+		if err := r.userDb.RefreshRecommendations(userId, constants.PrecomputedRecommendations); err != nil {
+			slog.Warn("error refreshing recommendations", slog.String("userId", userId.String()), slog.String("error", err.Error()))
+		}
+	}
+}
+
+// enqueue never blocks, if the queue is full the user is left for the periodic refresh.
+// It does nothing if the refresher was not started
+func (r *recommendationsRefresher) enqueue(userId uuid.UUID) {
+	if r == nil {
+		return
+	}
+
+	select {
+	case r.queue <- userId:
+	default:
+		slog.Warn("recommendations refresh queue is full", slog.String("userId", userId.String()))
+	}
+}
+
+// StartRecommendationsRefresher starts the worker that refreshes the recommendations of the users
+// right after their follows or profile change. Until it is started those changes are only picked up
+// by RefreshStaleRecommendations
+func (u *User) StartRecommendationsRefresher(queueSize int) {
+	u.recommendationsRefresher = &recommendationsRefresher{
+		userDb: u.userDb,
+		queue:  make(chan uuid.UUID, queueSize),
+	}
+	go u.recommendationsRefresher.run()
+}
+
+// RefreshStaleRecommendations refreshes the recommendations of all the users that were not refreshed
+// since the job started, in batches
+func (u *User) RefreshStaleRecommendations() error {
+	startedAt := time.Now()
+	refreshed := 0
+	for {
+		userIds, err := u.userDb.GetUsersWithStaleRecommendations(startedAt, constants.RecommendationsRefreshBatchSize)
+		if err != nil {
+			return fmt.Errorf("error getting users with stale recommendations: %w", err)
+		}
+
+		batchRefreshed := 0
+		for _, userId := range userIds {
+			if err := u.userDb.RefreshRecommendations(userId, constants.PrecomputedRecommendations); err != nil {
+				slog.Warn("error refreshing recommendations", slog.String("userId", userId.String()), slog.String("error", err.Error()))
+				continue
+			}
+			batchRefreshed++
+		}
+		refreshed += batchRefreshed
+
+		if len(userIds) < constants.RecommendationsRefreshBatchSize {
+			break
+		}
+		// the users that failed are returned again, so a batch without progress would loop forever
+		if batchRefreshed == 0 {
+			return fmt.Errorf("no recommendations could be refreshed in a batch of %d users", len(userIds))
+		}
+	}
+
+	slog.Info("recommendations refreshed", slog.Int("users", refreshed), slog.Duration("duration", time.Since(startedAt)))
+	return nil
+}
@@ -10,36 +10,11 @@ import (
 	"users-service/src/app_errors"
 	"users-service/src/constants"
 	"users-service/src/database"
-	"users-service/src/database/register_options"
 	"users-service/src/model"
 
 	"github.com/google/uuid"
 )
 
-func (u *User) GetLocations() map[string]interface{} {
-	locations := []model.Location{}
-	for id, name := range register_options.GetAllLocationsAndIds() {
-		locations = append(locations, model.Location{Id: id, Name: name})
-	}
-
-	slog.Info("locations retrieved successfully")
-	return map[string]interface{}{
-		"locations": locations,
-	}
-}
-func (u *User) GetInterests() map[string]interface{} {
-
-	interests := []model.Interest{}
-	for id, interest := range register_options.GetAllInterestsAndIds() {
-		interests = append(interests, model.Interest{Id: id, Interest: interest})
-	}
-
-	slog.Info("interests retrieved successfully")
-	return map[string]interface{}{
-		"interests": interests,
-	}
-}
-
 func (u *User) validateRegistryEntryExists(id uuid.UUID) error {
 	slog.Info("checking if registry entry exists")
 
@@ -182,8 +157,7 @@ func (u *User) AddInterests(id uuid.UUID, interestsIds []int) error {
 		return err
 	}
 
-	interestsNames := extractInterestNamesFromValidIds(interestsIds)
-	if err := u.registryDb.AddInterestsToRegistryEntry(id, interestsNames); err != nil {
+	if err := u.registryDb.AddInterestsToRegistryEntry(id, interestsIds); err != nil {
 		return app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error adding interests to registry entry: %w", err))
 	}
 
@@ -191,17 +165,30 @@ func (u *User) AddInterests(id uuid.UUID, interestsIds []int) error {
 	return nil
 }
 
-func (u *User) createUserFromRegistry(registry model.RegistryEntry) (model.UserPrivateProfile, error) {
+func (u *User) createUserFromRegistry(registry model.RegistryEntry) (model.UserRecord, error) {
 	userRecord := generateUserRecordFromRegistryEntry(registry)
-	createdUser, err := u.userDb.CreateUser(userRecord)
-	if err != nil {
-		return model.UserPrivateProfile{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error creating user: %w", err))
+
+	// the users that registered with an identity provider sign in with its subject from now on
+	var identity *model.UserIdentity
+	if registry.IdentitySubject != "" {
+		identity = &model.UserIdentity{
+			Provider: registry.IdentityProvider,
+			Subject:  registry.IdentitySubject,
+			Email:    registry.Email,
+		}
 	}
 
-	return u.createUserPrivateProfileFromUserRecord(createdUser)
+	createdUser, err := u.userDb.CreateUser(userRecord, identity)
+	if err != nil {
+		if errors.Is(err, database.ErrKeyAlreadyExists) {
+			return model.UserRecord{}, app_errors.NewAppError(http.StatusConflict, IdentityLinkedToAnotherUser, fmt.Errorf("the %s identity is linked to another user", registry.IdentityProvider))
+		}
+		return model.UserRecord{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error creating user: %w", err))
+	}
+	return createdUser, nil
 }
 
-func (u *User) CompleteRegistry(id uuid.UUID) (model.UserPrivateProfile, error) {
+func (u *User) CompleteRegistry(id uuid.UUID, language string) (model.UserPrivateProfile, error) {
 	slog.Info("completing registry")
 
 	if err := u.validateRegistryStep(id, constants.CompleteStep); err != nil {
@@ -213,17 +200,24 @@ func (u *User) CompleteRegistry(id uuid.UUID) (model.UserPrivateProfile, error)
 		return model.UserPrivateProfile{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting registry entry: %w", err))
 	}
 
+	// the registry is kept until the user is created, so a registration that fails can be completed again.
+	// Once it is created the email resolves to the user, so a registry that couldn't be deleted is left behind
+	createdUser, err := u.createUserFromRegistry(registry)
+	if err != nil {
+		return model.UserPrivateProfile{}, err
+	}
+
 	if err := u.registryDb.DeleteRegistryEntry(id); err != nil {
-		return model.UserPrivateProfile{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error deleting registry entry: %w", err))
+		slog.Error("error deleting registry entry", slog.String("registration_id", id.String()), slog.String("error", err.Error()))
 	}
 
-	userResponse, err := u.createUserFromRegistry(registry)
+	userResponse, err := u.createUserPrivateProfileFromUserRecord(createdUser, language)
 	if err != nil {
 		return model.UserPrivateProfile{}, err
 	}
 
 	if u.amqpQueue != nil {
-		if err := u.sendNewUserMessage(userResponse.Id.String(), userResponse.Location, registry.Id.String()); err != nil {
+		if err := u.sendNewUserMessage(createdUser.Id.String(), createdUser.Location, registry.Id.String()); err != nil {
 			slog.Warn("error sending new user message", slog.String("error", err.Error()))
 		}
 	}
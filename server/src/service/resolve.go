@@ -1,12 +1,20 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
 	"users-service/src/app_errors"
+	"users-service/src/auth"
 	"users-service/src/constants"
+	"users-service/src/database"
 	"users-service/src/model"
+
+	"github.com/google/uuid"
 )
 
 func (u *User) checkIfEmailHasAccount(email string) (bool, error) {
@@ -22,11 +30,21 @@ func (u *User) checkIfEmailHasAccount(email string) (bool, error) {
 
 }
 
-func (u *User) createNewRegistry(email string, identityProvider *string) (model.ResolveResponse, error) {
+func (u *User) createNewRegistry(email string, identity *auth.VerifiedIdentity) (model.ResolveResponse, error) {
+	var identityProvider *string
+	if identity != nil {
+		identityProvider = &identity.Provider
+	}
+
 	registryId, err := u.registryDb.CreateRegistryEntry(email, identityProvider)
 	if err != nil {
 		return model.ResolveResponse{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error creating registry entry: %w", err))
 	}
+	if identity != nil {
+		if err := u.registryDb.SetRegistryEntryIdentity(registryId, identity.Provider, identity.Subject); err != nil {
+			return model.ResolveResponse{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error setting registry entry identity: %w", err))
+		}
+	}
 
 	if u.amqpQueue != nil {
 		if err := u.sendNewRegistryMessage(registryId.String(), identityProvider); err != nil {
@@ -43,11 +61,16 @@ func (u *User) createNewRegistry(email string, identityProvider *string) (model.
 	}, nil
 }
 
-func (u *User) resolveExistingRegistry(email string) (model.ResolveResponse, error) {
+func (u *User) resolveExistingRegistry(email string, identity *auth.VerifiedIdentity) (model.ResolveResponse, error) {
 	registry, err := u.registryDb.GetRegistryEntryByEmail(email)
 	if err != nil {
 		return model.ResolveResponse{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting registry entry: %w", err))
 	}
+	if identity != nil {
+		if err := u.registryDb.SetRegistryEntryIdentity(registry.Id, identity.Provider, identity.Subject); err != nil {
+			return model.ResolveResponse{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error setting registry entry identity: %w", err))
+		}
+	}
 
 	slog.Info("user email resolved successfully: it has registry", slog.String("email", email))
 	return model.ResolveResponse{
@@ -59,12 +82,69 @@ func (u *User) resolveExistingRegistry(email string) (model.ResolveResponse, err
 	}, nil
 }
 
-func (u *User) resolveAccountWithIdentityProvider(email string, provider *string) (model.ResolveResponse, error) {
-	userRecord, err := u.userDb.GetUserByEmail(email)
+// resolveRegistry continues the registry of the email, or starts one if it has none
+func (u *User) resolveRegistry(email string, identity *auth.VerifiedIdentity) (model.ResolveResponse, error) {
+	exists, err := u.registryDb.CheckIfRegistryEntryExistsByEmail(email)
+	if err != nil {
+		return model.ResolveResponse{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error checking if registry entry exists: %w", err))
+	}
+
+	if exists {
+		slog.Info("user email resolved successfully: it has registry entry", slog.String("email", email))
+		return u.resolveExistingRegistry(email, identity)
+	}
+
+	slog.Info("user email resolved successfully: it doesnt have account", slog.String("email", email))
+	return u.createNewRegistry(email, identity)
+}
+
+func (u *User) ResolveUserEmail(email string, language string) (model.ResolveResponse, error) {
+	if valErrs, err := u.userValidator.ValidateEmail(email); err != nil {
+		return model.ResolveResponse{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error validating mail: %w", err))
+	} else if len(valErrs) > 0 {
+		return model.ResolveResponse{}, app_errors.NewAppValidationError(valErrs)
+	}
+
+	hasAccount, err := u.checkIfEmailHasAccount(email)
+	if err != nil {
+		return model.ResolveResponse{}, err
+	}
+
+	if hasAccount {
+		slog.Info("user email resolved successfully: it has account", slog.String("email", email))
+		return model.ResolveResponse{
+			NextAuthStep: constants.LoginStep,
+			Metadata:     nil,
+		}, nil
+	}
+
+	return u.resolveRegistry(email, nil)
+}
+
+// providerToken reads the token of the provider metadata, Google sends a Firebase token and the OpenID Connect providers an ID token
+func providerToken(provider string, metadata json.RawMessage) (string, error) {
+	if provider == constants.GoogleProvider {
+		var googleMetadata model.GoogleAuthMetadata
+		if err := json.Unmarshal(metadata, &googleMetadata); err != nil || googleMetadata.FirebaseTokenId == "" {
+			return "", fmt.Errorf("the %s metadata needs a firebase_token_id", provider)
+		}
+		return googleMetadata.FirebaseTokenId, nil
+	}
+
+	var oidcMetadata model.OIDCAuthMetadata
+	if err := json.Unmarshal(metadata, &oidcMetadata); err != nil || oidcMetadata.IdToken == "" {
+		return "", fmt.Errorf("the %s metadata needs an id_token", provider)
+	}
+	return oidcMetadata.IdToken, nil
+}
+
+// loginWithIdentity logs in the user the identity is bound to
+func (u *User) loginWithIdentity(userId uuid.UUID, provider string, language string) (model.ResolveResponse, error) {
+	userRecord, err := u.userDb.GetUserById(userId)
 	if err != nil {
-		return model.ResolveResponse{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting user by email: %w", err))
+		return model.ResolveResponse{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting user by id: %w", err))
 	}
-	token, profile, err := u.loginValidUser(userRecord, provider)
+	token, profile, err := u.loginValidUser(userRecord, &provider, language)
 	if err != nil {
 		return model.ResolveResponse{}, err
 	}
@@ -77,39 +157,116 @@ func (u *User) resolveAccountWithIdentityProvider(email string, provider *string
 	}, nil
 }
 
-func (u *User) ResolveUserEmail(email string, identityProvider *string) (model.ResolveResponse, error) {
-	if valErrs, err := u.userValidator.ValidateEmail(email); err != nil {
+// bindRegisteredIdentity links the identity to the account of its verified email if the account was registered
+// with the provider before the identities were stored, and tells if it was. It is done once, an account that
+// unlinks the provider afterwards is not linked again
+func (u *User) bindRegisteredIdentity(identity auth.VerifiedIdentity) (uuid.UUID, bool, error) {
+	claimed, err := u.registryDb.ClaimRegistryIdentity(identity.Email, identity.Provider, identity.Subject)
+	if err != nil {
+		return uuid.Nil, false, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error claiming registry identity: %w", err))
+	}
+	if !claimed {
+		return uuid.Nil, false, nil
+	}
+
+	userRecord, err := u.userDb.GetUserByEmail(identity.Email)
+	if err != nil {
+		return uuid.Nil, false, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting user by email: %w", err))
+	}
+
+	err = u.userDb.AddUserIdentity(model.UserIdentity{
+		UserId:   userRecord.Id,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrKeyAlreadyExists) {
+			return uuid.Nil, false, app_errors.NewAppError(http.StatusConflict, IdentityAlreadyLinked, fmt.Errorf("the user %s has another %s identity", userRecord.Id, identity.Provider))
+		}
+		return uuid.Nil, false, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error adding user identity: %w", err))
+	}
+
+	slog.Info("registered identity bound to account", slog.String("provider", identity.Provider), slog.String("user_id", userRecord.Id.String()))
+	return userRecord.Id, true, nil
+}
+
+// verifyProviderToken verifies the token of the provider metadata and returns the identity it vouches for
+func (u *User) verifyProviderToken(ctx context.Context, provider string, metadata json.RawMessage) (auth.VerifiedIdentity, error) {
+	verifier, ok := u.identityProviders.Get(provider)
+	if !ok {
+		return auth.VerifiedIdentity{}, app_errors.NewAppError(http.StatusBadRequest, UnknownProvider, fmt.Errorf("unknown provider: '%s'", provider))
+	}
+
+	token, err := providerToken(provider, metadata)
+	if err != nil {
+		return auth.VerifiedIdentity{}, app_errors.NewAppError(http.StatusBadRequest, "Invalid data in request", err)
+	}
+
+	identity, err := verifier.Verify(ctx, token)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidIdentityToken):
+			return auth.VerifiedIdentity{}, app_errors.NewAppError(http.StatusUnauthorized, InvalidProviderToken, err)
+		case errors.Is(err, auth.ErrUnverifiedEmail):
+			return auth.VerifiedIdentity{}, app_errors.NewAppError(http.StatusUnauthorized, UnverifiedProviderEmail, err)
+		default:
+			return auth.VerifiedIdentity{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error verifying %s token: %w", provider, err))
+		}
+	}
+	identity.Provider = provider
+
+	slog.Info("identity provider token verified", slog.String("provider", provider), slog.String("subject", identity.Subject))
+	return identity, nil
+}
+
+// ResolveUserWithProvider resolves the user of a token issued by one of the identity providers. The user is the one
+// the provider subject is linked to, the email is the one the provider verified and the one sent by the client must match it.
+// An account is only linked by its email when it was registered with the provider before the identities were stored,
+// otherwise its user has to log in and link the provider
+func (u *User) ResolveUserWithProvider(ctx context.Context, provider string, email string, metadata json.RawMessage, language string) (model.ResolveResponse, error) {
+	identity, err := u.verifyProviderToken(ctx, provider, metadata)
+	if err != nil {
+		return model.ResolveResponse{}, err
+	}
+
+	if email != "" && !strings.EqualFold(email, identity.Email) {
+		return model.ResolveResponse{}, app_errors.NewAppError(http.StatusUnauthorized, ProviderEmailMismatch, fmt.Errorf("the %s token was issued for another email", provider))
+	}
+
+	userId, err := u.userDb.GetUserIdByIdentity(provider, identity.Subject)
+	if err == nil {
+		return u.loginWithIdentity(userId, provider, language)
+	}
+	if !errors.Is(err, database.ErrKeyNotFound) {
+		return model.ResolveResponse{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting user by identity: %w", err))
+	}
+
+	if valErrs, err := u.userValidator.ValidateEmail(identity.Email); err != nil {
 		return model.ResolveResponse{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error validating mail: %w", err))
 	} else if len(valErrs) > 0 {
 		return model.ResolveResponse{}, app_errors.NewAppValidationError(valErrs)
 	}
 
-	hasAccount, err := u.checkIfEmailHasAccount(email)
+	hasAccount, err := u.checkIfEmailHasAccount(identity.Email)
 	if err != nil {
 		return model.ResolveResponse{}, err
 	}
-
 	if hasAccount {
-		slog.Info("user email resolved successfully: it has account", slog.String("email", email))
-		if identityProvider != nil {
-			return u.resolveAccountWithIdentityProvider(email, identityProvider)
+		userId, bound, err := u.bindRegisteredIdentity(identity)
+		if err != nil {
+			return model.ResolveResponse{}, err
+		}
+		if bound {
+			return u.loginWithIdentity(userId, provider, language)
 		}
+
+		slog.Info("user email resolved successfully: its account is not linked to the provider", slog.String("provider", provider))
 		return model.ResolveResponse{
 			NextAuthStep: constants.LoginStep,
 			Metadata:     nil,
 		}, nil
 	}
 
-	exists, err := u.registryDb.CheckIfRegistryEntryExistsByEmail(email)
-	if err != nil {
-		return model.ResolveResponse{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error checking if registry entry exists: %w", err))
-	}
-
-	if exists {
-		slog.Info("user email resolved successfully: it has registry entry", slog.String("email", email))
-		return u.resolveExistingRegistry(email)
-	}
-
-	slog.Info("user email resolved successfully: it doesnt have account", slog.String("email", email))
-	return u.createNewRegistry(email, identityProvider)
+	return u.resolveRegistry(identity.Email, &identity)
 }
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"users-service/src/app_errors"
+	"users-service/src/model"
+
+	"github.com/google/uuid"
+)
+
+// SearchUsers retrieves a page of the users whose username or name match the text, ranked by relevance:
+// exact username first, then username prefix, username containing the text, name containing the text
+// and finally the ones that are just similar to it, so typos still find results.
+// Each result has the parts of its fields that matched the text highlighted.
+// The results can be filtered by location and interests, and with an empty text the users are just browsed by them
+func (u *User) SearchUsers(userSessionId uuid.UUID, text string, filtersRequest model.SearchFiltersRequest, page model.PageRequest, language string) ([]model.UserProfileResponse, *model.Cursor, error) {
+	text = strings.TrimSpace(text)
+	filters, err := u.getUserSearchFilters(filtersRequest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	users, next, err := u.userDb.SearchUsers(text, filters, page)
+	if err != nil {
+		err = app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error searching users with %s: %w", text, err))
+		return nil, nil, err
+	}
+
+	profiles, err := u.getUserProfilesFromUserRecords(users, userSessionId, language)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	highlighter := newSearchHighlighter(text)
+	for i, user := range users {
+		profiles[i].Highlights = highlighter.highlight(user)
+	}
+
+	return profiles, next, nil
+}
+
+// getUserSearchFilters checks the ids of the filters against the catalogs. Deprecated entries can be searched,
+// since there are still users that have them
+func (u *User) getUserSearchFilters(request model.SearchFiltersRequest) (model.UserSearchFilters, error) {
+	filters := model.UserSearchFilters{}
+	if request.LocationId != nil {
+		locations, err := u.catalogs.get(model.LocationsCatalog)
+		if err != nil {
+			return model.UserSearchFilters{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting locations: %w", err))
+		}
+		if !locations.exists(*request.LocationId) {
+			err := app_errors.NewAppError(http.StatusBadRequest, InvalidLocation, fmt.Errorf("invalid location id: %d", *request.LocationId))
+			return model.UserSearchFilters{}, err
+		}
+		filters.LocationId = request.LocationId
+	}
+
+	if len(request.InterestIds) > 0 {
+		interests, err := u.catalogs.get(model.InterestsCatalog)
+		if err != nil {
+			return model.UserSearchFilters{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting interests: %w", err))
+		}
+		for _, interestId := range request.InterestIds {
+			if !interests.exists(interestId) {
+				err := app_errors.NewAppError(http.StatusBadRequest, InvalidInterest, fmt.Errorf("invalid interest id: %d", interestId))
+				return model.UserSearchFilters{}, err
+			}
+		}
+		filters.InterestIds = request.InterestIds
+	}
+
+	if len(request.CategoryIds) > 0 {
+		categories, err := u.catalogs.get(model.InterestCategoriesCatalog)
+		if err != nil {
+			return model.UserSearchFilters{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting interest categories: %w", err))
+		}
+		for _, categoryId := range request.CategoryIds {
+			if !categories.exists(categoryId) {
+				err := app_errors.NewAppError(http.StatusBadRequest, InvalidInterestCategory, fmt.Errorf("invalid interest category id: %d", categoryId))
+				return model.UserSearchFilters{}, err
+			}
+		}
+		filters.CategoryIds = request.CategoryIds
+	}
+
+	return filters, nil
+}
+
+// searchHighlighter wraps the words of a search text found in the fields of a user in <em> tags
+type searchHighlighter struct {
+	pattern *regexp.Regexp
+}
+
+func newSearchHighlighter(text string) searchHighlighter {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return searchHighlighter{}
+	}
+
+	// longer words first so they take precedence over the shorter ones they contain
+	sort.Slice(words, func(i, j int) bool { return len(words[i]) > len(words[j]) })
+	for i, word := range words {
+		words[i] = regexp.QuoteMeta(word)
+	}
+
+	return searchHighlighter{pattern: regexp.MustCompile("(?i)" + strings.Join(words, "|"))}
+}
+
+// wrapMatches escapes the value, since it is user input, and wraps the matched ranges in <em> tags
+func wrapMatches(value string, matches [][]int) string {
+	var builder strings.Builder
+	last := 0
+	for _, match := range matches {
+		builder.WriteString(html.EscapeString(value[last:match[0]]))
+		builder.WriteString("<em>" + html.EscapeString(value[match[0]:match[1]]) + "</em>")
+		last = match[1]
+	}
+	builder.WriteString(html.EscapeString(value[last:]))
+	return builder.String()
+}
+
+// highlight returns the fields of the user that contain any of the words, with them highlighted.
+// Users that only matched by similarity may have no highlighted fields
+func (h searchHighlighter) highlight(user model.UserRecord) map[string]string {
+	if h.pattern == nil {
+		return nil
+	}
+
+	fields := map[string]string{
+		"username":   user.UserName,
+		"first_name": user.FirstName,
+		"last_name":  user.LastName,
+	}
+
+	highlights := map[string]string{}
+	for field, value := range fields {
+		if matches := h.pattern.FindAllStringIndex(value, -1); len(matches) > 0 {
+			highlights[field] = wrapMatches(value, matches)
+		}
+	}
+
+	if len(highlights) == 0 {
+		return nil
+	}
+	return highlights
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"users-service/src/app_errors"
+	"users-service/src/auth"
+	"users-service/src/constants"
+	"users-service/src/database"
+	"users-service/src/model"
+
+	"github.com/google/uuid"
+)
+
+const clientCredentialsGrant = "client_credentials"
+
+// CreateServiceClient registers a service client with the given scopes, its secret is only returned now
+func (u *User) CreateServiceClient(userSessionIsAdmin bool, request model.CreateServiceClientRequest) (model.ServiceClientCredentials, error) {
+	if !userSessionIsAdmin {
+		return model.ServiceClientCredentials{}, app_errors.NewAppError(http.StatusForbidden, UserIsNotAdmin, ErrUserIsNotAdmin)
+	}
+
+	name := strings.TrimSpace(request.Name)
+	if name == "" || utf8.RuneCountInString(name) > constants.MaxServiceClientNameLength {
+		return model.ServiceClientCredentials{}, app_errors.NewAppError(http.StatusBadRequest, InvalidServiceClient, fmt.Errorf("the name must have between 1 and %d characters", constants.MaxServiceClientNameLength))
+	}
+	scopes, err := validateServiceScopes(request.Scopes)
+	if err != nil {
+		return model.ServiceClientCredentials{}, err
+	}
+
+	secret, secretHash, err := generateServiceSecret()
+	if err != nil {
+		return model.ServiceClientCredentials{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, err)
+	}
+
+	client, err := u.userDb.CreateServiceClient(name, scopes, secretHash)
+	if err != nil {
+		if errors.Is(err, database.ErrKeyAlreadyExists) {
+			return model.ServiceClientCredentials{}, app_errors.NewAppError(http.StatusConflict, ServiceClientAlreadyExists, err)
+		}
+		return model.ServiceClientCredentials{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error creating service client: %w", err))
+	}
+
+	slog.Info("service client created", slog.String("clientId", client.Id.String()), slog.String("name", client.Name))
+	return model.ServiceClientCredentials{Client: createServiceClient(client), ClientSecret: secret}, nil
+}
+
+// GetServiceClients returns every service client, the revoked ones included
+func (u *User) GetServiceClients(userSessionIsAdmin bool) ([]model.ServiceClient, error) {
+	if !userSessionIsAdmin {
+		return nil, app_errors.NewAppError(http.StatusForbidden, UserIsNotAdmin, ErrUserIsNotAdmin)
+	}
+
+	records, err := u.userDb.GetServiceClients()
+	if err != nil {
+		return nil, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error retrieving service clients: %w", err))
+	}
+
+	clients := make([]model.ServiceClient, len(records))
+	for i, record := range records {
+		clients[i] = createServiceClient(record)
+	}
+	return clients, nil
+}
+
+// RotateServiceClientSecret generates a new secret for the client, the previous one keeps working
+// for ServiceSecretGracePeriod so the client can switch to the new one without downtime
+func (u *User) RotateServiceClientSecret(userSessionIsAdmin bool, id uuid.UUID) (model.ServiceClientCredentials, error) {
+	if !userSessionIsAdmin {
+		return model.ServiceClientCredentials{}, app_errors.NewAppError(http.StatusForbidden, UserIsNotAdmin, ErrUserIsNotAdmin)
+	}
+
+	secret, secretHash, err := generateServiceSecret()
+	if err != nil {
+		return model.ServiceClientCredentials{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, err)
+	}
+
+	client, err := u.userDb.RotateServiceClientSecret(id, secretHash, time.Now().Add(constants.ServiceSecretGracePeriod))
+	if err != nil {
+		if errors.Is(err, database.ErrKeyNotFound) {
+			return model.ServiceClientCredentials{}, app_errors.NewAppError(http.StatusNotFound, ServiceClientNotFound, err)
+		}
+		return model.ServiceClientCredentials{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error rotating service client secret: %w", err))
+	}
+
+	slog.Info("service client secret rotated", slog.String("clientId", client.Id.String()))
+	return model.ServiceClientCredentials{Client: createServiceClient(client), ClientSecret: secret}, nil
+}
+
+// RevokeServiceClient revokes a service client, its secrets and the tokens already issued to it stop working
+func (u *User) RevokeServiceClient(userSessionIsAdmin bool, id uuid.UUID) error {
+	if !userSessionIsAdmin {
+		return app_errors.NewAppError(http.StatusForbidden, UserIsNotAdmin, ErrUserIsNotAdmin)
+	}
+
+	if err := u.userDb.RevokeServiceClient(id); err != nil {
+		if errors.Is(err, database.ErrKeyNotFound) {
+			return app_errors.NewAppError(http.StatusNotFound, ServiceClientNotFound, err)
+		}
+		return app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error revoking service client: %w", err))
+	}
+
+	slog.Info("service client revoked", slog.String("clientId", id.String()))
+	return nil
+}
+
+// IssueServiceToken exchanges the credentials of a service client for a token with the requested scopes,
+// or with all of its scopes if none are requested
+func (u *User) IssueServiceToken(request model.ServiceTokenRequest) (model.ServiceTokenResponse, error) {
+	if request.GrantType != clientCredentialsGrant {
+		return model.ServiceTokenResponse{}, app_errors.NewAppError(http.StatusBadRequest, UnsupportedGrantType, fmt.Errorf("unsupported grant type: '%s'", request.GrantType))
+	}
+
+	invalidCredentials := func(err error) error {
+		return app_errors.NewAppError(http.StatusUnauthorized, InvalidClientCredentials, err)
+	}
+
+	clientId, err := uuid.Parse(request.ClientId)
+	if err != nil {
+		return model.ServiceTokenResponse{}, invalidCredentials(err)
+	}
+	client, err := u.userDb.GetServiceClient(clientId)
+	if err != nil {
+		if errors.Is(err, database.ErrKeyNotFound) {
+			return model.ServiceTokenResponse{}, invalidCredentials(err)
+		}
+		return model.ServiceTokenResponse{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error retrieving service client: %w", err))
+	}
+	if client.RevokedAt != nil {
+		return model.ServiceTokenResponse{}, invalidCredentials(fmt.Errorf("the service client %s was revoked", client.Id))
+	}
+	if !isServiceClientSecret(client, request.ClientSecret) {
+		return model.ServiceTokenResponse{}, invalidCredentials(fmt.Errorf("wrong secret for service client %s", client.Id))
+	}
+
+	scopes := client.Scopes
+	if requested := strings.Fields(request.Scope); len(requested) > 0 {
+		for _, scope := range requested {
+			if !slices.Contains(client.Scopes, scope) {
+				return model.ServiceTokenResponse{}, app_errors.NewAppError(http.StatusForbidden, InvalidServiceScope, fmt.Errorf("the service client %s was not granted %s", client.Id, scope))
+			}
+		}
+		scopes = requested
+	}
+
+	token, err := auth.GenerateServiceToken(client.Id.String(), scopes, constants.ServiceTokenDuration)
+	if err != nil {
+		return model.ServiceTokenResponse{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error generating service token: %w", err))
+	}
+
+	slog.Info("service token issued", slog.String("clientId", client.Id.String()), slog.String("scope", strings.Join(scopes, " ")))
+	return model.ServiceTokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(constants.ServiceTokenDuration.Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	}, nil
+}
+
+// CheckIfServiceClientIsActive tells if the service client exists and was not revoked
+func (u *User) CheckIfServiceClientIsActive(id uuid.UUID) (bool, error) {
+	client, err := u.userDb.GetServiceClient(id)
+	if err != nil {
+		if errors.Is(err, database.ErrKeyNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return client.RevokedAt == nil, nil
+}
+
+func validateServiceScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, app_errors.NewAppError(http.StatusBadRequest, InvalidServiceClient, fmt.Errorf("a service client needs at least one scope"))
+	}
+
+	valid := []string{}
+	for _, scope := range scopes {
+		if !slices.Contains(constants.ServiceScopes, scope) {
+			return nil, app_errors.NewAppError(http.StatusBadRequest, InvalidServiceClient, fmt.Errorf("unknown scope: '%s'", scope))
+		}
+		if !slices.Contains(valid, scope) {
+			valid = append(valid, scope)
+		}
+	}
+	return valid, nil
+}
+
+// generateServiceSecret returns a random secret and its hash. The secrets are random enough
+// for a plain SHA-256 to be safe, so checking them doesn't cost as much as a password
+func generateServiceSecret() (string, string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", "", fmt.Errorf("error generating service client secret: %w", err)
+	}
+	secret := base64.RawURLEncoding.EncodeToString(bytes)
+	return secret, hashServiceSecret(secret), nil
+}
+
+func hashServiceSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// isServiceClientSecret tells if the secret is the current one of the client, or the previous one
+// during the grace period after a rotation
+func isServiceClientSecret(client model.ServiceClientRecord, secret string) bool {
+	hash := hashServiceSecret(secret)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(client.SecretHash)) == 1 {
+		return true
+	}
+	return client.PreviousSecretHash != "" &&
+		client.PreviousSecretExpiresAt != nil && time.Now().Before(*client.PreviousSecretExpiresAt) &&
+		subtle.ConstantTimeCompare([]byte(hash), []byte(client.PreviousSecretHash)) == 1
+}
+
+func createServiceClient(record model.ServiceClientRecord) model.ServiceClient {
+	return model.ServiceClient{
+		Id:        record.Id,
+		Name:      record.Name,
+		Scopes:    record.Scopes,
+		CreatedAt: record.CreatedAt,
+		RotatedAt: record.RotatedAt,
+		RevokedAt: record.RevokedAt,
+	}
+}
@@ -1,23 +1,41 @@
 package service
 
 import (
+	amqp "github.com/rabbitmq/amqp091-go"
+	"time"
+	"users-service/src/auth"
+	"users-service/src/cache"
+	"users-service/src/database/catalog_db"
 	"users-service/src/database/registry_db"
 	"users-service/src/database/users_db"
-	amqp "github.com/rabbitmq/amqp091-go"
+	"users-service/src/model"
 )
 
 type User struct {
-	userDb        users_db.UserDatabase
-	registryDb    registry_db.RegistryDatabase
-	userValidator *UserValidator
-	amqpQueue	 *amqp.Channel	
+	userDb            users_db.UserDatabase
+	registryDb        registry_db.RegistryDatabase
+	catalogDb         catalog_db.CatalogDatabase
+	catalogs          *catalogs
+	userValidator     *UserValidator
+	amqpQueue         *amqp.Channel
+	identityProviders *auth.ProviderRegistry
+	suggestionsCache  *cache.LRU[suggestionsKey, []model.UserSuggestion]
+
+	recommendationsRefresher *recommendationsRefresher
 }
 
-func CreateUserService(userDb users_db.UserDatabase, registryDb registry_db.RegistryDatabase, queue *amqp.Channel) *User {
+// CreateUserService creates the service, a non positive suggestionsCacheSize disables the autocomplete cache
+// and a non positive catalogCacheTTL the cache of the interests and locations
+func CreateUserService(userDb users_db.UserDatabase, registryDb registry_db.RegistryDatabase, catalogDb catalog_db.CatalogDatabase, queue *amqp.Channel, identityProviders *auth.ProviderRegistry, suggestionsCacheSize int, suggestionsCacheTTL time.Duration, catalogCacheTTL time.Duration) *User {
+	catalogs := newCatalogs(catalogDb, catalogCacheTTL)
 	return &User{
-		userDb:        userDb,
-		registryDb:    registryDb,
-		userValidator: NewUserValidator(userDb),
-		amqpQueue:     queue,
+		userDb:            userDb,
+		registryDb:        registryDb,
+		catalogDb:         catalogDb,
+		catalogs:          catalogs,
+		userValidator:     NewUserValidator(userDb, catalogs),
+		amqpQueue:         queue,
+		identityProviders: identityProviders,
+		suggestionsCache:  cache.NewLRU[suggestionsKey, []model.UserSuggestion](suggestionsCacheSize, suggestionsCacheTTL),
 	}
 }
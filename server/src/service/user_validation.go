@@ -3,31 +3,40 @@ package service
 import (
 	"fmt"
 	"log/slog"
+	"net/url"
 	"regexp"
+	"time"
+	"unicode/utf8"
 	"users-service/src/constants"
-	"users-service/src/database/register_options"
 	"users-service/src/database/users_db"
 	"users-service/src/model"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 )
 
 type UserValidator struct {
 	usersDb          users_db.UserDatabase
+	catalogs         *catalogs
 	validationErrors []model.ValidationError
+	// catalogErr is the error getting a catalog during the validation, it isn't an error of the user data
+	catalogErr error
 }
 
-func NewUserValidator(usersDb users_db.UserDatabase) *UserValidator {
+func NewUserValidator(usersDb users_db.UserDatabase, catalogs *catalogs) *UserValidator {
 	return &UserValidator{
-		usersDb: usersDb,
+		usersDb:  usersDb,
+		catalogs: catalogs,
 	}
 }
 
-func (u *UserValidator) ValidateUpdateUsername(newUsername string) ([]model.ValidationError, error) {
+// ValidateUpdateUsername validates the new username of a user, that can take back the usernames it still holds
+// but can't change it again until the cooldown since its last change is over
+func (u *UserValidator) ValidateUpdateUsername(userId uuid.UUID, newUsername string) ([]model.ValidationError, error) {
 	u.clearValidationErrors()
 	validate := validator.New()
 
-	if err := validate.RegisterValidation("usernamevalidator", u.usernameValidator); err != nil {
+	if err := validate.RegisterValidation("usernamevalidator", u.usernameValidatorFor(userId)); err != nil {
 		slog.Error("Error registering custom validator", slog.String("error: ", err.Error()))
 		return []model.ValidationError{}, err
 	}
@@ -41,7 +50,17 @@ func (u *UserValidator) ValidateUpdateUsername(newUsername string) ([]model.Vali
 			fmt.Println("error: ", err)
 		}
 	}
-	
+
+	lastChange, err := u.usersDb.GetLastUsernameChange(userId)
+	if err != nil {
+		return []model.ValidationError{}, err
+	}
+	if lastChange != nil {
+		if nextChange := lastChange.Add(constants.UsernameChangeCooldown); time.Now().Before(nextChange) {
+			u.addValidationError("username", fmt.Sprintf("The username can't be changed again until %s", nextChange.Format(time.RFC3339)))
+		}
+	}
+
 	return u.validationErrors, nil
 }
 func (u *UserValidator) ValidateUpdatePrivateProfileData(newProfile model.UpdateUserPrivateProfileData) ([]model.ValidationError, error) {
@@ -54,6 +73,11 @@ func (u *UserValidator) ValidateUpdatePrivateProfileData(newProfile model.Update
 		"lastnamevalidator":  u.lastnamevalidator,
 		"locationvalidator":  u.locationValidator,
 		"interestsvalidator": u.interestsValidator,
+		"biovalidator":       u.bioValidator,
+		"websitevalidator":   u.websiteValidator,
+		"birthdatevalidator": u.birthDateValidator,
+		"pronounsvalidator":  u.pronounsValidator,
+		"visibilityvalidator": u.visibilityValidator,
 	}
 	
 	for name, validatorFunc := range customValidators {
@@ -75,7 +99,82 @@ func (u *UserValidator) ValidateUpdatePrivateProfileData(newProfile model.Update
 	}
 	
 	fmt.Println("desp de errores")
-	return u.validationErrors, nil
+	return u.validationResult()
+}
+
+// ValidateProfilePatch validates only the fields that are in the patch, the username is validated apart
+// since it only has to be checked when it changes
+func (u *UserValidator) ValidateProfilePatch(patch model.UserProfilePatch) ([]model.ValidationError, error) {
+	u.clearValidationErrors()
+	validate := validator.New()
+
+	customValidators := map[string]validator.Func{
+		"firstnamevalidator": u.firstnamevalidator,
+		"lastnamevalidator":  u.lastnamevalidator,
+		"locationvalidator":  u.locationValidator,
+		"interestsvalidator": u.interestsValidator,
+		"biovalidator":       u.bioValidator,
+		"websitevalidator":   u.websiteValidator,
+		"birthdatevalidator": u.birthDateValidator,
+		"pronounsvalidator":  u.pronounsValidator,
+		"visibilityvalidator": u.visibilityValidator,
+	}
+
+	for name, validatorFunc := range customValidators {
+		if err := validate.RegisterValidation(name, validatorFunc); err != nil {
+			slog.Error("Error registering custom validator", slog.String("error: ", err.Error()))
+			return []model.ValidationError{}, err
+		}
+	}
+
+	type patchedField struct {
+		value interface{}
+		tag   string
+	}
+	fields := []patchedField{}
+	if patch.FirstName != nil {
+		fields = append(fields, patchedField{*patch.FirstName, "firstnamevalidator"})
+	}
+	if patch.LastName != nil {
+		fields = append(fields, patchedField{*patch.LastName, "lastnamevalidator"})
+	}
+	if patch.LocationId != nil {
+		fields = append(fields, patchedField{*patch.LocationId, "locationvalidator"})
+	}
+	if patch.InterestIds != nil {
+		fields = append(fields, patchedField{*patch.InterestIds, "interestsvalidator"})
+	}
+	if patch.Bio != nil {
+		fields = append(fields, patchedField{*patch.Bio, "omitempty,biovalidator"})
+	}
+	if patch.Website != nil {
+		fields = append(fields, patchedField{*patch.Website, "omitempty,websitevalidator"})
+	}
+	if patch.BirthDate != nil {
+		fields = append(fields, patchedField{*patch.BirthDate, "omitempty,birthdatevalidator"})
+	}
+	if patch.Pronouns != nil {
+		fields = append(fields, patchedField{*patch.Pronouns, "omitempty,pronounsvalidator"})
+	}
+	if patch.Visibility != nil {
+		// the fields set back to their default only need to exist
+		visibility := make(map[string]string, len(patch.Visibility))
+		for field, value := range patch.Visibility {
+			if value == nil {
+				visibility[field] = model.VisibilityPublic
+			} else {
+				visibility[field] = *value
+			}
+		}
+		fields = append(fields, patchedField{visibility, "visibilityvalidator"})
+	}
+
+	for _, field := range fields {
+		// the custom validators add their own errors
+		_ = validate.Var(field.value, field.tag)
+	}
+
+	return u.validationResult()
 }
 
 func (u *UserValidator) ValidateEmail(email string) ([]model.ValidationError, error) {
@@ -106,9 +205,10 @@ func (u *UserValidator) ValidatePersonalInfo(personalInfo model.UserPersonalInfo
 	customValidators := map[string]validator.Func{
 		"firstnamevalidator": u.firstnamevalidator,
 		"lastnamevalidator":  u.lastnamevalidator,
-		"usernamevalidator":  u.usernameValidator,
+		"usernamevalidator":  u.usernameValidatorFor(uuid.Nil),
 		"passwordvalidator":  u.passwordValidator,
 		"locationvalidator":  u.locationValidator,
+		"birthdatevalidator": u.birthDateValidator,
 	}
 
 	for name, validatorFunc := range customValidators {
@@ -127,7 +227,7 @@ func (u *UserValidator) ValidatePersonalInfo(personalInfo model.UserPersonalInfo
 		}
 	}
 
-	return u.validationErrors, nil
+	return u.validationResult()
 }
 
 func (u *UserValidator) ValidateInterests(interests []int) ([]model.ValidationError, error) {
@@ -148,11 +248,20 @@ func (u *UserValidator) ValidateInterests(interests []int) ([]model.ValidationEr
 		}
 	}
 
-	return u.validationErrors, nil
+	return u.validationResult()
 }
 
 func (u *UserValidator) clearValidationErrors() {
 	u.validationErrors = []model.ValidationError{}
+	u.catalogErr = nil
+}
+
+// validationResult returns the validation errors, or the error getting the catalogs if one couldn't be read
+func (u *UserValidator) validationResult() ([]model.ValidationError, error) {
+	if u.catalogErr != nil {
+		return []model.ValidationError{}, u.catalogErr
+	}
+	return u.validationErrors, nil
 }
 
 func (u *UserValidator) addValidationError(fieldName, message string) {
@@ -196,25 +305,40 @@ func (u *UserValidator) lastnamevalidator(fl validator.FieldLevel) bool {
 	}
 	return true
 }
-func (u *UserValidator) usernameValidator(fl validator.FieldLevel) bool {
-	username := fl.Field().String()
-	if len(username) < constants.MinUsernameLength || len(username) > constants.MaxUsernameLength {
-		u.addValidationError("username", fmt.Sprintf("Username must be between %d and %d characters long", constants.MinUsernameLength, constants.MaxUsernameLength))
-		return false
-	}
+// usernameValidatorFor returns the validator of the usernames the given user can take, the ones that are held
+// for other users after they left them are taken
+func (u *UserValidator) usernameValidatorFor(userId uuid.UUID) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		username := fl.Field().String()
+		if len(username) < constants.MinUsernameLength || len(username) > constants.MaxUsernameLength {
+			u.addValidationError("username", fmt.Sprintf("Username must be between %d and %d characters long", constants.MinUsernameLength, constants.MaxUsernameLength))
+			return false
+		}
 
-	user, err := u.usersDb.CheckIfUsernameExists(username)
-	if err != nil {
-		u.addValidationError("username", "Error checking if username exists")
-		return false
-	}
+		user, err := u.usersDb.CheckIfUsernameExists(username)
+		if err != nil {
+			u.addValidationError("username", "Error checking if username exists")
+			return false
+		}
 
-	if user {
-		u.addValidationError("username", "Username already exists")
-		return false
-	}
+		if user {
+			u.addValidationError("username", "Username already exists")
+			return false
+		}
 
-	return true
+		reserved, err := u.usersDb.CheckIfUsernameIsReserved(username, userId)
+		if err != nil {
+			u.addValidationError("username", "Error checking if username exists")
+			return false
+		}
+
+		if reserved {
+			u.addValidationError("username", "Username is reserved")
+			return false
+		}
+
+		return true
+	}
 }
 
 func (u *UserValidator) passwordValidator(fl validator.FieldLevel) bool {
@@ -238,7 +362,12 @@ func (u *UserValidator) passwordValidator(fl validator.FieldLevel) bool {
 
 func (u *UserValidator) locationValidator(fl validator.FieldLevel) bool {
 	location := fl.Field().Int()
-	if register_options.GetLocationName(int(location)) == "" {
+	locations, err := u.catalogs.get(model.LocationsCatalog)
+	if err != nil {
+		u.catalogErr = fmt.Errorf("error getting locations: %w", err)
+		return true
+	}
+	if !locations.selectable(int(location)) {
 		u.addValidationError("location", "Invalid location")
 		return false
 	}
@@ -256,9 +385,15 @@ func (u *UserValidator) interestsValidator(fl validator.FieldLevel) bool {
 		return false
 	}
 
+	catalog, err := u.catalogs.get(model.InterestsCatalog)
+	if err != nil {
+		u.catalogErr = fmt.Errorf("error getting interests: %w", err)
+		return true
+	}
+
 	seen := make(map[int]bool)
 	for _, interest := range interests {
-		if register_options.GetInterestName(interest) == "" {
+		if !catalog.selectable(interest) {
 			u.addValidationError("interests", "Invalid interest")
 			return false
 		}
@@ -270,3 +405,79 @@ func (u *UserValidator) interestsValidator(fl validator.FieldLevel) bool {
 	}
 	return true
 }
+
+func (u *UserValidator) bioValidator(fl validator.FieldLevel) bool {
+	bio := fl.Field().String()
+	if utf8.RuneCountInString(bio) > constants.MaxBioLength {
+		u.addValidationError(model.ProfileFieldBio, fmt.Sprintf("Bio must be at most %d characters long", constants.MaxBioLength))
+		return false
+	}
+	return true
+}
+
+func (u *UserValidator) websiteValidator(fl validator.FieldLevel) bool {
+	website := fl.Field().String()
+	if len(website) > constants.MaxWebsiteLength {
+		u.addValidationError(model.ProfileFieldWebsite, fmt.Sprintf("Website must be at most %d characters long", constants.MaxWebsiteLength))
+		return false
+	}
+
+	parsed, err := url.ParseRequestURI(website)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		u.addValidationError(model.ProfileFieldWebsite, "Website must be a valid http or https URL")
+		return false
+	}
+	return true
+}
+
+func (u *UserValidator) birthDateValidator(fl validator.FieldLevel) bool {
+	birthDate, err := time.Parse(model.BirthDateLayout, fl.Field().String())
+	if err != nil {
+		u.addValidationError(model.ProfileFieldBirthDate, "Birth date must have the YYYY-MM-DD format")
+		return false
+	}
+
+	age := ageAt(birthDate, time.Now())
+	if age < constants.MinUserAge {
+		u.addValidationError(model.ProfileFieldBirthDate, fmt.Sprintf("Users must be at least %d years old", constants.MinUserAge))
+		return false
+	}
+	if age > constants.MaxUserAge {
+		u.addValidationError(model.ProfileFieldBirthDate, "Invalid birth date")
+		return false
+	}
+	return true
+}
+
+func (u *UserValidator) pronounsValidator(fl validator.FieldLevel) bool {
+	pronouns := fl.Field().String()
+	if utf8.RuneCountInString(pronouns) > constants.MaxPronounsLength {
+		u.addValidationError(model.ProfileFieldPronouns, fmt.Sprintf("Pronouns must be at most %d characters long", constants.MaxPronounsLength))
+		return false
+	}
+	return true
+}
+
+func (u *UserValidator) visibilityValidator(fl validator.FieldLevel) bool {
+	visibility := fl.Field().Interface().(map[string]string)
+	for field, value := range visibility {
+		if !model.IsProfileField(field) {
+			u.addValidationError("visibility", fmt.Sprintf("The visibility of %s can't be changed", field))
+			return false
+		}
+		if value != model.VisibilityPublic && value != model.VisibilityPrivate {
+			u.addValidationError("visibility", fmt.Sprintf("The visibility must be %s or %s", model.VisibilityPublic, model.VisibilityPrivate))
+			return false
+		}
+	}
+	return true
+}
+
+// ageAt returns how many full years have passed from the birth date to the given time
+func ageAt(birthDate time.Time, now time.Time) int {
+	age := now.Year() - birthDate.Year()
+	if now.Month() < birthDate.Month() || (now.Month() == birthDate.Month() && now.Day() < birthDate.Day()) {
+		age--
+	}
+	return age
+}
@@ -3,9 +3,9 @@ package service
 import (
 	"fmt"
 	"net/http"
+	"time"
 	"users-service/src/app_errors"
 	"users-service/src/constants"
-	"users-service/src/database/register_options"
 	"users-service/src/model"
 
 	"github.com/google/uuid"
@@ -23,10 +23,30 @@ func generateUserPersonalInfoRecordFromRequest(request model.UserPersonalInfoReq
 		LastName:  request.LastName,
 		UserName:  request.UserName,
 		Password:  password,
-		Location:  register_options.GetLocationName(request.LocationId),
+		LocationId: request.LocationId,
+		BirthDate:  parseBirthDate(request.BirthDate),
 	}, nil
 }
 
+// parseBirthDate returns the birth date of an already validated request, or nil if it wasn't sent
+func parseBirthDate(birthDate string) *time.Time {
+	if birthDate == "" {
+		return nil
+	}
+	parsed, err := time.Parse(model.BirthDateLayout, birthDate)
+	if err != nil {
+		return nil
+	}
+	return &parsed
+}
+
+func formatBirthDate(birthDate *time.Time) string {
+	if birthDate == nil {
+		return ""
+	}
+	return birthDate.Format(model.BirthDateLayout)
+}
+
 func generateUserRecordFromRegistryEntry(registry model.RegistryEntry) model.UserRecord {
 	return model.UserRecord{
 		UserName:  registry.PersonalInfo.UserName,
@@ -34,17 +54,15 @@ func generateUserRecordFromRegistryEntry(registry model.RegistryEntry) model.Use
 		LastName:  registry.PersonalInfo.LastName,
 		Email:     registry.Email,
 		Password:  registry.PersonalInfo.Password,
-		Location:  registry.PersonalInfo.Location,
-		Interests: registry.Interests,
+		LocationId:  registry.PersonalInfo.LocationId,
+		InterestIds: registry.InterestIds,
+		BirthDate:   registry.PersonalInfo.BirthDate,
 	}
 }
 
-func (u *User) createUserPrivateProfileFromUserRecord(record model.UserRecord) (model.UserPrivateProfile, error) {
-	followers, following, err := u.getAmountOfFollowersAndFollowing(record)
-	if err != nil {
-		return model.UserPrivateProfile{}, err
-	}
-
+// createUserPrivateProfileFromUserRecord builds the private profile of the user, with its location and interests in the language
+func (u *User) createUserPrivateProfileFromUserRecord(record model.UserRecord, language string) (model.UserPrivateProfile, error) {
+	u.localizeUser(&record, language)
 	return model.UserPrivateProfile{
 		Id:          record.Id,
 		UserName:    record.UserName,
@@ -54,58 +72,123 @@ func (u *User) createUserPrivateProfileFromUserRecord(record model.UserRecord) (
 		Location:    record.Location,
 		Interests:   record.Interests,
 		PicturePath: record.PicturePath,
-		Followers:   followers,
-		Following:   following,
+		Followers:   record.FollowersCount,
+		Following:   record.FollowingCount,
+		Bio:         record.Bio,
+		Website:     record.Website,
+		BirthDate:   formatBirthDate(record.BirthDate),
+		Pronouns:    record.Pronouns,
+		BannerPath:  record.BannerPath,
+		Visibility:  record.Visibility.WithDefaults(),
+		Version:     record.Version,
 	}, nil
 }
 
-func (u *User) generateUserPublicProfileFromUserRecord(user model.UserRecord) (model.UserPublicProfile, error) {
-	followers, following, err := u.getAmountOfFollowersAndFollowing(user)
-	if err != nil {
-		return model.UserPublicProfile{}, err
-	}
+func (u *User) generateUserPublicProfileFromUserRecord(user model.UserRecord, language string) (model.UserPublicProfile, error) {
+	u.localizeUser(&user, language)
+	return buildUserPublicProfile(user), nil
+}
 
-	return model.UserPublicProfile{
+// buildUserPublicProfile builds the profile other users see, without the optional fields the user made private
+func buildUserPublicProfile(user model.UserRecord) model.UserPublicProfile {
+	profile := model.UserPublicProfile{
 		Id:          user.Id,
 		UserName:    user.UserName,
 		FirstName:   user.FirstName,
 		LastName:    user.LastName,
 		Location:    user.Location,
-		Followers:   followers,
-		Following:   following,
+		Followers:   user.FollowersCount,
+		Following:   user.FollowingCount,
 		PicturePath: user.PicturePath,
-	}, nil
+	}
+
+	if user.Visibility.IsPublic(model.ProfileFieldBio) {
+		profile.Bio = user.Bio
+	}
+	if user.Visibility.IsPublic(model.ProfileFieldWebsite) {
+		profile.Website = user.Website
+	}
+	if user.Visibility.IsPublic(model.ProfileFieldBirthDate) {
+		profile.BirthDate = formatBirthDate(user.BirthDate)
+	}
+	if user.Visibility.IsPublic(model.ProfileFieldPronouns) {
+		profile.Pronouns = user.Pronouns
+	}
+	if user.Visibility.IsPublic(model.ProfileFieldBanner) {
+		profile.BannerPath = user.BannerPath
+	}
+	return profile
+}
+
+func getUserIds(userRecords []model.UserRecord) []uuid.UUID {
+	ids := make([]uuid.UUID, len(userRecords))
+	for i, user := range userRecords {
+		ids[i] = user.Id
+	}
+	return ids
 }
 
-func (u *User) getUserProfilesFromUserRecords(userRecords []model.UserRecord, sessionUserId uuid.UUID) ([]model.UserProfileResponse, error) {
+// viewerRelationships holds how the session user relates to a list of users
+type viewerRelationships struct {
+	follows    map[uuid.UUID]bool
+	followedBy map[uuid.UUID]bool
+	mutuals    map[uuid.UUID]int
+}
+
+// getViewerRelationships fetches the relationships of the session user with the whole list of users at once
+func (u *User) getViewerRelationships(sessionUserId uuid.UUID, userIds []uuid.UUID) (viewerRelationships, error) {
+	follows, err := u.userDb.GetFollowedUsersAmong(sessionUserId, userIds)
+	if err != nil {
+		return viewerRelationships{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error checking if user follows: %w", err))
+	}
+
+	followedBy, err := u.userDb.GetFollowersAmong(sessionUserId, userIds)
+	if err != nil {
+		return viewerRelationships{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error checking if user is followed: %w", err))
+	}
+
+	mutuals, err := u.userDb.GetMutualCounts(sessionUserId, userIds)
+	if err != nil {
+		return viewerRelationships{}, app_errors.NewAppError(http.StatusInternalServerError, InternalServerError, fmt.Errorf("error getting mutual counts: %w", err))
+	}
+
+	return viewerRelationships{follows: follows, followedBy: followedBy, mutuals: mutuals}, nil
+}
+
+// profileResponse builds the response of a profile as seen by the session user
+func (r viewerRelationships) profileResponse(sessionUserId uuid.UUID, userId uuid.UUID, profile interface{}) model.UserProfileResponse {
+	return model.UserProfileResponse{
+		OwnProfile:  sessionUserId == userId,
+		Follows:     r.follows[userId],
+		FollowedBy:  r.followedBy[userId],
+		MutualCount: r.mutuals[userId],
+		Profile:     profile,
+	}
+}
+
+// getUserProfilesFromUserRecords builds the profiles of a list of users as seen by the session user in its language,
+// fetching the relationships with the whole list at once
+func (u *User) getUserProfilesFromUserRecords(userRecords []model.UserRecord, sessionUserId uuid.UUID, language string) ([]model.UserProfileResponse, error) {
+	u.localizeUsers(userRecords, language)
+
+	relationships, err := u.getViewerRelationships(sessionUserId, getUserIds(userRecords))
+	if err != nil {
+		return nil, err
+	}
+
 	profiles := make([]model.UserProfileResponse, 0, len(userRecords))
 	for _, user := range userRecords {
-		profile, err := u.generateUserPublicProfileFromUserRecord(user)
-		if err != nil {
-			return nil, err
-		}
-		follows, err := u.userDb.CheckIfUserFollows(sessionUserId, user.Id)
-		if err != nil {
-			return nil, app_errors.NewAppError(http.StatusInternalServerError, "Internal server error", fmt.Errorf("error checking if user follows: %w", err))
-		}
-		followProfile := model.UserProfileResponse{
-			Follows:    follows,
-			OwnProfile: sessionUserId == user.Id,
-			Profile:    profile,
-		}
-		profiles = append(profiles, followProfile)
+		profiles = append(profiles, relationships.profileResponse(sessionUserId, user.Id, buildUserPublicProfile(user)))
 	}
 	return profiles, nil
 }
 
-func (u *User) getPublicProfilesFromUserRecords(userRecords []model.UserRecord) ([]model.UserPublicProfile, error) {
+func (u *User) getPublicProfilesFromUserRecords(userRecords []model.UserRecord, language string) ([]model.UserPublicProfile, error) {
+	u.localizeUsers(userRecords, language)
+
 	profiles := make([]model.UserPublicProfile, 0, len(userRecords))
 	for _, user := range userRecords {
-		profile, err := u.generateUserPublicProfileFromUserRecord(user)
-		if err != nil {
-			return nil, err
-		}
-		profiles = append(profiles, profile)
+		profiles = append(profiles, buildUserPublicProfile(user))
 	}
 	return profiles, nil
 }
@@ -124,26 +207,17 @@ func checkPasswordHash(password, hash string) bool {
 	return err == nil
 }
 
-func extractInterestNamesFromValidIds(interests []int) []string {
-	interestsNames := make([]string, len(interests))
-	for i, interest := range interests {
-		if name := register_options.GetInterestName(interest); name != "" {
-			interestsNames[i] = name
-		}
-	}
-	return interestsNames
-}
-
 func getStepForRegistryEntry(entry model.RegistryEntry) string {
 	if !entry.EmailVerified {
 		return constants.EmailVerificationStep
 	}
 
-	if entry.PersonalInfo.FirstName == "" {
+	// the registrations migrated with a location that is not in the catalog have to choose it again
+	if entry.PersonalInfo.FirstName == "" || !entry.HasLocation {
 		return constants.PersonalInfoStep
 	}
 
-	if len(entry.Interests) == 0 {
+	if len(entry.InterestIds) == 0 {
 		return constants.InterestsStep
 	}
 
@@ -136,3 +136,77 @@ func AddInterestsInfoToNotExistingRegistry(t *testing.T) {
 	err = utils.PutValidInterests(router, id, intetestsIds)
 	assert.Equal(t, err, nil)
 }
+
+// createCompleteRegistry takes a registry through every onboarding step and returns its id
+func createCompleteRegistry(t *testing.T, testRouter *router.Router, email string, username string) string {
+	res, err := utils.GetUserRegistryForSignUp(testRouter, email)
+	assert.Equal(t, err, nil)
+
+	id := res.Metadata.RegistrationId
+
+	err = utils.SendEmailVerificationAndVerificateIt(testRouter, id)
+	assert.Equal(t, err, nil)
+
+	personalInfo := models.UserPersonalInfo{
+		FirstName: "Edward",
+		LastName:  "Elric",
+		UserName:  username,
+		Password:  "Holaa&2dS",
+		Location:  1,
+	}
+
+	err = utils.PutValidUserPersonalInfo(testRouter, id, personalInfo)
+	assert.Equal(t, err, nil)
+
+	err = utils.PutValidInterests(testRouter, id, []int{0})
+	assert.Equal(t, err, nil)
+	return id
+}
+
+func TestRegistriesWithLocationNamesAreMigratedToTheCatalog(t *testing.T) {
+	testRouter, err := router.CreateRouter()
+	assert.Equal(t, err, nil)
+
+	mappedEmail := "monke4@gmail.com"
+	mappedId := createCompleteRegistry(t, testRouter, mappedEmail, "EdwardoElric")
+	unmappedEmail := "monke5@gmail.com"
+	unmappedId := createCompleteRegistry(t, testRouter, unmappedEmail, "AlphonseElric")
+
+	// the registries are taken back to when they stored the name of the location
+	db := utils.OpenTestDatabase(t)
+	_, err = db.Exec("ALTER TABLE registry_entries ADD COLUMN location VARCHAR(255)")
+	assert.Equal(t, err, nil)
+	_, err = db.Exec("UPDATE registry_entries SET location_id = NULL, location = ' argentina ' WHERE id = $1", mappedId)
+	assert.Equal(t, err, nil)
+	_, err = db.Exec("UPDATE registry_entries SET location_id = NULL, location = 'Atlantis' WHERE id = $1", unmappedId)
+	assert.Equal(t, err, nil)
+
+	utils.CreateTestUserService(t, db)
+
+	res, err := utils.GetUserRegistryForSignUp(testRouter, mappedEmail)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, res.Metadata.OnboardingStep, constants.CompleteStep)
+
+	profile, err := utils.CompleteValidRegistry(testRouter, mappedId)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, profile.FirstName, "Edward")
+	assert.Equal(t, profile.Location, "Argentina")
+
+	res, err = utils.GetUserRegistryForSignUp(testRouter, unmappedEmail)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, res.Metadata.OnboardingStep, constants.PersonalInfoStep)
+
+	personalInfo := models.UserPersonalInfo{
+		FirstName: "Alphonse",
+		LastName:  "Elric",
+		UserName:  "AlphonseElrics",
+		Password:  "Holaa&2dS",
+		Location:  2,
+	}
+	err = utils.PutValidUserPersonalInfo(testRouter, unmappedId, personalInfo)
+	assert.Equal(t, err, nil)
+
+	res, err = utils.GetUserRegistryForSignUp(testRouter, unmappedEmail)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, res.Metadata.OnboardingStep, constants.CompleteStep)
+}
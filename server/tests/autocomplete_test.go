@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"users-service/src/router"
+	"users-service/tests/models"
+	"users-service/tests/utils"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func setUpAutocompleteTests() (testRouter *router.Router, token string, user2 models.UserPrivateProfile, user3 models.UserPrivateProfile) {
+	testRouter, user1, user1Password, user2, user2Password := setUpSearchTests()
+
+	user := models.UserPersonalInfo{
+		FirstName: "Monke",
+		LastName:  "Aaa",
+		UserName:  "MonkeAaa",
+		Password:  "Edward$El1ric:)",
+		Location:  0,
+	}
+	user3, err := utils.CreateValidUser(testRouter, "monkeaaa@elric.com", user, []int{0})
+	if err != nil {
+		panic("Failed to create user3: " + err.Error())
+	}
+
+	user2Login, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user2.Email, Password: user2Password})
+	if err != nil {
+		panic("Failed to login user2: " + err.Error())
+	}
+	if err := utils.FollowValidUser(testRouter, user3.Id.String(), user2Login.AccessToken); err != nil {
+		panic("Failed to follow user3: " + err.Error())
+	}
+
+	user1Login, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	if err != nil {
+		panic("Failed to login user1: " + err.Error())
+	}
+	return testRouter, user1Login.AccessToken, user2, user3
+}
+
+func TestAutocompleteReturnsTheMostFollowedUsersFirst(t *testing.T) {
+	testRouter, token, user2, user3 := setUpAutocompleteTests()
+
+	code, suggestions, err := utils.AutocompleteUsernames(testRouter, "monke", token)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, len(suggestions), 2)
+	assert.Equal(t, suggestions[0].Id, user3.Id)
+	assert.Equal(t, suggestions[0].Name, "Monke Aaa")
+	assert.Equal(t, suggestions[1].Id, user2.Id)
+}
+
+func TestAutocompleteReturnsTheFollowedUsersFirst(t *testing.T) {
+	testRouter, token, user2, user3 := setUpAutocompleteTests()
+
+	err := utils.FollowValidUser(testRouter, user2.Id.String(), token)
+	assert.Equal(t, err, nil)
+
+	code, suggestions, err := utils.AutocompleteUsernames(testRouter, "MONKE", token)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, len(suggestions), 2)
+	assert.Equal(t, suggestions[0].Id, user2.Id)
+	assert.Equal(t, suggestions[1].Id, user3.Id)
+}
+
+func TestAutocompleteWithoutPrefixReturnsError(t *testing.T) {
+	testRouter, token, _, _ := setUpAutocompleteTests()
+
+	code, _, err := utils.AutocompleteUsernames(testRouter, "", token)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusBadRequest)
+}
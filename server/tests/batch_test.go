@@ -0,0 +1,51 @@
+package tests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"users-service/tests/models"
+	"users-service/tests/utils"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/google/uuid"
+)
+
+func TestGetUsersBatchReturnsTheUsersKeyedById(t *testing.T) {
+	testRouter, user1, user1Password, user2, _ := setUpEditProfileTests()
+	resp, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+	err = utils.FollowValidUser(testRouter, user2.Id.String(), resp.AccessToken)
+	assert.Equal(t, err, nil)
+
+	missingId := uuid.New().String()
+	code, batch, err := utils.GetUsersBatch(testRouter, resp.AccessToken, []string{user2.Id.String(), missingId}, []string{strings.ToUpper(user1.UserName), "Nobody"})
+
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, len(batch.Users), 3)
+	assert.Equal(t, batch.Users[missingId] == nil, true)
+
+	followed := batch.Users[user2.Id.String()]
+	assert.Equal(t, followed.Profile.UserName, user2.UserName)
+	assert.Equal(t, followed.Follows, true)
+	assert.Equal(t, followed.FollowedBy, false)
+
+	assert.Equal(t, *batch.Usernames[strings.ToUpper(user1.UserName)], user1.Id)
+	assert.Equal(t, batch.Usernames["Nobody"] == nil, true)
+	assert.Equal(t, batch.Users[user1.Id.String()].Profile.FirstName, user1.FirstName)
+}
+
+func TestGetUsersBatchWithInvalidIdsReturnsBadRequest(t *testing.T) {
+	testRouter, user1, user1Password, _, _ := setUpEditProfileTests()
+	resp, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+
+	code, _, err := utils.GetUsersBatch(testRouter, resp.AccessToken, []string{"not-an-id"}, nil)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusBadRequest)
+
+	code, _, err = utils.GetUsersBatch(testRouter, resp.AccessToken, nil, nil)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusBadRequest)
+}
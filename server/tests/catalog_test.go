@@ -0,0 +1,305 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"users-service/src/router"
+	"users-service/tests/models"
+	"users-service/tests/utils"
+
+	"github.com/go-playground/assert/v2"
+)
+
+// setUpCatalogTests creates a user from Argentina interested in programming and movies, and logs in an admin
+func setUpCatalogTests() (testRouter *router.Router, user models.LoginResponse, adminToken string) {
+	testRouter, err := router.CreateRouter()
+	if err != nil {
+		panic("Failed to create router: " + err.Error())
+	}
+
+	personalInfo := models.UserPersonalInfo{
+		FirstName: "Winry",
+		LastName:  "Rockbell",
+		UserName:  "WinryRockbell",
+		Password:  "Winry$R0ckbell:)",
+		Location:  0,
+	}
+	user, err = utils.CreateAndLoginUser(testRouter, "winry@rockbell.com", personalInfo, []int{0, 1})
+	if err != nil {
+		panic("Failed to create user: " + err.Error())
+	}
+
+	adminToken, err = utils.LoginAdmin()
+	if err != nil {
+		panic("Failed to login admin: " + err.Error())
+	}
+
+	return testRouter, user, adminToken
+}
+
+func TestAddedInterestIsListedLast(t *testing.T) {
+	testRouter, _, adminToken := setUpCatalogTests()
+
+	code, entry, err := utils.AddCatalogEntry(testRouter, "interests", "gaming", adminToken)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusCreated)
+	assert.Equal(t, entry.Name, "gaming")
+
+	options, err := utils.GetRegisterOptions(testRouter)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, options.Interests[len(options.Interests)-1], models.Interest{Id: entry.Id, Name: "gaming"})
+}
+
+func TestAddingAnExistingInterestReturnsConflict(t *testing.T) {
+	testRouter, _, adminToken := setUpCatalogTests()
+
+	code, _, err := utils.AddCatalogEntry(testRouter, "interests", "movies", adminToken)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusConflict)
+}
+
+func TestRenamedInterestIsSeenByTheUsersThatHaveIt(t *testing.T) {
+	testRouter, user, adminToken := setUpCatalogTests()
+
+	code, _, err := utils.UpdateCatalogEntry(testRouter, "interests", 0, map[string]interface{}{"name": "coding"}, adminToken)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+
+	profile, err := utils.GetOwnProfile(testRouter, user.Profile.Id.String(), user.AccessToken)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, profile.Interests, []string{"coding", "movies"})
+}
+
+func TestRenamedLocationIsSeenByTheUsersThatHaveIt(t *testing.T) {
+	testRouter, user, adminToken := setUpCatalogTests()
+
+	code, _, err := utils.UpdateCatalogEntry(testRouter, "locations", 0, map[string]interface{}{"name": "República Argentina"}, adminToken)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+
+	profile, err := utils.GetOwnProfile(testRouter, user.Profile.Id.String(), user.AccessToken)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, profile.Location, "República Argentina")
+}
+
+func TestDeprecatedLocationIsNotListedAndCantBeChosen(t *testing.T) {
+	testRouter, user, adminToken := setUpCatalogTests()
+
+	code, entry, err := utils.UpdateCatalogEntry(testRouter, "locations", 4, map[string]interface{}{"deprecated": true}, adminToken)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, entry, models.CatalogEntry{Id: 4, Name: "Uruguay", Position: 4, Deprecated: true})
+
+	options, err := utils.GetRegisterOptions(testRouter)
+	assert.Equal(t, err, nil)
+	for _, location := range options.Locations {
+		assert.NotEqual(t, location.Id, 4)
+	}
+
+	edit := models.EditUserProfileRequest{
+		Username:  user.Profile.UserName,
+		FirstName: user.Profile.FirstName,
+		LastName:  user.Profile.LastName,
+		Location:  4,
+		Interests: []int{0, 1},
+	}
+	code, _, err = utils.EditInvalidUserProfile(testRouter, user.AccessToken, edit)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusBadRequest)
+}
+
+func TestReorderedLocationsAreListedInTheNewOrder(t *testing.T) {
+	testRouter, _, adminToken := setUpCatalogTests()
+
+	code, err := utils.ReorderCatalog(testRouter, "locations", []int{4, 3, 2, 1, 0}, adminToken)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+
+	options, err := utils.GetRegisterOptions(testRouter)
+	assert.Equal(t, err, nil)
+	ids := []int{}
+	for _, location := range options.Locations {
+		ids = append(ids, location.Id)
+	}
+	assert.Equal(t, ids, []int{4, 3, 2, 1, 0})
+}
+
+func TestReorderMissingEntriesReturnsBadRequest(t *testing.T) {
+	testRouter, _, adminToken := setUpCatalogTests()
+
+	code, err := utils.ReorderCatalog(testRouter, "locations", []int{1, 0}, adminToken)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusBadRequest)
+}
+
+func TestCatalogCantBeEditedByNonAdmins(t *testing.T) {
+	testRouter, user, _ := setUpCatalogTests()
+
+	code, _, err := utils.AddCatalogEntry(testRouter, "interests", "gaming", user.AccessToken)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusForbidden)
+}
+
+func TestCatalogsAreListedInTheRequestedLanguage(t *testing.T) {
+	testRouter, _, _ := setUpCatalogTests()
+
+	options, err := utils.GetRegisterOptionsInLanguage(testRouter, "es-AR,es;q=0.9,en;q=0.8")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, options.Interests[0].Name, "programación")
+	assert.Equal(t, options.Locations[1].Name, "Brasil")
+
+	options, err = utils.GetRegisterOptionsInLanguage(testRouter, "en-US")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, options.Interests[0].Name, "programming")
+	assert.Equal(t, options.Locations[1].Name, "Brazil")
+}
+
+func TestUnsupportedLanguageFallsBackToEnglish(t *testing.T) {
+	testRouter, _, _ := setUpCatalogTests()
+
+	options, err := utils.GetRegisterOptionsInLanguage(testRouter, "fr-FR")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, options.Interests[4].Name, "cooking")
+	assert.Equal(t, options.Locations[1].Name, "Brazil")
+}
+
+func TestProfileIsShownInTheLanguageOfTheViewer(t *testing.T) {
+	testRouter, user, _ := setUpCatalogTests()
+
+	profile, err := utils.GetOwnProfileInLanguage(testRouter, user.Profile.Id.String(), user.AccessToken, "es")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, profile.Interests, []string{"programación", "películas"})
+	assert.Equal(t, profile.Location, "Argentina")
+
+	profile, err = utils.GetOwnProfileInLanguage(testRouter, user.Profile.Id.String(), user.AccessToken, "en")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, profile.Interests, []string{"programming", "movies"})
+}
+
+func TestTranslatedEntryIsShownInThatLanguageOnly(t *testing.T) {
+	testRouter, _, adminToken := setUpCatalogTests()
+
+	_, entry, err := utils.AddCatalogEntry(testRouter, "interests", "gaming", adminToken)
+	assert.Equal(t, err, nil)
+
+	code, translated, err := utils.SetCatalogTranslation(testRouter, "interests", entry.Id, "es", "videojuegos", adminToken)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, translated.Translations, map[string]string{"es": "videojuegos"})
+
+	options, err := utils.GetRegisterOptionsInLanguage(testRouter, "es")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, options.Interests[len(options.Interests)-1].Name, "videojuegos")
+
+	options, err = utils.GetRegisterOptionsInLanguage(testRouter, "en")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, options.Interests[len(options.Interests)-1].Name, "gaming")
+
+	code = utils.DeleteCatalogTranslation(testRouter, "interests", entry.Id, "es", adminToken)
+	assert.Equal(t, code, http.StatusNoContent)
+
+	options, err = utils.GetRegisterOptionsInLanguage(testRouter, "es")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, options.Interests[len(options.Interests)-1].Name, "gaming")
+}
+
+func TestTranslatingToAnUnsupportedLanguageReturnsBadRequest(t *testing.T) {
+	testRouter, _, adminToken := setUpCatalogTests()
+
+	code, _, err := utils.SetCatalogTranslation(testRouter, "interests", 0, "fr", "programmation", adminToken)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusBadRequest)
+}
+
+func TestInterestCategoriesAreListedInTheRequestedLanguage(t *testing.T) {
+	testRouter, _, _ := setUpCatalogTests()
+
+	categories, err := utils.GetInterestCategories(testRouter, "es")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(categories), 4)
+	assert.Equal(t, categories[0], models.InterestCategory{Id: 0, Name: "tecnología", Icon: "💻"})
+}
+
+func TestInterestsCanBeListedByCategory(t *testing.T) {
+	testRouter, _, _ := setUpCatalogTests()
+
+	code, interests, err := utils.GetInterestsInCategory(testRouter, "3")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, len(interests), 2)
+	assert.Equal(t, interests[0].Name, "traveling")
+	assert.Equal(t, interests[0].Icon, "✈️")
+	assert.Equal(t, interests[1].Name, "cooking")
+	assert.Equal(t, *interests[1].CategoryId, 3)
+}
+
+func TestListingInterestsOfAnUnknownCategoryReturnsBadRequest(t *testing.T) {
+	testRouter, _, _ := setUpCatalogTests()
+
+	code, _, err := utils.GetInterestsInCategory(testRouter, "99")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusBadRequest)
+}
+
+func TestAddedInterestIsListedInItsCategory(t *testing.T) {
+	testRouter, _, adminToken := setUpCatalogTests()
+
+	code, entry, err := utils.AddCatalogEntryWithMetadata(testRouter, "interests", map[string]interface{}{"name": "gaming", "icon": "🎮", "category_id": 1}, adminToken)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusCreated)
+
+	_, interests, err := utils.GetInterestsInCategory(testRouter, "1")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, interests[len(interests)-1].Id, entry.Id)
+	assert.Equal(t, interests[len(interests)-1].Icon, "🎮")
+}
+
+func TestLocationsCantHaveAnIcon(t *testing.T) {
+	testRouter, _, adminToken := setUpCatalogTests()
+
+	code, _, err := utils.AddCatalogEntryWithMetadata(testRouter, "locations", map[string]interface{}{"name": "Bolivia", "icon": "🇧🇴"}, adminToken)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusBadRequest)
+}
+
+func TestUsersCanBeSearchedByInterestCategory(t *testing.T) {
+	testRouter, user, _ := setUpCatalogTests()
+
+	personalInfo := models.UserPersonalInfo{
+		FirstName: "Edward",
+		LastName:  "Elric",
+		UserName:  "EdwardElric",
+		Password:  "Edward$Elr1c:)",
+		Location:  2,
+	}
+	traveler, err := utils.CreateValidUser(testRouter, "edward@elric.com", personalInfo, []int{4})
+	assert.Equal(t, err, nil)
+
+	users, err := utils.SearchUsersWithQuery(testRouter, "categories=3", user.AccessToken, 10)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(users), 1)
+	assert.Equal(t, users[0].Profile.Id, traveler.Id)
+}
+
+func TestUsersInterestedInTheSameCategoryAreRecommended(t *testing.T) {
+	testRouter, user, adminToken := setUpCatalogTests()
+
+	_, series, err := utils.AddCatalogEntryWithMetadata(testRouter, "interests", map[string]interface{}{"name": "series", "category_id": 1}, adminToken)
+	assert.Equal(t, err, nil)
+
+	personalInfo := models.UserPersonalInfo{
+		FirstName: "Edward",
+		LastName:  "Elric",
+		UserName:  "EdwardElric",
+		Password:  "Edward$Elr1c:)",
+		Location:  2,
+	}
+	watcher, err := utils.CreateValidUser(testRouter, "edward@elric.com", personalInfo, []int{series.Id})
+	assert.Equal(t, err, nil)
+
+	recommendations, err := utils.GetAllUserRecommendations(testRouter, user.AccessToken, 10)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(recommendations), 1)
+	assert.Equal(t, recommendations[0].Profile.Id, watcher.Id)
+	assert.Equal(t, recommendations[0].Reasons, []string{"1 interest category in common"})
+}
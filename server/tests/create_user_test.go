@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-playground/assert/v2"
 
@@ -285,3 +286,68 @@ func TestCreateUserWithUsernameThatExistsWithDifferentCaseReturnsProperValidatio
 	assert.Equal(t, response.Errors[0].Field, "username")
 	utils.AssertRegisterInstancePattern(t, "personal-info", response.Instance)
 }
+
+func TestCreateUserWithBirthDateKeepsIt(t *testing.T) {
+	router, err := router.CreateRouter()
+	assert.Equal(t, err, nil)
+
+	email := "winry@rockbell.com"
+	personalInfo := models.UserPersonalInfo{
+		FirstName: "Winry",
+		LastName:  "Rockbell",
+		UserName:  "WinryRockbell",
+		Password:  "Aut0mail$Rock",
+		Location:  0,
+		BirthDate: "1996-06-21",
+	}
+
+	userProfile, err := utils.CreateValidUser(router, email, personalInfo, []int{0})
+
+	assert.Equal(t, err, nil)
+	assert.Equal(t, userProfile.BirthDate, "1996-06-21")
+	assert.Equal(t, userProfile.Visibility["birth_date"], "private")
+}
+
+func TestCreateUserYoungerThanTheMinimumAgeReturnsProperValidationError(t *testing.T) {
+	router, err := router.CreateRouter()
+	assert.Equal(t, err, nil)
+
+	email := "selim@bradley.com"
+	personalInfo := models.UserPersonalInfo{
+		FirstName: "Selim",
+		LastName:  "Bradley",
+		UserName:  "SelimBradley",
+		Password:  "Pr1de$Shadow",
+		Location:  0,
+		BirthDate: time.Now().AddDate(-10, 0, 0).Format("2006-01-02"),
+	}
+
+	code, response, err := utils.CreateUserWithInvalidPersonalInfo(router, email, personalInfo)
+
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusBadRequest)
+	assert.Equal(t, len(response.Errors), 1)
+	assert.Equal(t, response.Errors[0].Field, "birth_date")
+}
+
+func TestCreateUserWithMalformedBirthDateReturnsProperValidationError(t *testing.T) {
+	router, err := router.CreateRouter()
+	assert.Equal(t, err, nil)
+
+	email := "maes@hughes.com"
+	personalInfo := models.UserPersonalInfo{
+		FirstName: "Maes",
+		LastName:  "Hughes",
+		UserName:  "MaesHughes",
+		Password:  "Elys1a$Hughes",
+		Location:  0,
+		BirthDate: "21/06/1985",
+	}
+
+	code, response, err := utils.CreateUserWithInvalidPersonalInfo(router, email, personalInfo)
+
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusBadRequest)
+	assert.Equal(t, len(response.Errors), 1)
+	assert.Equal(t, response.Errors[0].Field, "birth_date")
+}
@@ -3,8 +3,10 @@ package tests
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"users-service/src/router"
 	"users-service/tests/models"
@@ -13,10 +15,10 @@ import (
 	"github.com/go-playground/assert/v2"
 )
 
-func setUpEditProfileTests() (testRouter *router.Router, user1 models.UserPrivateProfile, user1Password string, user2 models.UserPrivateProfile, user2Password string){
+func setUpEditProfileTests(options ...router.Option) (testRouter *router.Router, user1 models.UserPrivateProfile, user1Password string, user2 models.UserPrivateProfile, user2Password string){
     var err error
     
-    testRouter, err = router.CreateRouter()
+    testRouter, err = router.CreateRouter(options...)
     if err != nil {
         panic("Failed to create router: " + err.Error())
     }
@@ -165,3 +167,152 @@ func TestEditProfileWithInvalidDataReturnsProperValidationError(t *testing.T) {
 	assert.Equal(t, response.Title, "validation error")
 	assert.Equal(t, len(response.Errors), 5)
 }
+
+func TestEditProfileWithExtendedFieldsShowsThemByVisibility(t *testing.T) {
+	testRouter, user1, user1Password, user2, user2Password := setUpEditProfileTests()
+
+	updatedProfile := models.EditUserProfileRequest {
+		FirstName: user1.FirstName,
+		LastName: user1.LastName,
+		Username: user1.UserName,
+		Location: 0,
+		Interests: []int{0, 1},
+		Bio: "Fullmetal alchemist",
+		Website: "https://amestris.gov/elric",
+		BirthDate: "1899-02-03",
+		Pronouns: "he/him",
+		BannerPath: "banners/elric.png",
+		Visibility: map[string]string{"website": "private"},
+	}
+	resp, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+
+	// the birth date makes him too old, it is sent again with a valid one
+	code, response, err := utils.EditInvalidUserProfile(testRouter, resp.AccessToken, updatedProfile)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusBadRequest)
+	assert.Equal(t, len(response.Errors), 1)
+	assert.Equal(t, response.Errors[0].Field, "birth_date")
+
+	updatedProfile.BirthDate = "1999-02-03"
+	newUser, err := utils.EditValidUserProfile(testRouter, resp.AccessToken, updatedProfile)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, newUser.Bio, "Fullmetal alchemist")
+	assert.Equal(t, newUser.Website, "https://amestris.gov/elric")
+	assert.Equal(t, newUser.BirthDate, "1999-02-03")
+	assert.Equal(t, newUser.Pronouns, "he/him")
+	assert.Equal(t, newUser.BannerPath, "banners/elric.png")
+	assert.Equal(t, newUser.Visibility["website"], "private")
+	assert.Equal(t, newUser.Visibility["bio"], "public")
+
+	otherResp, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user2.Email, Password: user2Password})
+	assert.Equal(t, err, nil)
+	publicProfile, err := utils.GetAnotherUserProfile(testRouter, user1.Id.String(), otherResp.AccessToken)
+	assert.Equal(t, err, nil)
+
+	assert.Equal(t, publicProfile.Bio, "Fullmetal alchemist")
+	assert.Equal(t, publicProfile.Pronouns, "he/him")
+	assert.Equal(t, publicProfile.BannerPath, "banners/elric.png")
+	assert.Equal(t, publicProfile.Website, "")
+	assert.Equal(t, publicProfile.BirthDate, "")
+}
+
+func TestEditProfileWithInvalidExtendedFieldsReturnsProperValidationErrors(t *testing.T) {
+	testRouter, user1, user1Password, _, _ := setUpEditProfileTests()
+
+	updatedProfile := models.EditUserProfileRequest {
+		FirstName: user1.FirstName,
+		LastName: user1.LastName,
+		Username: user1.UserName,
+		Location: 0,
+		Interests: []int{0, 1},
+		Bio: strings.Repeat("a", 501),
+		Website: "ftp://amestris.gov",
+		Pronouns: strings.Repeat("b", 33),
+		Visibility: map[string]string{"email": "public"},
+	}
+	resp, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+
+	code, response, err := utils.EditInvalidUserProfile(testRouter, resp.AccessToken, updatedProfile)
+
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusBadRequest)
+	assert.Equal(t, response.Title, "validation error")
+	assert.Equal(t, len(response.Errors), 4)
+}
+
+func TestPatchProfileOnlyModifiesTheGivenFields(t *testing.T) {
+	testRouter, user1, user1Password, _, _ := setUpEditProfileTests()
+	resp, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+
+	code, etag, body := utils.PatchUserProfile(testRouter, resp.AccessToken, `{"picture_path": "pictures/edward.png", "bio": "Alchemist"}`, "")
+	assert.Equal(t, code, http.StatusOK)
+
+	patched := models.UserPrivateProfile{}
+	assert.Equal(t, json.Unmarshal(body, &patched), nil)
+	assert.Equal(t, etag, fmt.Sprintf(`"%d"`, patched.Version))
+	assert.Equal(t, patched.Version, user1.Version+1)
+	assert.Equal(t, patched.Bio, "Alchemist")
+	assert.Equal(t, patched.UserName, user1.UserName)
+	assert.Equal(t, patched.FirstName, user1.FirstName)
+	assert.Equal(t, patched.Location, user1.Location)
+	assert.Equal(t, patched.Interests, user1.Interests)
+
+	// null clears the optional fields
+	code, _, body = utils.PatchUserProfile(testRouter, resp.AccessToken, `{"bio": null}`, "")
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, json.Unmarshal(body, &patched), nil)
+	assert.Equal(t, patched.Bio, "")
+	assert.Equal(t, patched.Version, user1.Version+2)
+}
+
+func TestPatchProfileWithoutChangesKeepsTheVersion(t *testing.T) {
+	testRouter, user1, user1Password, _, _ := setUpEditProfileTests()
+	resp, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+
+	code, _, body := utils.PatchUserProfile(testRouter, resp.AccessToken, fmt.Sprintf(`{"username": "%s"}`, user1.UserName), "")
+	assert.Equal(t, code, http.StatusOK)
+
+	patched := models.UserPrivateProfile{}
+	assert.Equal(t, json.Unmarshal(body, &patched), nil)
+	assert.Equal(t, patched.Version, user1.Version)
+}
+
+func TestPatchProfileWithStaleVersionReturnsPreconditionFailed(t *testing.T) {
+	testRouter, user1, user1Password, _, _ := setUpEditProfileTests()
+	resp, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+
+	staleETag := fmt.Sprintf(`"%d"`, user1.Version)
+	code, etag, _ := utils.PatchUserProfile(testRouter, resp.AccessToken, `{"first_name": "Alphonse"}`, staleETag)
+	assert.Equal(t, code, http.StatusOK)
+
+	code, _, body := utils.PatchUserProfile(testRouter, resp.AccessToken, `{"first_name": "Hohenheim"}`, staleETag)
+	assert.Equal(t, code, http.StatusPreconditionFailed)
+	result := models.ErrorResponse{}
+	assert.Equal(t, json.Unmarshal(body, &result), nil)
+	assert.Equal(t, result.Title, "The profile was modified since it was retrieved")
+
+	code, _, _ = utils.PatchUserProfile(testRouter, resp.AccessToken, `{"first_name": "Hohenheim"}`, etag)
+	assert.Equal(t, code, http.StatusOK)
+}
+
+func TestPatchProfileWithInvalidFieldsReturnsProperValidationErrors(t *testing.T) {
+	testRouter, user1, user1Password, _, _ := setUpEditProfileTests()
+	resp, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+
+	code, _, body := utils.PatchUserProfile(testRouter, resp.AccessToken, `{"first_name": null, "email": "other@elric.com"}`, "")
+	assert.Equal(t, code, http.StatusBadRequest)
+	response := models.ValidationErrorResponse{}
+	assert.Equal(t, json.Unmarshal(body, &response), nil)
+	assert.Equal(t, len(response.Errors), 2)
+
+	code, _, body = utils.PatchUserProfile(testRouter, resp.AccessToken, `{"last_name": "E", "interests": [9000]}`, "")
+	assert.Equal(t, code, http.StatusBadRequest)
+	assert.Equal(t, json.Unmarshal(body, &response), nil)
+	assert.Equal(t, len(response.Errors), 2)
+}
@@ -449,3 +449,67 @@ func TestGetAmountOfFollowersInTimeRangeReturnsNoneIfThereAreNoNewFollowers(t *t
     assert.Equal(t, err, nil)
     assert.Equal(t, amount, 0)
 }
+
+func TestFollowAndUnfollowUpdateTheCounters(t *testing.T) {
+    testRouter, user1, user1Password, user2, _ := setUpFollowTests()
+    loginRequest := models.LoginRequest{
+        Email: user1.Email,
+        Password: user1Password, 
+    }
+    resp, err := utils.LoginValidUser(testRouter, loginRequest)
+    assert.Equal(t, err, nil)
+
+    err = utils.FollowValidUser(testRouter, user2.Id.String(), resp.AccessToken)
+    assert.Equal(t, err, nil)
+
+    follower, err := utils.GetOwnProfile(testRouter, user1.Id.String(), resp.AccessToken)
+    assert.Equal(t, err, nil)
+    assert.Equal(t, follower.Following, 1)
+    assert.Equal(t, follower.Followers, 0)
+
+    _, _, followed := utils.GetProfileByUsername(testRouter, user2.UserName, resp.AccessToken)
+    assert.Equal(t, followed.Followers, 1)
+    assert.Equal(t, followed.Following, 0)
+
+    err = utils.UnfollowValidUser(testRouter, user2.Id.String(), resp.AccessToken)
+    assert.Equal(t, err, nil)
+
+    follower, err = utils.GetOwnProfile(testRouter, user1.Id.String(), resp.AccessToken)
+    assert.Equal(t, err, nil)
+    assert.Equal(t, follower.Following, 0)
+
+    _, _, followed = utils.GetProfileByUsername(testRouter, user2.UserName, resp.AccessToken)
+    assert.Equal(t, followed.Followers, 0)
+}
+
+func TestReconcileFollowCountersRepairsDriftedCounters(t *testing.T) {
+    testRouter, user1, user1Password, user2, _ := setUpFollowTests()
+    loginRequest := models.LoginRequest{
+        Email: user1.Email,
+        Password: user1Password, 
+    }
+    resp, err := utils.LoginValidUser(testRouter, loginRequest)
+    assert.Equal(t, err, nil)
+
+    err = utils.FollowValidUser(testRouter, user2.Id.String(), resp.AccessToken)
+    assert.Equal(t, err, nil)
+
+    db := utils.OpenTestDatabase(t)
+    _, err = db.Exec("UPDATE users SET followers_count = 42, following_count = 7 WHERE id = $1", user2.Id)
+    assert.Equal(t, err, nil)
+
+    userService, userDb := utils.CreateTestUserService(t, db)
+    repaired, err := userDb.ReconcileFollowCounters()
+    assert.Equal(t, err, nil)
+    assert.Equal(t, repaired, 1)
+
+    _, _, followed := utils.GetProfileByUsername(testRouter, user2.UserName, resp.AccessToken)
+    assert.Equal(t, followed.Followers, 1)
+    assert.Equal(t, followed.Following, 0)
+
+    err = userService.ReconcileFollowCounters()
+    assert.Equal(t, err, nil)
+    repaired, err = userDb.ReconcileFollowCounters()
+    assert.Equal(t, err, nil)
+    assert.Equal(t, repaired, 0)
+}
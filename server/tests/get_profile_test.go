@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/go-playground/assert/v2"
@@ -186,3 +187,33 @@ func TestGetUserThatIsInRegistryReturnsNotFoundError(t *testing.T) {
 	assert.Equal(t, err, nil)
 	assert.Equal(t, result.Title, "User not found")
 }
+
+func TestGetProfileByUsernameIgnoresItsCase(t *testing.T) {
+	testRouter, user1, user1Password, user2, _ := setUpEditProfileTests()
+	resp, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+
+	code, _, profile := utils.GetProfileByUsername(testRouter, strings.ToLower(user2.UserName), resp.AccessToken)
+
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, profile.Id, user2.Id)
+	assert.Equal(t, profile.UserName, user2.UserName)
+}
+
+func TestGetBlockedUserProfileByUsernameIsOnlyFoundByAdmins(t *testing.T) {
+	testRouter, user1, user1Password, user2, _ := setUpEditProfileTests()
+	resp, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+	adminToken, err := utils.LoginAdmin()
+	assert.Equal(t, err, nil)
+
+	err = utils.BlockUser(testRouter, user2.Id.String(), "You are blocked", adminToken)
+	assert.Equal(t, err, nil)
+
+	code, _, _ := utils.GetProfileByUsername(testRouter, user2.UserName, resp.AccessToken)
+	assert.Equal(t, code, http.StatusNotFound)
+
+	code, _, profile := utils.GetProfileByUsername(testRouter, user2.UserName, adminToken)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, profile.Id, user2.Id)
+}
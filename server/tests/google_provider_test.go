@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"users-service/src/router"
+	"users-service/tests/models"
+	"users-service/tests/utils"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestResolveWithGoogleProvider(t *testing.T) {
+	testRouter, user1, user1Password, _, _ := setUpEditProfileTests(utils.WithFakeGoogle())
+
+	token := utils.FakeGoogleToken("google-uid-1", user1.Email, nil)
+	code, _, _ := utils.ResolveWithProvider(testRouter, "someone.else@gmail.com", "GOOGLE", map[string]string{"firebase_token_id": token})
+	assert.Equal(t, code, http.StatusUnauthorized)
+	code, _, _ = utils.ResolveWithProvider(testRouter, user1.Email, "GOOGLE", map[string]string{"firebase_token_id": utils.FakeGoogleToken("google-uid-1", user1.Email, jwt.MapClaims{"email_verified": false})})
+	assert.Equal(t, code, http.StatusUnauthorized)
+	code, _, _ = utils.ResolveWithProvider(testRouter, user1.Email, "GOOGLE", map[string]string{"firebase_token_id": utils.FakeGoogleToken("google-uid-1", user1.Email, jwt.MapClaims{"exp": 1})})
+	assert.Equal(t, code, http.StatusUnauthorized)
+	code, _, _ = utils.ResolveWithProvider(testRouter, user1.Email, "GOOGLE", map[string]string{"id_token": token})
+	assert.Equal(t, code, http.StatusBadRequest)
+
+	code, resp, err := utils.ResolveWithProvider(testRouter, user1.Email, "GOOGLE", map[string]string{"firebase_token_id": token})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, resp.NextAuthStep, "LOGIN")
+
+	login, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+	code, _, _ = utils.LinkUserIdentity(testRouter, login.AccessToken, "GOOGLE", map[string]string{"firebase_token_id": token})
+	assert.Equal(t, code, http.StatusCreated)
+
+	code, resp, err = utils.ResolveWithProvider(testRouter, user1.Email, "GOOGLE", map[string]string{"firebase_token_id": token})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, resp.NextAuthStep, "SESSION")
+
+	code, resp, err = utils.ResolveWithProvider(testRouter, "", "GOOGLE", map[string]string{"firebase_token_id": utils.FakeGoogleToken("google-uid-2", "new.google.user@gmail.com", nil)})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, resp.NextAuthStep, "SIGN_UP")
+}
+
+// registerWithGoogle takes a registry started with a Google token through every onboarding step but the last one,
+// it returns its id
+func registerWithGoogle(t *testing.T, testRouter *router.Router, token string) string {
+	code, resp, err := utils.ResolveWithProvider(testRouter, "", "GOOGLE", map[string]string{"firebase_token_id": token})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	id := resp.Metadata.(map[string]interface{})["registration_id"].(string)
+
+	err = utils.SendEmailVerificationAndVerificateIt(testRouter, id)
+	assert.Equal(t, err, nil)
+	err = utils.PutValidUserPersonalInfo(testRouter, id, models.UserPersonalInfo{
+		FirstName: "Monke",
+		LastName:  "Google",
+		UserName:  "GoogleMonke",
+		Password:  "Holaa&2dS",
+		Location:  0,
+	})
+	assert.Equal(t, err, nil)
+	err = utils.PutValidInterests(testRouter, id, []int{0})
+	assert.Equal(t, err, nil)
+	return id
+}
+
+func TestGoogleRegistryIsKeptWhenItsIdentityCantBeBound(t *testing.T) {
+	testRouter, user1, _, _, _ := setUpEditProfileTests(utils.WithFakeGoogle())
+
+	email := "new.google.user@gmail.com"
+	token := utils.FakeGoogleToken("google-uid-2", email, nil)
+	id := registerWithGoogle(t, testRouter, token)
+
+	// the Google account gets bound to another user before the registry is completed
+	db := utils.OpenTestDatabase(t)
+	_, err := db.Exec("INSERT INTO user_identities (user_id, provider, subject, email) VALUES ($1, 'GOOGLE', 'google-uid-2', $2)", user1.Id, user1.Email)
+	assert.Equal(t, err, nil)
+
+	code, response, err := utils.CompleteInvalidRegistry(testRouter, id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusConflict)
+	assert.Equal(t, response.Title, "The identity provider account is linked to another user")
+
+	registry, err := utils.GetUserRegistryForSignUp(testRouter, email)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, registry.NextAuthStep, "SIGN_UP")
+	assert.Equal(t, registry.Metadata.OnboardingStep, "COMPLETE")
+
+	_, err = db.Exec("DELETE FROM user_identities WHERE subject = 'google-uid-2'")
+	assert.Equal(t, err, nil)
+
+	profile, err := utils.CompleteValidRegistry(testRouter, id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, profile.Email, email)
+
+	code, resp, err := utils.ResolveWithProvider(testRouter, email, "GOOGLE", map[string]string{"firebase_token_id": token})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, resp.NextAuthStep, "SESSION")
+}
+
+func TestAccountsRegisteredWithGoogleBeforeTheIdentitiesAreLinkedOnce(t *testing.T) {
+	testRouter, _, _, _, _ := setUpEditProfileTests(utils.WithFakeGoogle())
+
+	email := "old.google.user@gmail.com"
+	token := utils.FakeGoogleToken("google-uid-3", email, nil)
+	id := registerWithGoogle(t, testRouter, token)
+	profile, err := utils.CompleteValidRegistry(testRouter, id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, profile.Email, email)
+
+	// the account is taken back to before the identities were stored, only its registry knows it used Google
+	db := utils.OpenTestDatabase(t)
+	_, err = db.Exec("DELETE FROM user_identities WHERE user_id = $1", profile.Id)
+	assert.Equal(t, err, nil)
+	_, err = db.Exec("UPDATE registry_entries SET identity_subject = '' WHERE email = $1", email)
+	assert.Equal(t, err, nil)
+
+	code, resp, err := utils.ResolveWithProvider(testRouter, email, "GOOGLE", map[string]string{"firebase_token_id": token})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, resp.NextAuthStep, "SESSION")
+
+	// once unlinked, it is not linked again by its email
+	_, err = db.Exec("DELETE FROM user_identities WHERE user_id = $1", profile.Id)
+	assert.Equal(t, err, nil)
+
+	code, resp, err = utils.ResolveWithProvider(testRouter, email, "GOOGLE", map[string]string{"firebase_token_id": token})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, resp.NextAuthStep, "LOGIN")
+}
+
+func TestUnsignedGoogleTokensAreRejectedWithoutTheFakeVerifier(t *testing.T) {
+	testRouter, user1, _, _, _ := setUpEditProfileTests()
+
+	token := utils.FakeGoogleToken("google-uid-1", user1.Email, nil)
+	code, _, _ := utils.ResolveWithProvider(testRouter, user1.Email, "GOOGLE", map[string]string{"firebase_token_id": token})
+	assert.NotEqual(t, code, http.StatusOK)
+}
@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"users-service/tests/models"
+	"users-service/tests/utils"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestLinkAndUnlinkIdentityProviders(t *testing.T) {
+	issuer := utils.NewFakeIssuer(t)
+	issuer.UseAsProvider(t, "GITHUB")
+	testRouter, user1, user1Password, user2, user2Password := setUpEditProfileTests()
+
+	login1, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+	login2, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user2.Email, Password: user2Password})
+	assert.Equal(t, err, nil)
+
+	// the provider account may have another email than the user
+	token := issuer.Token("github-subject-1", "edward.at.github@gmail.com", nil)
+	code, identity, err := utils.LinkUserIdentity(testRouter, login1.AccessToken, "GITHUB", map[string]string{"id_token": token})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusCreated)
+	assert.Equal(t, identity.Provider, "GITHUB")
+	assert.Equal(t, identity.Email, "edward.at.github@gmail.com")
+
+	code, resp, err := utils.ResolveWithProvider(testRouter, "", "GITHUB", map[string]string{"id_token": token})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, resp.NextAuthStep, "SESSION")
+	profile := resp.Metadata.(map[string]interface{})["profile"].(map[string]interface{})
+	assert.Equal(t, profile["email"], user1.Email)
+
+	// the provider account can't be linked twice, nor the user have two accounts of the provider
+	code, _, _ = utils.LinkUserIdentity(testRouter, login2.AccessToken, "GITHUB", map[string]string{"id_token": token})
+	assert.Equal(t, code, http.StatusConflict)
+	code, _, _ = utils.LinkUserIdentity(testRouter, login1.AccessToken, "GITHUB", map[string]string{"id_token": issuer.Token("github-subject-2", user1.Email, nil)})
+	assert.Equal(t, code, http.StatusConflict)
+	code, _, _ = utils.LinkUserIdentity(testRouter, login1.AccessToken, "GITHUB", map[string]string{"id_token": issuer.Token("github-subject-2", user1.Email, jwt.MapClaims{"exp": 1})})
+	assert.Equal(t, code, http.StatusUnauthorized)
+
+	code, identities, err := utils.GetUserIdentities(testRouter, login1.AccessToken)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, len(identities), 1)
+
+	// the user keeps the password to sign in with
+	code = utils.UnlinkUserIdentity(testRouter, login1.AccessToken, "GITHUB")
+	assert.Equal(t, code, http.StatusNoContent)
+	code = utils.UnlinkUserIdentity(testRouter, login1.AccessToken, "GITHUB")
+	assert.Equal(t, code, http.StatusNotFound)
+
+	code, resp, _ = utils.ResolveWithProvider(testRouter, "", "GITHUB", map[string]string{"id_token": token})
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, resp.NextAuthStep, "SIGN_UP")
+}
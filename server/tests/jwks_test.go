@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"users-service/src/auth"
+	"users-service/tests/models"
+	"users-service/tests/utils"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestLoginTokenIsSignedWithAKeyOfTheJWKS(t *testing.T) {
+	testRouter, user1, user1Password, _, _ := setUpEditProfileTests()
+	resp, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+
+	token, _, err := jwt.NewParser().ParseUnverified(resp.AccessToken, jwt.MapClaims{})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, token.Method.Alg(), "RS256")
+
+	code, jwks, err := utils.GetJWKS(testRouter)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+
+	found := false
+	for _, key := range jwks.Keys {
+		if key.Kid == token.Header["kid"] {
+			found = true
+			assert.Equal(t, key.Kty, "RSA")
+			assert.NotEqual(t, key.N, "")
+		}
+	}
+	assert.Equal(t, found, true)
+}
+
+func TestTokensSignedWithTheOldSecretAreRejected(t *testing.T) {
+	testRouter, user1, _, user2, _ := setUpEditProfileTests()
+
+	userToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id":    user1.Id.String(),
+		"user_admin": false,
+		"exp":        time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("MonkeCrack"))
+	assert.Equal(t, err, nil)
+
+	code, _, _ := utils.GetProfileByUsername(testRouter, user2.UserName, userToken)
+	assert.Equal(t, code, http.StatusUnauthorized)
+
+	serviceToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"client_id": "notifications",
+		"scope":     "users:read",
+		"aud":       "ThePsyducks-internal",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("MonkeCrack"))
+	assert.Equal(t, err, nil)
+
+	code, _, _ = utils.GetInternalUserProfile(testRouter, user2.Id.String(), serviceToken)
+	assert.Equal(t, code, http.StatusUnauthorized)
+}
+
+func TestSigningKeysAreRotatedByTheCreationTimeInTheirFileName(t *testing.T) {
+	dir := t.TempDir()
+	keys, err := auth.NewKeyManager(dir, auth.AlgorithmRS256, time.Hour, 24*time.Hour)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(keys.JWKS().Keys), 1)
+	kid := keys.JWKS().Keys[0].Kid
+
+	files, err := os.ReadDir(dir)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(files), 1)
+
+	// the file is renamed as if it was created before the rotation interval, its modification time is still now
+	created := time.Now().Add(-2 * time.Hour).Unix()
+	err = os.Rename(filepath.Join(dir, files[0].Name()), filepath.Join(dir, fmt.Sprintf("%d-%s.pem", created, kid)))
+	assert.Equal(t, err, nil)
+
+	keys, err = auth.NewKeyManager(dir, auth.AlgorithmRS256, time.Hour, 24*time.Hour)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(keys.JWKS().Keys), 2)
+	assert.Equal(t, keys.JWKS().Keys[0].Kid, kid)
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
@@ -25,12 +27,20 @@ type UserPersonalInfo struct {
 	UserName  string `json:"username"`
 	Password  string `json:"password"`
 	Location  int    `json:"location"`
+	BirthDate string `json:"birth_date,omitempty"`
 }
 
 type UserProfileResponse struct {
-	OwnProfile bool        `json:"own_profile" binding:"required"`
-	Follows    bool        `json:"follows" binding:"required"`
-	Profile    interface{} `json:"profile" binding:"required"`
+	OwnProfile  bool        `json:"own_profile" binding:"required"`
+	Follows     bool        `json:"follows" binding:"required"`
+	FollowedBy  bool        `json:"followed_by"`
+	MutualCount int         `json:"mutual_count"`
+	Profile     interface{} `json:"profile" binding:"required"`
+}
+
+type RelationshipResponse struct {
+	Follows    bool `json:"follows"`
+	FollowedBy bool `json:"followed_by"`
 }
 
 type UserPublicProfile struct {
@@ -41,6 +51,11 @@ type UserPublicProfile struct {
 	Location  string    `json:"location" binding:"required"`
 	Followers int       `json:"followers" binding:"required"`
 	Following int       `json:"following" binding:"required"`
+	Bio       string    `json:"bio"`
+	Website   string    `json:"website"`
+	BirthDate string    `json:"birth_date"`
+	Pronouns  string    `json:"pronouns"`
+	BannerPath string   `json:"banner_path"`
 }
 
 type UserPrivateProfile struct {
@@ -53,6 +68,13 @@ type UserPrivateProfile struct {
 	Interests []string  `json:"interests" binding:"required"`
 	Followers int       `json:"followers" binding:"required"`
 	Following int       `json:"following" binding:"required"`
+	Bio       string    `json:"bio"`
+	Website   string    `json:"website"`
+	BirthDate string    `json:"birth_date"`
+	Pronouns  string    `json:"pronouns"`
+	BannerPath string   `json:"banner_path"`
+	Visibility map[string]string `json:"visibility"`
+	Version   int       `json:"version"`
 }
 
 type UserInformationResponse struct {
@@ -63,11 +85,20 @@ type UserInformationResponse struct {
 type Location struct {
 	Id   int    `json:"id"`
 	Name string `json:"name"`
+	Kind string `json:"kind"`
 }
 
 type Interest struct {
+	Id         int    `json:"id"`
+	Name       string `json:"name"`
+	CategoryId *int   `json:"category_id"`
+	Icon       string `json:"icon"`
+}
+
+type InterestCategory struct {
 	Id   int    `json:"id"`
 	Name string `json:"name"`
+	Icon string `json:"icon"`
 }
 
 type RegisterOptions struct {
@@ -113,16 +144,26 @@ type EditUserProfileRequest struct {
 	LastName  string `json:"last_name"`
 	Location  int    `json:"location"`
 	Interests []int  `json:"interests"`
+	Bio       string `json:"bio,omitempty"`
+	Website   string `json:"website,omitempty"`
+	BirthDate string `json:"birth_date,omitempty"`
+	Pronouns  string `json:"pronouns,omitempty"`
+	BannerPath string `json:"banner_path,omitempty"`
+	Visibility map[string]string `json:"visibility,omitempty"`
 }
 
 type FollowUserProfile struct {
-	Follows bool `json:"follows"`
-	Profile UserPublicProfile `json:"profile"`
+	Follows    bool              `json:"follows"`
+	Profile    UserPublicProfile `json:"profile"`
+	Highlights map[string]string `json:"highlights"`
+	Reasons    []string          `json:"reasons"`
+	DistanceKm float64           `json:"distance_km"`
 }
 
 type Pagination struct {
-	NextOffset int `json:"next_offset"`
-	Limit      int `json:"limit"`
+	NextOffset int    `json:"next_offset"`
+	NextCursor string `json:"next_cursor"`
+	Limit      int    `json:"limit"`
 }
 
 type FollowersResponse struct {
@@ -140,3 +181,76 @@ type PaginationResponse[T any] struct {
 	Pagination Pagination `json:"pagination" binding:"required"`
 }
 
+
+type UserSuggestion struct {
+	Id          uuid.UUID `json:"id"`
+	UserName    string    `json:"username"`
+	Name        string    `json:"name"`
+	PicturePath string    `json:"picture_path"`
+}
+
+type CatalogEntry struct {
+	Id           int               `json:"id"`
+	Name         string            `json:"name"`
+	Position     int               `json:"position"`
+	Deprecated   bool              `json:"deprecated"`
+	Translations map[string]string `json:"translations"`
+}
+
+type CompactUserProfile struct {
+	Id          uuid.UUID `json:"id"`
+	UserName    string    `json:"username"`
+	FirstName   string    `json:"first_name"`
+	LastName    string    `json:"last_name"`
+	PicturePath string    `json:"picture_path"`
+}
+
+type BatchUser struct {
+	Profile    CompactUserProfile `json:"profile"`
+	Follows    bool               `json:"follows"`
+	FollowedBy bool               `json:"followed_by"`
+}
+
+type UserBatchResponse struct {
+	Users     map[string]*BatchUser `json:"users"`
+	Usernames map[string]*uuid.UUID `json:"usernames"`
+}
+
+type ServiceClient struct {
+	Id        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
+
+type ServiceClientCredentials struct {
+	Client       ServiceClient `json:"client"`
+	ClientSecret string        `json:"client_secret"`
+}
+
+type ServiceTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Scope       string `json:"scope"`
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+type UserIdentity struct {
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"users-service/src/router"
+	"users-service/tests/models"
+	"users-service/tests/utils"
+
+	"github.com/go-playground/assert/v2"
+)
+
+// setUpNearbyTests creates users in Buenos Aires, La Plata (about 53 km away), Montevideo (about 205 km away)
+// and Santiago de Chile (more than 1000 km away), returning the token of the one in Buenos Aires
+func setUpNearbyTests() (testRouter *router.Router, token string) {
+	testRouter, err := router.CreateRouter()
+	if err != nil {
+		panic("Failed to create router: " + err.Error())
+	}
+
+	users := []struct {
+		email    string
+		username string
+		location []string
+	}{
+		{"roy@mustang.com", "RoyMustang", []string{"Argentina", "Ciudad Autónoma de Buenos Aires", "Buenos Aires"}},
+		{"riza@hawkeye.com", "RizaHawkeye", []string{"Argentina", "Buenos Aires", "La Plata"}},
+		{"maes@hughes.com", "MaesHughes", []string{"Uruguay", "Montevideo", "Montevideo"}},
+		{"alex@armstrong.com", "AlexArmstrong", []string{"Chile", "Región Metropolitana", "Santiago"}},
+	}
+
+	for i, user := range users {
+		locationId, err := utils.FindLocationId(testRouter, user.location...)
+		if err != nil {
+			panic("Failed to find location: " + err.Error())
+		}
+
+		personalInfo := models.UserPersonalInfo{
+			FirstName: "State",
+			LastName:  "Alchemist",
+			UserName:  user.username,
+			Password:  "Alchemist$St4te:)",
+			Location:  locationId,
+		}
+		login, err := utils.CreateAndLoginUser(testRouter, user.email, personalInfo, []int{0})
+		if err != nil {
+			panic("Failed to create user: " + err.Error())
+		}
+		if i == 0 {
+			token = login.AccessToken
+		}
+	}
+
+	return testRouter, token
+}
+
+func TestUserRegisteredInACityShowsItsFullLocation(t *testing.T) {
+	testRouter, err := router.CreateRouter()
+	assert.Equal(t, err, nil)
+
+	locationId, err := utils.FindLocationId(testRouter, "Argentina", "Santa Fe", "Rosario")
+	assert.Equal(t, err, nil)
+
+	personalInfo := models.UserPersonalInfo{
+		FirstName: "Izumi",
+		LastName:  "Curtis",
+		UserName:  "IzumiCurtis",
+		Password:  "Izumi$Curt1s:)",
+		Location:  locationId,
+	}
+	login, err := utils.CreateAndLoginUser(testRouter, "izumi@curtis.com", personalInfo, []int{0})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, login.Profile.Location, "Rosario, Santa Fe, Argentina")
+}
+
+func TestNearbyUsersWithinTheRadius(t *testing.T) {
+	testRouter, token := setUpNearbyTests()
+
+	code, users, err := utils.GetNearbyUsers(testRouter, token, "100")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, len(users), 1)
+	assert.Equal(t, users[0].Profile.UserName, "RizaHawkeye")
+	assert.Equal(t, users[0].DistanceKm > 45 && users[0].DistanceKm < 60, true)
+}
+
+func TestNearbyUsersAreSortedByDistance(t *testing.T) {
+	testRouter, token := setUpNearbyTests()
+
+	code, users, err := utils.GetNearbyUsers(testRouter, token, "500")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, len(users), 2)
+	assert.Equal(t, users[0].Profile.UserName, "RizaHawkeye")
+	assert.Equal(t, users[1].Profile.UserName, "MaesHughes")
+	assert.Equal(t, users[1].Profile.Location, "Montevideo, Montevideo, Uruguay")
+}
+
+func TestNearbyUsersWithInvalidRadiusReturnsBadRequest(t *testing.T) {
+	testRouter, token := setUpNearbyTests()
+
+	code, _, err := utils.GetNearbyUsers(testRouter, token, "-5")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusBadRequest)
+}
+
+func TestSearchByCountryFindsTheUsersInItsCities(t *testing.T) {
+	testRouter, token := setUpNearbyTests()
+
+	users, err := utils.SearchUsersWithQuery(testRouter, "location=0", token, 10)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(users), 2)
+}
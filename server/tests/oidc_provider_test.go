@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"users-service/tests/models"
+	"users-service/tests/utils"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestResolveWithOIDCProviderUsesTheVerifiedEmail(t *testing.T) {
+	issuer := utils.NewFakeIssuer(t)
+	issuer.UseAsProvider(t, "GITHUB")
+	testRouter, user1, _, user2, _ := setUpEditProfileTests()
+
+	// a valid token can't log into the account of another email
+	token := issuer.Token("github-subject-1", user1.Email, nil)
+	code, _, err := utils.ResolveWithProvider(testRouter, user2.Email, "GITHUB", map[string]string{"id_token": token})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusUnauthorized)
+
+	// the account of the verified email is not linked to the provider, its user has to log in
+	code, resp, err := utils.ResolveWithProvider(testRouter, user1.Email, "GITHUB", map[string]string{"id_token": token})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, resp.NextAuthStep, "LOGIN")
+
+	token = issuer.Token("github-subject-2", "new.github.user@gmail.com", nil)
+	code, resp, err = utils.ResolveWithProvider(testRouter, "", "GITHUB", map[string]string{"id_token": token})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, resp.NextAuthStep, "SIGN_UP")
+}
+
+func TestResolveWithOIDCProviderLogsInBySubject(t *testing.T) {
+	issuer := utils.NewFakeIssuer(t)
+	issuer.UseAsProvider(t, "GITHUB")
+	testRouter, user1, user1Password, _, _ := setUpEditProfileTests()
+
+	login, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+	code, _, _ := utils.LinkUserIdentity(testRouter, login.AccessToken, "GITHUB", map[string]string{"id_token": issuer.Token("github-subject-1", user1.Email, nil)})
+	assert.Equal(t, code, http.StatusCreated)
+
+	// the subject keeps logging into the account after its email changed at the provider
+	code, resp, err := utils.ResolveWithProvider(testRouter, "", "GITHUB", map[string]string{"id_token": issuer.Token("github-subject-1", "renamed@gmail.com", nil)})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, resp.NextAuthStep, "SESSION")
+	profile := resp.Metadata.(map[string]interface{})["profile"].(map[string]interface{})
+	assert.Equal(t, profile["email"], user1.Email)
+
+	// another subject of the provider can't take over the account by its email
+	code, resp, _ = utils.ResolveWithProvider(testRouter, "", "GITHUB", map[string]string{"id_token": issuer.Token("github-subject-3", user1.Email, nil)})
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, resp.NextAuthStep, "LOGIN")
+}
+
+func TestResolveWithOIDCProviderRejectsInvalidTokens(t *testing.T) {
+	issuer := utils.NewFakeIssuer(t)
+	issuer.UseAsProvider(t, "APPLE")
+	testRouter, user1, _, _, _ := setUpEditProfileTests()
+
+	invalidTokens := []string{
+		issuer.Token("apple-subject", user1.Email, jwt.MapClaims{"aud": "another-client"}),
+		issuer.Token("apple-subject", user1.Email, jwt.MapClaims{"iss": "https://another.issuer"}),
+		issuer.Token("apple-subject", user1.Email, jwt.MapClaims{"exp": 1}),
+		issuer.Token("apple-subject", user1.Email, jwt.MapClaims{"email_verified": false}),
+		utils.NewFakeIssuer(t).Token("apple-subject", user1.Email, nil),
+	}
+	for _, token := range invalidTokens {
+		code, _, _ := utils.ResolveWithProvider(testRouter, user1.Email, "APPLE", map[string]string{"id_token": token})
+		assert.Equal(t, code, http.StatusUnauthorized)
+	}
+
+	code, _, _ := utils.ResolveWithProvider(testRouter, user1.Email, "GITLAB", map[string]string{"id_token": issuer.Token("apple-subject", user1.Email, nil)})
+	assert.Equal(t, code, http.StatusBadRequest)
+}
@@ -0,0 +1,35 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+
+	"users-service/tests/utils"
+)
+
+func TestGetAllUsersWithCursorReturnsEveryUserOnce(t *testing.T) {
+	testRouter, user1, _, user2, _ := setUpBlockTests()
+
+	adminToken, err := utils.LoginAdmin()
+	assert.Equal(t, err, nil)
+
+	users, err := utils.GetAllUsersWithCursor(testRouter, adminToken, 1)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(users), 2)
+	assert.Equal(t, users[0].Id, user2.Id)
+	assert.Equal(t, users[1].Id, user1.Id)
+}
+
+func TestGetAllUsersWithInvalidCursorReturnsBadRequest(t *testing.T) {
+	testRouter, _, _, _, _ := setUpBlockTests()
+
+	adminToken, err := utils.LoginAdmin()
+	assert.Equal(t, err, nil)
+
+	code, response, err := utils.GetAllUsersWithInvalidCursor(testRouter, adminToken, "not-a-cursor")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusBadRequest)
+	assert.Equal(t, response.Status, http.StatusBadRequest)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"testing"
 	"time"
+	"users-service/src/constants"
 	"users-service/src/router"
 	"users-service/tests/models"
 	"users-service/tests/utils"
@@ -154,3 +155,175 @@ func TestGetRecommendationsForUserReturnsPaginatedRecommendations(t *testing.T)
 }
 
 
+
+func TestGetRecommendationsIncludesUsersFollowedByFollowedUsers(t *testing.T) {
+	testRouter, user1, user1Password, user2, user2Password, user3, _, user4, _ := setUpRecommendationTests()
+
+	user1Login, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+	user2Login, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user2.Email, Password: user2Password})
+	assert.Equal(t, err, nil)
+
+	err = utils.FollowValidUser(testRouter, user2.Id.String(), user1Login.AccessToken)
+	assert.Equal(t, err, nil)
+	err = utils.FollowValidUser(testRouter, user3.Id.String(), user2Login.AccessToken)
+	assert.Equal(t, err, nil)
+
+	recommendations, err := utils.GetAllUserRecommendations(testRouter, user1Login.AccessToken, 10)
+	assert.Equal(t, err, nil)
+
+	assert.Equal(t, len(recommendations), 2)
+	assert.Equal(t, recommendations[0].Profile.Id, user4.Id)
+	assert.Equal(t, recommendations[0].Reasons, []string{"1 interest in common", "also from Argentina"})
+	assert.Equal(t, recommendations[1].Profile.Id, user3.Id)
+	assert.Equal(t, recommendations[1].Reasons, []string{"followed by 1 person you follow"})
+}
+
+func TestDismissedRecommendationsAreNotReturned(t *testing.T) {
+	testRouter, user1, user1Password, user2, _, _, _, user4, _ := setUpRecommendationTests()
+
+	response, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+
+	code, err := utils.DismissRecommendation(testRouter, user4.Id.String(), response.AccessToken, nil)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusNoContent)
+
+	expiresAt := time.Now().Add(time.Hour)
+	code, err = utils.DismissRecommendation(testRouter, user2.Id.String(), response.AccessToken, &expiresAt)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusNoContent)
+
+	recommendations, err := utils.GetAllUserRecommendations(testRouter, response.AccessToken, 10)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(recommendations), 0)
+}
+
+func TestDismissRecommendationWithPastExpirationReturnsError(t *testing.T) {
+	testRouter, user1, user1Password, _, _, _, _, user4, _ := setUpRecommendationTests()
+
+	response, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+
+	expiresAt := time.Now().Add(-time.Hour)
+	code, err := utils.DismissRecommendation(testRouter, user4.Id.String(), response.AccessToken, &expiresAt)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusBadRequest)
+
+	recommendations, err := utils.GetAllUserRecommendations(testRouter, response.AccessToken, 10)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(recommendations), 2)
+}
+
+func TestRepeatedlyShownRecommendationsAreDownRanked(t *testing.T) {
+	testRouter, user1, user1Password, user2, _, _, _, user4, _ := setUpRecommendationTests()
+
+	response, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+
+	// only the first page is fetched, so user4 is the only one shown, until it was shown more times than the free ones
+	for i := 0; i < 4; i++ {
+		listedAt := time.Unix(time.Now().Unix()+int64(10+i), 0)
+		recommendations, err := utils.GetUserRecommendationsPage(testRouter, response.AccessToken, 1, listedAt)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, len(recommendations), 1)
+		assert.Equal(t, recommendations[0].Profile.Id, user4.Id)
+	}
+
+	recommendations, err := utils.GetAllUserRecommendations(testRouter, response.AccessToken, 10)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(recommendations), 2)
+	assert.Equal(t, recommendations[0].Profile.Id, user2.Id)
+	assert.Equal(t, recommendations[1].Profile.Id, user4.Id)
+}
+
+func TestRecommendationsPaginatedWithCursorDontRepeatUsers(t *testing.T) {
+	testRouter, user1, user1Password, user2, _, _, _, user4, _ := setUpRecommendationTests()
+
+	response, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+
+	// user4 is shown as many times as the free impressions, so the next listing down-ranks it below user2
+	for i := 0; i < 3; i++ {
+		listedAt := time.Unix(time.Now().Unix()+int64(10+i), 0)
+		_, err := utils.GetUserRecommendationsPage(testRouter, response.AccessToken, 1, listedAt)
+		assert.Equal(t, err, nil)
+	}
+
+	// the impression made by the first page doesn't re-rank the pages that follow it
+	recommendations, err := utils.GetUserRecommendationsWithCursor(testRouter, response.AccessToken, 1, time.Unix(time.Now().Unix()+20, 0))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(recommendations), 2)
+	assert.Equal(t, recommendations[0].Profile.Id, user4.Id)
+	assert.Equal(t, recommendations[1].Profile.Id, user2.Id)
+}
+
+func TestRefreshStaleRecommendationsRefreshesEveryUser(t *testing.T) {
+	_, user1, _, _, _, _, _, _, _ := setUpRecommendationTests()
+
+	db := utils.OpenTestDatabase(t)
+	userService, userDb := utils.CreateTestUserService(t, db)
+
+	stale, err := userDb.GetUsersWithStaleRecommendations(time.Now().Add(time.Minute), 10)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(stale), 4)
+
+	err = userService.RefreshStaleRecommendations()
+	assert.Equal(t, err, nil)
+
+	stale, err = userDb.GetUsersWithStaleRecommendations(time.Now().Add(-time.Minute), 10)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(stale), 0)
+
+	var precomputed int
+	err = db.Get(&precomputed, "SELECT COUNT(*) FROM user_recommendations WHERE user_id = $1", user1.Id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, precomputed, 2)
+}
+
+func TestRefreshedRecommendationsAreReadFromThePrecomputedOnes(t *testing.T) {
+	testRouter, user1, user1Password, user2, _, user3, _, user4, _ := setUpRecommendationTests()
+
+	response, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+
+	db := utils.OpenTestDatabase(t)
+	_, userDb := utils.CreateTestUserService(t, db)
+	err = userDb.RefreshRecommendations(user1.Id, constants.PrecomputedRecommendations)
+	assert.Equal(t, err, nil)
+
+	// the precomputed ones are changed by hand, so only reading them returns user3 and not user2
+	_, err = db.Exec("DELETE FROM user_recommendations WHERE user_id = $1 AND recommended_id = $2", user1.Id, user2.Id)
+	assert.Equal(t, err, nil)
+	_, err = db.Exec(`INSERT INTO user_recommendations (user_id, recommended_id, followed_by_following, shared_interests, shared_categories, same_location, score)
+		VALUES ($1, $2, 0, 0, 0, false, 100)`, user1.Id, user3.Id)
+	assert.Equal(t, err, nil)
+
+	recommendations, err := utils.GetAllUserRecommendations(testRouter, response.AccessToken, 10)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(recommendations), 2)
+	assert.Equal(t, recommendations[0].Profile.Id, user3.Id)
+	assert.Equal(t, recommendations[1].Profile.Id, user4.Id)
+
+	// the users followed after the refresh are left out until the next one removes them
+	err = utils.FollowValidUser(testRouter, user4.Id.String(), response.AccessToken)
+	assert.Equal(t, err, nil)
+
+	recommendations, err = utils.GetAllUserRecommendations(testRouter, response.AccessToken, 10)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(recommendations), 1)
+	assert.Equal(t, recommendations[0].Profile.Id, user3.Id)
+
+	err = userDb.RefreshRecommendations(user1.Id, constants.PrecomputedRecommendations)
+	assert.Equal(t, err, nil)
+
+	var precomputed []string
+	err = db.Select(&precomputed, "SELECT recommended_id::text FROM user_recommendations WHERE user_id = $1", user1.Id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, precomputed, []string{user2.Id.String()})
+
+	recommendations, err = utils.GetAllUserRecommendations(testRouter, response.AccessToken, 10)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(recommendations), 1)
+	assert.Equal(t, recommendations[0].Profile.Id, user2.Id)
+}
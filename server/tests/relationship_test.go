@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"users-service/src/router"
+	"users-service/tests/models"
+	"users-service/tests/utils"
+
+	"github.com/go-playground/assert/v2"
+)
+
+// setUpRelationshipTests creates three users where user1 follows user2, user2 follows user3 and user3 follows user1
+func setUpRelationshipTests() (testRouter *router.Router, user1Token string, user2 models.UserPrivateProfile, user3 models.UserPrivateProfile) {
+	testRouter, user1, user1Password, user2, user2Password := setUpSearchTests()
+
+	user := models.UserPersonalInfo{
+		FirstName: "Alphonse",
+		LastName:  "Elric",
+		UserName:  "AlphonseElric",
+		Password:  "Alphonse$El1ric:)",
+		Location:  0,
+	}
+	user3Login, err := utils.CreateAndLoginUser(testRouter, "alphonse@elric.com", user, []int{0})
+	if err != nil {
+		panic("Failed to create user3: " + err.Error())
+	}
+	user3 = user3Login.Profile
+
+	user1Login, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	if err != nil {
+		panic("Failed to login user1: " + err.Error())
+	}
+	user2Login, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user2.Email, Password: user2Password})
+	if err != nil {
+		panic("Failed to login user2: " + err.Error())
+	}
+
+	follows := []struct {
+		id    string
+		token string
+	}{
+		{user2.Id.String(), user1Login.AccessToken},
+		{user3.Id.String(), user2Login.AccessToken},
+		{user1.Id.String(), user3Login.AccessToken},
+	}
+	for _, follow := range follows {
+		if err := utils.FollowValidUser(testRouter, follow.id, follow.token); err != nil {
+			panic("Failed to follow user: " + err.Error())
+		}
+	}
+
+	return testRouter, user1Login.AccessToken, user2, user3
+}
+
+func TestGetRelationshipWithUserThatFollowsYou(t *testing.T) {
+	testRouter, user1Token, _, user3 := setUpRelationshipTests()
+
+	code, relationship, err := utils.GetRelationship(testRouter, user3.Id.String(), user1Token)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, relationship, models.RelationshipResponse{Follows: false, FollowedBy: true})
+}
+
+func TestGetRelationshipWithFollowedUser(t *testing.T) {
+	testRouter, user1Token, user2, _ := setUpRelationshipTests()
+
+	code, relationship, err := utils.GetRelationship(testRouter, user2.Id.String(), user1Token)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, relationship, models.RelationshipResponse{Follows: true, FollowedBy: false})
+}
+
+func TestGetRelationshipWithNotExistingUserReturnsNotFound(t *testing.T) {
+	testRouter, user1Token, _, _ := setUpRelationshipTests()
+
+	code, _, err := utils.GetRelationship(testRouter, "e3901fcc-9287-4775-a484-f363f69cefd3", user1Token)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusNotFound)
+}
+
+func TestGetMutualsReturnsFollowedUsersThatFollowTheUser(t *testing.T) {
+	testRouter, user1Token, user2, user3 := setUpRelationshipTests()
+
+	mutuals, err := utils.GetMutuals(testRouter, user3.Id.String(), user1Token)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(mutuals), 1)
+	assert.Equal(t, mutuals[0].Profile.Id, user2.Id)
+	assert.Equal(t, mutuals[0].Follows, true)
+
+	mutuals, err = utils.GetMutuals(testRouter, user2.Id.String(), user1Token)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(mutuals), 0)
+}
+
+func TestProfileShowsFollowedByAndMutualCount(t *testing.T) {
+	testRouter, user1Token, _, user3 := setUpRelationshipTests()
+
+	profile, err := utils.GetValidUser(testRouter, user3.Id.String(), user1Token)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, profile.Follows, false)
+	assert.Equal(t, profile.FollowedBy, true)
+	assert.Equal(t, profile.MutualCount, 1)
+}
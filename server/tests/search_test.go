@@ -150,4 +150,116 @@ func TestSearchForUsersWithWhitespacedTextReturnsError(t *testing.T) {
 
     assert.Equal(t, recorder.Code, http.StatusBadRequest)
     assert.Equal(t, errorResponse.Instance, "/users/search")
-}
\ No newline at end of file
+}
+
+func TestSearchWithTypoReturnsSimilarUser(t *testing.T) {
+    testRouter, user1, user1Password, _, _ := setUpSearchTests()
+
+    LoginRequest := models.LoginRequest{
+        Email: user1.Email,
+        Password: user1Password,
+    }
+
+    response, err := utils.LoginValidUser(testRouter, LoginRequest)
+    assert.Equal(t, err, nil)
+
+    searchResult, err := utils.SearchUsers(testRouter, "EdwardoElrc", response.AccessToken, 2)
+    assert.Equal(t, err, nil)
+    assert.Equal(t, len(searchResult), 1)
+    assert.Equal(t, searchResult[0].Profile.Id, user1.Id)
+}
+
+func TestSearchForExactUsernameReturnsItFirst(t *testing.T) {
+    testRouter, user1, user1Password, _, _ := setUpSearchTests()
+
+    LoginRequest := models.LoginRequest{
+        Email: user1.Email,
+        Password: user1Password,
+    }
+
+    response, err := utils.LoginValidUser(testRouter, LoginRequest)
+    assert.Equal(t, err, nil)
+
+    searchResult, err := utils.SearchUsers(testRouter, "edwardoelric", response.AccessToken, 2)
+    assert.Equal(t, err, nil)
+    assert.Equal(t, len(searchResult) > 0, true)
+    assert.Equal(t, searchResult[0].Profile.Id, user1.Id)
+}
+
+func TestSearchHighlightsTheMatchedText(t *testing.T) {
+    testRouter, user1, user1Password, user2, _ := setUpSearchTests()
+
+    LoginRequest := models.LoginRequest{
+        Email: user1.Email,
+        Password: user1Password,
+    }
+
+    response, err := utils.LoginValidUser(testRouter, LoginRequest)
+    assert.Equal(t, err, nil)
+
+    searchResult, err := utils.SearchUsers(testRouter, "elr", response.AccessToken, 2)
+    assert.Equal(t, err, nil)
+    assert.Equal(t, len(searchResult), 2)
+    assert.Equal(t, searchResult[0].Profile.Id, user2.Id)
+    assert.Equal(t, searchResult[0].Highlights["username"], "Monke<em>Elr</em>ico")
+    assert.Equal(t, searchResult[1].Highlights["username"], "Edwardo<em>Elr</em>ic")
+    assert.Equal(t, searchResult[1].Highlights["last_name"], "<em>Elr</em>ic")
+}
+
+func TestSearchFilteredByLocationReturnsOnlyUsersFromIt(t *testing.T) {
+    testRouter, user1, user1Password, user2, _ := setUpSearchTests()
+
+    LoginRequest := models.LoginRequest{
+        Email: user1.Email,
+        Password: user1Password,
+    }
+
+    response, err := utils.LoginValidUser(testRouter, LoginRequest)
+    assert.Equal(t, err, nil)
+
+    searchResult, err := utils.SearchUsersWithQuery(testRouter, "text=elr&location=1", response.AccessToken, 2)
+    assert.Equal(t, err, nil)
+    assert.Equal(t, len(searchResult), 1)
+    assert.Equal(t, searchResult[0].Profile.Id, user2.Id)
+}
+
+func TestSearchWithoutTextBrowsesUsersByInterests(t *testing.T) {
+    testRouter, user1, user1Password, user2, _ := setUpSearchTests()
+
+    LoginRequest := models.LoginRequest{
+        Email: user1.Email,
+        Password: user1Password,
+    }
+
+    response, err := utils.LoginValidUser(testRouter, LoginRequest)
+    assert.Equal(t, err, nil)
+
+    searchResult, err := utils.SearchUsersWithQuery(testRouter, "interests=1,4", response.AccessToken, 1)
+    assert.Equal(t, err, nil)
+    assert.Equal(t, len(searchResult), 2)
+    assert.Equal(t, searchResult[0].Profile.Id, user2.Id)
+    assert.Equal(t, searchResult[1].Profile.Id, user1.Id)
+
+    searchResult, err = utils.SearchUsersWithQuery(testRouter, "interests=4", response.AccessToken, 2)
+    assert.Equal(t, err, nil)
+    assert.Equal(t, len(searchResult), 0)
+}
+
+func TestSearchWithInvalidLocationReturnsError(t *testing.T) {
+    testRouter, user1, user1Password, _, _ := setUpSearchTests()
+
+    LoginRequest := models.LoginRequest{
+        Email: user1.Email,
+        Password: user1Password,
+    }
+
+    response, err := utils.LoginValidUser(testRouter, LoginRequest)
+    assert.Equal(t, err, nil)
+
+    req, _ := http.NewRequest("GET", "/users/search?location=99", nil)
+    req.Header.Add("Authorization", "Bearer "+response.AccessToken)
+    recorder := httptest.NewRecorder()
+    testRouter.Engine.ServeHTTP(recorder, req)
+
+    assert.Equal(t, recorder.Code, http.StatusBadRequest)
+}
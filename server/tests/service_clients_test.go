@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+	"users-service/tests/models"
+	"users-service/tests/utils"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestServiceClientCanOnlyCallTheEndpointsOfItsScopes(t *testing.T) {
+	testRouter, user1, user1Password, user2, _ := setUpEditProfileTests()
+	adminToken, err := utils.LoginAdmin()
+	assert.Equal(t, err, nil)
+
+	code, credentials, err := utils.CreateServiceClient(testRouter, "notifications", []string{"users:read"}, adminToken)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusCreated)
+	assert.NotEqual(t, credentials.ClientSecret, "")
+
+	code, token, err := utils.IssueServiceToken(testRouter, credentials.Client.Id.String(), credentials.ClientSecret, "")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, token.Scope, "users:read")
+
+	code, profile, err := utils.GetInternalUserProfile(testRouter, user2.Id.String(), token.AccessToken)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, profile.UserName, user2.UserName)
+
+	now := time.Now().UTC()
+	code = utils.GetInternalAmountOfFollowers(testRouter, user2.Id.String(), token.AccessToken, now.Add(-time.Hour).Format(time.RFC3339), now.Format(time.RFC3339))
+	assert.Equal(t, code, http.StatusForbidden)
+
+	code, _, _ = utils.IssueServiceToken(testRouter, credentials.Client.Id.String(), credentials.ClientSecret, "metrics:read")
+	assert.Equal(t, code, http.StatusForbidden)
+
+	// the users tokens and the service tokens can't be used in place of each other
+	resp, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+	code, _, _ = utils.GetInternalUserProfile(testRouter, user2.Id.String(), resp.AccessToken)
+	assert.Equal(t, code, http.StatusUnauthorized)
+	code, _, _ = utils.GetUsersBatch(testRouter, token.AccessToken, []string{user2.Id.String()}, nil)
+	assert.Equal(t, code, http.StatusUnauthorized)
+}
+
+func TestRotatedAndRevokedServiceClientSecrets(t *testing.T) {
+	testRouter, _, _, user2, _ := setUpEditProfileTests()
+	adminToken, err := utils.LoginAdmin()
+	assert.Equal(t, err, nil)
+
+	_, credentials, err := utils.CreateServiceClient(testRouter, "metrics", []string{"users:read", "metrics:read"}, adminToken)
+	assert.Equal(t, err, nil)
+	clientId := credentials.Client.Id.String()
+
+	code, rotated, err := utils.RotateServiceClientSecret(testRouter, clientId, adminToken)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.NotEqual(t, rotated.ClientSecret, credentials.ClientSecret)
+
+	// the previous secret keeps working during the grace period
+	code, _, _ = utils.IssueServiceToken(testRouter, clientId, credentials.ClientSecret, "")
+	assert.Equal(t, code, http.StatusOK)
+	code, token, err := utils.IssueServiceToken(testRouter, clientId, rotated.ClientSecret, "users:read")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, token.Scope, "users:read")
+	code, _, _ = utils.IssueServiceToken(testRouter, clientId, "wrong-secret", "")
+	assert.Equal(t, code, http.StatusUnauthorized)
+
+	assert.Equal(t, utils.RevokeServiceClient(testRouter, clientId, adminToken), http.StatusNoContent)
+
+	code, _, _ = utils.IssueServiceToken(testRouter, clientId, rotated.ClientSecret, "")
+	assert.Equal(t, code, http.StatusUnauthorized)
+	code, _, _ = utils.GetInternalUserProfile(testRouter, user2.Id.String(), token.AccessToken)
+	assert.Equal(t, code, http.StatusUnauthorized)
+}
+
+func TestOnlyAdminsCanManageServiceClients(t *testing.T) {
+	testRouter, user1, user1Password, _, _ := setUpEditProfileTests()
+	resp, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+
+	code, _, err := utils.CreateServiceClient(testRouter, "notifications", []string{"users:read"}, resp.AccessToken)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusForbidden)
+
+	adminToken, err := utils.LoginAdmin()
+	assert.Equal(t, err, nil)
+	code, _, err = utils.CreateServiceClient(testRouter, "notifications", []string{"users:write"}, adminToken)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusBadRequest)
+}
@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"users-service/tests/models"
+	"users-service/tests/utils"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestOldUsernameRedirectsToTheCurrentOne(t *testing.T) {
+	testRouter, user1, user1Password, user2, user2Password := setUpEditProfileTests()
+	resp, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+
+	code, _, _ := utils.PatchUserProfile(testRouter, resp.AccessToken, `{"username": "FullmetalEd"}`, "")
+	assert.Equal(t, code, http.StatusOK)
+
+	otherResp, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user2.Email, Password: user2Password})
+	assert.Equal(t, err, nil)
+
+	code, location, _ := utils.GetProfileByUsername(testRouter, user1.UserName, otherResp.AccessToken)
+	assert.Equal(t, code, http.StatusFound)
+	assert.Equal(t, location, "/users/by-username/FullmetalEd")
+
+	code, _, profile := utils.GetProfileByUsername(testRouter, "FullmetalEd", otherResp.AccessToken)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, profile.Id, user1.Id)
+	assert.Equal(t, profile.UserName, "FullmetalEd")
+}
+
+func TestGetProfileByUnknownUsernameReturnsNotFound(t *testing.T) {
+	testRouter, user1, user1Password, _, _ := setUpEditProfileTests()
+	resp, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+
+	code, _, _ := utils.GetProfileByUsername(testRouter, "VanHohenheim", resp.AccessToken)
+	assert.Equal(t, code, http.StatusNotFound)
+}
+
+func TestChangeUsernameBeforeTheCooldownReturnsProperValidationError(t *testing.T) {
+	testRouter, user1, user1Password, _, _ := setUpEditProfileTests()
+	resp, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+
+	code, _, _ := utils.PatchUserProfile(testRouter, resp.AccessToken, `{"username": "FullmetalEd"}`, "")
+	assert.Equal(t, code, http.StatusOK)
+
+	updatedProfile := models.EditUserProfileRequest{
+		FirstName: user1.FirstName,
+		LastName:  user1.LastName,
+		Username:  "StateAlchemist",
+		Location:  0,
+		Interests: []int{0, 1},
+	}
+	code, response, err := utils.EditInvalidUserProfile(testRouter, resp.AccessToken, updatedProfile)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusBadRequest)
+	assert.Equal(t, len(response.Errors), 1)
+	assert.Equal(t, response.Errors[0].Field, "username")
+}
+
+func TestUsernameLeftByAUserIsReserved(t *testing.T) {
+	testRouter, user1, user1Password, user2, user2Password := setUpEditProfileTests()
+	resp, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user1.Email, Password: user1Password})
+	assert.Equal(t, err, nil)
+
+	code, _, _ := utils.PatchUserProfile(testRouter, resp.AccessToken, `{"username": "FullmetalEd"}`, "")
+	assert.Equal(t, code, http.StatusOK)
+
+	otherResp, err := utils.LoginValidUser(testRouter, models.LoginRequest{Email: user2.Email, Password: user2Password})
+	assert.Equal(t, err, nil)
+	code, _, _ = utils.PatchUserProfile(testRouter, otherResp.AccessToken, `{"username": "edwardoelric"}`, "")
+	assert.Equal(t, code, http.StatusBadRequest)
+
+	personalInfo := models.UserPersonalInfo{
+		FirstName: "Edward",
+		LastName:  "Impostor",
+		UserName:  user1.UserName,
+		Password:  "Edward$El1ric:)",
+		Location:  0,
+	}
+	code, response, err := utils.CreateUserWithInvalidPersonalInfo(testRouter, "impostor@elric.com", personalInfo)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, http.StatusBadRequest)
+	assert.Equal(t, len(response.Errors), 1)
+	assert.Equal(t, response.Errors[0].Message, "Username is reserved")
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+	"time"
+	"users-service/src/auth"
+	"users-service/src/config"
+	"users-service/src/database/catalog_db"
+	"users-service/src/database/registry_db"
+	"users-service/src/database/users_db"
+	"users-service/src/service"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// OpenTestDatabase connects to the database of the routers of the tests, for what can't be done through the API,
+// like the background jobs or breaking the data on purpose. It must be opened after the router so the tables exist
+func OpenTestDatabase(t *testing.T) *sqlx.DB {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("error loading configuration: %v", err)
+	}
+
+	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
+		cfg.DatabaseUser, cfg.DatabasePassword, cfg.DatabaseHost, cfg.DatabasePort, cfg.DatabaseName)
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		t.Fatalf("error connecting to the test database: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+// CreateTestUserService creates a user service over the test database without recreating its tables,
+// so it sees the data created through the router
+func CreateTestUserService(t *testing.T, db *sqlx.DB) (*service.User, users_db.UserDatabase) {
+	catalogDb, err := catalog_db.CreateCatalogPostgresDB(db, false)
+	if err != nil {
+		t.Fatalf("error creating catalog database: %v", err)
+	}
+	userDb, err := users_db.CreateUsersPostgresDB(db, false)
+	if err != nil {
+		t.Fatalf("error creating users database: %v", err)
+	}
+	registryDb, err := registry_db.CreateRegistryPostgresDB(db, false)
+	if err != nil {
+		t.Fatalf("error creating registry database: %v", err)
+	}
+
+	return service.CreateUserService(userDb, registryDb, catalogDb, nil, auth.NewProviderRegistry(), 0, time.Second, time.Second), userDb
+}
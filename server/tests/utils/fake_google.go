@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"users-service/src/auth"
+	"users-service/src/constants"
+	"users-service/src/router"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// FakeGoogleVerifier takes the place of Firebase in the tests, so they don't need credentials nor the network.
+// Like the Firebase emulator it accepts unsigned tokens, so it only exists in the tests and the routers
+// get it through router.WithIdentityVerifier
+type FakeGoogleVerifier struct{}
+
+type fakeGoogleClaims struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	jwt.RegisteredClaims
+}
+
+func (v *FakeGoogleVerifier) Verify(_ context.Context, idToken string) (auth.VerifiedIdentity, error) {
+	keyfunc := func(*jwt.Token) (interface{}, error) {
+		return jwt.UnsafeAllowNoneSignatureType, nil
+	}
+
+	parsed, err := jwt.ParseWithClaims(idToken, &fakeGoogleClaims{}, keyfunc,
+		jwt.WithValidMethods([]string{jwt.SigningMethodNone.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return auth.VerifiedIdentity{}, fmt.Errorf("%w: %w", auth.ErrInvalidIdentityToken, err)
+	}
+
+	claims, ok := parsed.Claims.(*fakeGoogleClaims)
+	if !ok || claims.Subject == "" {
+		return auth.VerifiedIdentity{}, fmt.Errorf("%w: %w", auth.ErrInvalidIdentityToken, errors.New("the token has no subject"))
+	}
+	if claims.Email == "" || !claims.EmailVerified {
+		return auth.VerifiedIdentity{}, auth.ErrUnverifiedEmail
+	}
+
+	return auth.VerifiedIdentity{Provider: constants.GoogleProvider, Subject: claims.Subject, Email: claims.Email}, nil
+}
+
+// WithFakeGoogle makes the router verify the Google tokens with the FakeGoogleVerifier
+func WithFakeGoogle() router.Option {
+	return router.WithIdentityVerifier(constants.GoogleProvider, &FakeGoogleVerifier{})
+}
+
+// FakeGoogleToken creates a token the FakeGoogleVerifier accepts, unsigned like the ones of the Firebase emulator.
+// The claims override the default ones
+func FakeGoogleToken(subject string, email string, claims jwt.MapClaims) string {
+	tokenClaims := jwt.MapClaims{
+		"sub":            subject,
+		"email":          email,
+		"email_verified": true,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	}
+	for claim, value := range claims {
+		tokenClaims[claim] = value
+	}
+
+	signed, _ := jwt.NewWithClaims(jwt.SigningMethodNone, tokenClaims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	return signed
+}
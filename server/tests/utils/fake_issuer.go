@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const FakeIssuerClientId = "users-service-tests"
+
+// FakeIssuer is a local OpenID Connect provider that publishes its keys and signs the tokens the tests ask for
+type FakeIssuer struct {
+	Server *httptest.Server
+	key    *rsa.PrivateKey
+	kid    string
+}
+
+func NewFakeIssuer(t *testing.T) *FakeIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error generating fake issuer key: %v", err)
+	}
+	issuer := &FakeIssuer{key: key, kid: "fake-issuer-key"}
+
+	issuer.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encode := base64.RawURLEncoding.EncodeToString
+		jwks := map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": issuer.kid,
+				"use": "sig",
+				"alg": "RS256",
+				"n":   encode(key.N.Bytes()),
+				"e":   encode(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(jwks)
+	}))
+	t.Cleanup(issuer.Server.Close)
+
+	return issuer
+}
+
+// UseAsProvider configures the issuer as the provider with the name for the routers created afterwards
+func (i *FakeIssuer) UseAsProvider(t *testing.T, name string) {
+	providers := []map[string]interface{}{{
+		"name":       name,
+		"issuer":     i.Server.URL,
+		"client_ids": []string{FakeIssuerClientId},
+		"jwks_url":   i.Server.URL + "/jwks",
+	}}
+	encoded, _ := json.Marshal(providers)
+
+	file := filepath.Join(t.TempDir(), "oidc_providers.json")
+	if err := os.WriteFile(file, encoded, 0600); err != nil {
+		t.Fatalf("error writing providers file: %v", err)
+	}
+	t.Setenv("OIDC_PROVIDERS_FILE", file)
+}
+
+// Token signs an ID token for the subject and email, the claims override the default ones
+func (i *FakeIssuer) Token(subject string, email string, claims jwt.MapClaims) string {
+	tokenClaims := jwt.MapClaims{
+		"iss":            i.Server.URL,
+		"aud":            FakeIssuerClientId,
+		"sub":            subject,
+		"email":          email,
+		"email_verified": true,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	}
+	for claim, value := range claims {
+		tokenClaims[claim] = value
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, tokenClaims)
+	token.Header["kid"] = i.kid
+	signed, _ := token.SignedString(i.key)
+	return signed
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"regexp"
 	"testing"
 	"time"
@@ -112,6 +113,23 @@ func CompleteValidRegistry(router *router.Router, id string) (models.UserPrivate
 	return result, nil
 }
 
+func CompleteInvalidRegistry(router *router.Router, id string) (int, models.ErrorResponse, error) {
+	endpoint := fmt.Sprintf("/users/register/%s/complete", id)
+	req, _ := http.NewRequest("POST", endpoint, nil)
+
+	req.Header.Add("content-type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+	result := models.ErrorResponse{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &result)
+
+	if err != nil {
+		return 0, models.ErrorResponse{}, err
+	}
+
+	return recorder.Code, result, nil
+}
+
 func CreateValidUser(router *router.Router, email string, personalInfo models.UserPersonalInfo, interests []int) (models.UserPrivateProfile, error) {
 	res, err := GetUserRegistryForSignUp(router, email)
 	if err != nil {
@@ -268,6 +286,23 @@ func GetValidUser(router *router.Router, id string, token string) (models.UserPr
 	return result, nil
 }
 
+// GetProfileByUsername gets the profile of the user with the username. It returns the status code, the
+// Location header of the redirects and the profile, as public since both have its id and username
+func GetProfileByUsername(router *router.Router, username string, token string) (int, string, models.UserPublicProfile) {
+	req, _ := http.NewRequest("GET", "/users/by-username/"+url.PathEscape(username), nil)
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	result := struct {
+		Profile models.UserPublicProfile `json:"profile"`
+	}{}
+	_ = json.Unmarshal(recorder.Body.Bytes(), &result)
+
+	return recorder.Code, recorder.Header().Get("Location"), result.Profile
+}
+
 func GetValidUserInformation(router *router.Router, id string, adminToken string) (models.UserInformationResponse, error) {
 	req, _ := http.NewRequest("GET", "/users/"+id+"/information", nil)
 
@@ -316,6 +351,23 @@ func GetOwnProfile(router *router.Router, id string, token string) (models.UserP
 	return profile, nil
 }
 
+func GetOwnProfileInLanguage(router *router.Router, id string, token string, language string) (models.UserPrivateProfile, error) {
+	req, _ := http.NewRequest("GET", "/users/"+id, nil)
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	req.Header.Add("Accept-Language", language)
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	result := struct {
+		Profile models.UserPrivateProfile `json:"profile"`
+	}{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		return models.UserPrivateProfile{}, err
+	}
+	return result.Profile, nil
+}
+
 func GetAnotherUserProfile(router *router.Router, id string, token string) (models.UserPublicProfile, error) {
 	result, err := GetValidUser(router, id, token)
 	if err != nil {
@@ -348,8 +400,16 @@ func GetNotExistingUser(router *router.Router, id string, token string) (models.
 }
 
 func GetRegisterOptions(router *router.Router) (models.RegisterOptions, error) {
+	return GetRegisterOptionsInLanguage(router, "")
+}
+
+// GetRegisterOptionsInLanguage lists the locations and interests as asked with the Accept-Language header, if any
+func GetRegisterOptionsInLanguage(router *router.Router, language string) (models.RegisterOptions, error) {
 	req, _ := http.NewRequest("GET", "/users/info/locations", nil)
-	
+	if language != "" {
+		req.Header.Add("Accept-Language", language)
+	}
+
 	recorder := httptest.NewRecorder()
 	router.Engine.ServeHTTP(recorder, req)
 	var locations struct {
@@ -361,7 +421,10 @@ func GetRegisterOptions(router *router.Router) (models.RegisterOptions, error) {
 	}
 
 	req, _ = http.NewRequest("GET", "/users/info/interests", nil)
-	
+	if language != "" {
+		req.Header.Add("Accept-Language", language)
+	}
+
 	recorder = httptest.NewRecorder()
 	router.Engine.ServeHTTP(recorder, req)
 	var interests struct {
@@ -433,6 +496,22 @@ func EditInvalidUserProfile(router *router.Router, token string, user models.Edi
 	return recorder.Code, result, nil
 }
 
+// PatchUserProfile sends a merge patch of the profile, with the If-Match header if ifMatch is not empty.
+// It returns the status code, the ETag header and the body of the response
+func PatchUserProfile(router *router.Router, token string, patch string, ifMatch string) (int, string, []byte) {
+	req, _ := http.NewRequest("PATCH", "/users/profile", bytes.NewReader([]byte(patch)))
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	req.Header.Add("content-type", "application/merge-patch+json")
+	if ifMatch != "" {
+		req.Header.Add("If-Match", ifMatch)
+	}
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	return recorder.Code, recorder.Header().Get("ETag"), recorder.Body.Bytes()
+}
+
 func FollowValidUser(router *router.Router, id string, token string) error {
 	req, _ := http.NewRequest("POST", "/users/" + id + "/follow", nil)
 	
@@ -622,12 +701,16 @@ func GetAmountOfFollowersInTimeRangeInvalid(router *router.Router, token, startT
 }
 
 func SearchUsers(router *router.Router, text string, token string, limit int) ([]models.FollowUserProfile, error) {
+	return SearchUsersWithQuery(router, "text="+text, token, limit)
+}
+
+func SearchUsersWithQuery(router *router.Router, query string, token string, limit int) ([]models.FollowUserProfile, error) {
 	var result []models.FollowUserProfile
 	var currPagination models.Pagination
 	
 	fetchUsers := func(skip int) error {
 		timestamp := time.Unix(time.Now().Unix()+1, 0).UTC().Format(time.RFC3339Nano)
-		url := fmt.Sprintf("/users/search?text=%s&time=%s&skip=%d&limit=%d",text, timestamp, skip, limit)
+		url := fmt.Sprintf("/users/search?%s&time=%s&skip=%d&limit=%d", query, timestamp, skip, limit)
 		req, _ := http.NewRequest("GET", url, nil)
 	
 		req.Header.Add("Authorization", "Bearer "+token)
@@ -729,6 +812,51 @@ func GetAllUsers(router *router.Router, token string, limit int) ([]models.UserP
 	return result, nil
 }
 
+func GetAllUsersWithCursor(router *router.Router, token string, limit int) ([]models.UserPublicProfile, error) {
+	result := []models.UserPublicProfile{}
+	cursor := ""
+
+	for {
+		url := fmt.Sprintf("/users/all?limit=%d", limit)
+		if cursor != "" {
+			url += "&cursor=" + cursor
+		}
+		req, _ := http.NewRequest("GET", url, nil)
+
+		req.Header.Add("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		router.Engine.ServeHTTP(recorder, req)
+
+		page := models.PaginationResponse[models.UserPublicProfile]{}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &page); err != nil {
+			return nil, err
+		}
+
+		result = append(result, page.Data...)
+		if page.Pagination.NextCursor == "" {
+			return result, nil
+		}
+		cursor = page.Pagination.NextCursor
+	}
+}
+
+func GetAllUsersWithInvalidCursor(router *router.Router, token string, cursor string) (int, models.ErrorResponse, error) {
+	url := fmt.Sprintf("/users/all?limit=%d&cursor=%s", 10, cursor)
+	req, _ := http.NewRequest("GET", url, nil)
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+	result := models.ErrorResponse{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &result)
+
+	if err != nil {
+		return 0, models.ErrorResponse{}, err
+	}
+
+	return recorder.Code, result, nil
+}
+
 func GetAllUsersInvalidToken(router *router.Router, token string, limit int) (int, models.ErrorResponse, error) {
 	timestamp := time.Unix(time.Now().Unix()+1, 0).UTC().Format(time.RFC3339Nano)
 	url := fmt.Sprintf("/users/all?time=%s&skip=%d&limit=%d", timestamp, 0, limit)
@@ -935,3 +1063,457 @@ func PrivateUserToPublic(user models.UserPrivateProfile) models.UserPublicProfil
 		Following: user.Following,
 	}
 }
+
+func AutocompleteUsernames(router *router.Router, prefix string, token string) (int, []models.UserSuggestion, error) {
+	url := fmt.Sprintf("/users/autocomplete?prefix=%s", prefix)
+	req, _ := http.NewRequest("GET", url, nil)
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	var result struct {
+		Data []models.UserSuggestion `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		return 0, nil, err
+	}
+	return recorder.Code, result.Data, nil
+}
+
+func DismissRecommendation(router *router.Router, id string, token string, expiresAt *time.Time) (int, error) {
+	var body []byte
+	if expiresAt != nil {
+		marshalled, err := json.Marshal(map[string]time.Time{"expires_at": *expiresAt})
+		if err != nil {
+			return 0, err
+		}
+		body = marshalled
+	}
+
+	url := fmt.Sprintf("/users/recommendations/%s/dismiss", id)
+	req, _ := http.NewRequest("POST", url, bytes.NewReader(body))
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	return recorder.Code, nil
+}
+
+func GetUserRecommendationsPage(router *router.Router, token string, limit int, timestamp time.Time) ([]models.FollowUserProfile, error) {
+	url := fmt.Sprintf("/users/recommendations?time=%s&limit=%d", timestamp.UTC().Format(time.RFC3339), limit)
+	req, _ := http.NewRequest("GET", url, nil)
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	result := models.PaginationResponse[models.FollowUserProfile]{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		return nil, err
+	}
+	return result.Data, nil
+}
+
+// GetUserRecommendationsWithCursor lists every recommendation following the cursors, the listing starts at listedAt
+func GetUserRecommendationsWithCursor(router *router.Router, token string, limit int, listedAt time.Time) ([]models.FollowUserProfile, error) {
+	result := []models.FollowUserProfile{}
+	url := fmt.Sprintf("/users/recommendations?time=%s&limit=%d", listedAt.UTC().Format(time.RFC3339), limit)
+
+	for {
+		req, _ := http.NewRequest("GET", url, nil)
+
+		req.Header.Add("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		router.Engine.ServeHTTP(recorder, req)
+
+		page := models.PaginationResponse[models.FollowUserProfile]{}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &page); err != nil {
+			return nil, err
+		}
+
+		result = append(result, page.Data...)
+		if page.Pagination.NextCursor == "" {
+			return result, nil
+		}
+		url = fmt.Sprintf("/users/recommendations?limit=%d&cursor=%s", limit, page.Pagination.NextCursor)
+	}
+}
+
+func GetRelationship(router *router.Router, id string, token string) (int, models.RelationshipResponse, error) {
+	req, _ := http.NewRequest("GET", "/users/"+id+"/relationship", nil)
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	result := models.RelationshipResponse{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		return 0, models.RelationshipResponse{}, err
+	}
+	return recorder.Code, result, nil
+}
+
+func GetMutuals(router *router.Router, id string, token string) ([]models.FollowUserProfile, error) {
+	var result []models.FollowUserProfile
+	var currPagination models.Pagination
+
+	fetchMutuals := func(skip int) error {
+		timestamp := time.Now().UTC().Format(time.RFC3339Nano)
+		url := fmt.Sprintf("/users/%s/mutuals?time=%s&skip=%d&limit=%d", id, timestamp, skip, 1)
+		req, _ := http.NewRequest("GET", url, nil)
+
+		req.Header.Add("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		router.Engine.ServeHTTP(recorder, req)
+
+		newResult := models.PaginationResponse[models.FollowUserProfile]{}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &newResult); err != nil {
+			return err
+		}
+		result = append(result, newResult.Data...)
+		currPagination = newResult.Pagination
+		return nil
+	}
+
+	if err := fetchMutuals(0); err != nil {
+		return nil, err
+	}
+
+	for currPagination.NextOffset != 0 {
+		if err := fetchMutuals(currPagination.NextOffset); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func AddCatalogEntry(router *router.Router, catalog string, name string, token string) (int, models.CatalogEntry, error) {
+	return AddCatalogEntryWithMetadata(router, catalog, map[string]interface{}{"name": name}, token)
+}
+
+func AddCatalogEntryWithMetadata(router *router.Router, catalog string, entry map[string]interface{}, token string) (int, models.CatalogEntry, error) {
+	marshalledInfo, _ := json.Marshal(entry)
+	req, _ := http.NewRequest("POST", "/users/admin/catalogs/"+catalog, bytes.NewReader(marshalledInfo))
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	req.Header.Add("content-type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	result := models.CatalogEntry{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		return 0, models.CatalogEntry{}, err
+	}
+	return recorder.Code, result, nil
+}
+
+func UpdateCatalogEntry(router *router.Router, catalog string, id int, changes map[string]interface{}, token string) (int, models.CatalogEntry, error) {
+	marshalledInfo, _ := json.Marshal(changes)
+	req, _ := http.NewRequest("PATCH", fmt.Sprintf("/users/admin/catalogs/%s/%d", catalog, id), bytes.NewReader(marshalledInfo))
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	req.Header.Add("content-type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	result := models.CatalogEntry{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		return 0, models.CatalogEntry{}, err
+	}
+	return recorder.Code, result, nil
+}
+
+func GetInterestsInCategory(router *router.Router, category string) (int, []models.Interest, error) {
+	req, _ := http.NewRequest("GET", "/users/info/interests?category="+category, nil)
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	var result struct {
+		Interests []models.Interest `json:"interests"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		return 0, nil, err
+	}
+	return recorder.Code, result.Interests, nil
+}
+
+func GetInterestCategories(router *router.Router, language string) ([]models.InterestCategory, error) {
+	req, _ := http.NewRequest("GET", "/users/info/interests/categories", nil)
+	req.Header.Add("Accept-Language", language)
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	var result struct {
+		Categories []models.InterestCategory `json:"categories"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		return nil, err
+	}
+	return result.Categories, nil
+}
+
+func SetCatalogTranslation(router *router.Router, catalog string, id int, language string, name string, token string) (int, models.CatalogEntry, error) {
+	marshalledInfo, _ := json.Marshal(map[string]string{"name": name})
+	url := fmt.Sprintf("/users/admin/catalogs/%s/%d/translations/%s", catalog, id, language)
+	req, _ := http.NewRequest("PUT", url, bytes.NewReader(marshalledInfo))
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	req.Header.Add("content-type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	result := models.CatalogEntry{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		return 0, models.CatalogEntry{}, err
+	}
+	return recorder.Code, result, nil
+}
+
+func DeleteCatalogTranslation(router *router.Router, catalog string, id int, language string, token string) int {
+	url := fmt.Sprintf("/users/admin/catalogs/%s/%d/translations/%s", catalog, id, language)
+	req, _ := http.NewRequest("DELETE", url, nil)
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	return recorder.Code
+}
+
+func ReorderCatalog(router *router.Router, catalog string, ids []int, token string) (int, error) {
+	marshalledInfo, _ := json.Marshal(map[string][]int{"ids": ids})
+	req, _ := http.NewRequest("PUT", "/users/admin/catalogs/"+catalog+"/order", bytes.NewReader(marshalledInfo))
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	req.Header.Add("content-type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	return recorder.Code, nil
+}
+
+// FindLocationId walks down the locations hierarchy by their names, like "Argentina", "Santa Fe", "Rosario"
+func FindLocationId(router *router.Router, names ...string) (int, error) {
+	url := "/users/info/locations"
+	id := -1
+	for _, name := range names {
+		req, _ := http.NewRequest("GET", url, nil)
+		recorder := httptest.NewRecorder()
+		router.Engine.ServeHTTP(recorder, req)
+
+		var result struct {
+			Locations []models.Location `json:"locations"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+			return 0, err
+		}
+
+		id = -1
+		for _, location := range result.Locations {
+			if location.Name == name {
+				id = location.Id
+				break
+			}
+		}
+		if id == -1 {
+			return 0, fmt.Errorf("location %s not found", name)
+		}
+		url = fmt.Sprintf("/users/info/locations?parent=%d", id)
+	}
+	return id, nil
+}
+
+func GetNearbyUsers(router *router.Router, token string, radiusKm string) (int, []models.FollowUserProfile, error) {
+	timestamp := time.Unix(time.Now().Unix()+1, 0).UTC().Format(time.RFC3339Nano)
+	url := fmt.Sprintf("/users/nearby?radius_km=%s&time=%s&limit=%d", radiusKm, timestamp, 20)
+	req, _ := http.NewRequest("GET", url, nil)
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	result := models.PaginationResponse[models.FollowUserProfile]{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		return 0, nil, err
+	}
+	return recorder.Code, result.Data, nil
+}
+
+func GetUsersBatch(router *router.Router, token string, ids []string, usernames []string) (int, models.UserBatchResponse, error) {
+	body, _ := json.Marshal(map[string][]string{"ids": ids, "usernames": usernames})
+	req, _ := http.NewRequest("POST", "/users/batch", bytes.NewReader(body))
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	req.Header.Add("content-type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	result := models.UserBatchResponse{}
+	if recorder.Code != http.StatusOK {
+		return recorder.Code, result, nil
+	}
+	err := json.Unmarshal(recorder.Body.Bytes(), &result)
+	return recorder.Code, result, err
+}
+
+func CreateServiceClient(router *router.Router, name string, scopes []string, token string) (int, models.ServiceClientCredentials, error) {
+	body, _ := json.Marshal(map[string]interface{}{"name": name, "scopes": scopes})
+	req, _ := http.NewRequest("POST", "/users/admin/service-clients", bytes.NewReader(body))
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	req.Header.Add("content-type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	result := models.ServiceClientCredentials{}
+	if recorder.Code != http.StatusCreated {
+		return recorder.Code, result, nil
+	}
+	err := json.Unmarshal(recorder.Body.Bytes(), &result)
+	return recorder.Code, result, err
+}
+
+func RotateServiceClientSecret(router *router.Router, id string, token string) (int, models.ServiceClientCredentials, error) {
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/users/admin/service-clients/%s/rotate", id), nil)
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	result := models.ServiceClientCredentials{}
+	if recorder.Code != http.StatusOK {
+		return recorder.Code, result, nil
+	}
+	err := json.Unmarshal(recorder.Body.Bytes(), &result)
+	return recorder.Code, result, err
+}
+
+func RevokeServiceClient(router *router.Router, id string, token string) int {
+	req, _ := http.NewRequest("DELETE", fmt.Sprintf("/users/admin/service-clients/%s", id), nil)
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+	return recorder.Code
+}
+
+// IssueServiceToken asks for a token with the client credentials grant, sending them as a form
+func IssueServiceToken(router *router.Router, clientId string, clientSecret string, scope string) (int, models.ServiceTokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", clientId)
+	form.Set("client_secret", clientSecret)
+	form.Set("scope", scope)
+	req, _ := http.NewRequest("POST", "/internal/token", bytes.NewReader([]byte(form.Encode())))
+
+	req.Header.Add("content-type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	result := models.ServiceTokenResponse{}
+	if recorder.Code != http.StatusOK {
+		return recorder.Code, result, nil
+	}
+	err := json.Unmarshal(recorder.Body.Bytes(), &result)
+	return recorder.Code, result, err
+}
+
+func GetInternalUserProfile(router *router.Router, id string, token string) (int, models.UserPublicProfile, error) {
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/internal/users/%s", id), nil)
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	result := struct {
+		Profile models.UserPublicProfile `json:"profile"`
+	}{}
+	if recorder.Code != http.StatusOK {
+		return recorder.Code, result.Profile, nil
+	}
+	err := json.Unmarshal(recorder.Body.Bytes(), &result)
+	return recorder.Code, result.Profile, err
+}
+
+func GetInternalAmountOfFollowers(router *router.Router, id string, token string, startTime, endTime string) int {
+	url := fmt.Sprintf("/internal/users/%s/metrics/followers?time=%s&end_time=%s", id, startTime, endTime)
+	req, _ := http.NewRequest("GET", url, nil)
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+	return recorder.Code
+}
+
+func GetJWKS(router *router.Router) (int, models.JWKS, error) {
+	req, _ := http.NewRequest("GET", "/.well-known/jwks.json", nil)
+
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	result := models.JWKS{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &result)
+	return recorder.Code, result, err
+}
+
+func ResolveWithProvider(router *router.Router, email string, provider string, metadata map[string]string) (int, models.ResolverResponse, error) {
+	payload := map[string]interface{}{
+		"email":    email,
+		"provider": map[string]interface{}{"type": provider, "metadata": metadata},
+	}
+	marshalledInfo, _ := json.Marshal(payload)
+	req, _ := http.NewRequest("POST", "/users/resolver", bytes.NewReader(marshalledInfo))
+
+	req.Header.Add("content-type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	result := models.ResolverResponse{}
+	if recorder.Code != http.StatusOK {
+		return recorder.Code, result, nil
+	}
+	err := json.Unmarshal(recorder.Body.Bytes(), &result)
+	return recorder.Code, result, err
+}
+
+func GetUserIdentities(router *router.Router, token string) (int, []models.UserIdentity, error) {
+	req, _ := http.NewRequest("GET", "/users/identities", nil)
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	result := struct {
+		Data []models.UserIdentity `json:"data"`
+	}{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &result)
+	return recorder.Code, result.Data, err
+}
+
+func LinkUserIdentity(router *router.Router, token string, provider string, metadata map[string]string) (int, models.UserIdentity, error) {
+	marshalledInfo, _ := json.Marshal(map[string]interface{}{"type": provider, "metadata": metadata})
+	req, _ := http.NewRequest("POST", "/users/identities", bytes.NewReader(marshalledInfo))
+
+	req.Header.Add("content-type", "application/json")
+	req.Header.Add("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+
+	result := models.UserIdentity{}
+	if recorder.Code != http.StatusCreated {
+		return recorder.Code, result, nil
+	}
+	err := json.Unmarshal(recorder.Body.Bytes(), &result)
+	return recorder.Code, result, err
+}
+
+func UnlinkUserIdentity(router *router.Router, token string, provider string) int {
+	req, _ := http.NewRequest("DELETE", fmt.Sprintf("/users/identities/%s", provider), nil)
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	router.Engine.ServeHTTP(recorder, req)
+	return recorder.Code
+}